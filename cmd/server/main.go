@@ -1,8 +1,11 @@
 package main
 
 import (
+	"ManageEmployeesandDepartments/internal/auth"
+	"ManageEmployeesandDepartments/internal/config"
 	"ManageEmployeesandDepartments/internal/db"
 	"ManageEmployeesandDepartments/internal/handlers"
+	"ManageEmployeesandDepartments/internal/middleware"
 	"ManageEmployeesandDepartments/internal/repository"
 	"ManageEmployeesandDepartments/internal/routes"
 	"ManageEmployeesandDepartments/internal/services"
@@ -23,12 +26,22 @@ import (
 // @description API to manage employees and departments of a company.
 // @host localhost:8080
 // @BasePath /api/v1
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT as "Bearer <token>", with roles hr_admin, manager or viewer
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key for service integrations, limited to its scopes
 func main() {
 	// Load .env locally (not required in Docker, but good for dev)
 	if err := godotenv.Load(); err != nil {
 		log.Println(".env file not found, using environment variables.")
 	}
 
+	cfg := config.Load()
+
 	// Connect to database
 	db, err := db.ConnectDatabase()
 	if err != nil {
@@ -38,21 +51,64 @@ func main() {
 	// Repositories
 	employeeRepo := repository.NewEmployeeRepository(db)
 	deptRepo := repository.NewDepartmentRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	historyRepo := repository.NewEmployeeHistoryRepository(db)
+	versionRepo := repository.NewDepartmentHistoryRepository(db)
+
+	// Large department moves need the token of a move preview
+	threshold, ttl, err := cfg.MoveConfirmationLimits()
+	if err != nil {
+		log.Fatal("Failed to configure move confirmation: ", err)
+	}
+	moveKey, err := cfg.MoveConfirmationKey()
+	if err != nil {
+		log.Fatal("Failed to configure move confirmation: ", err)
+	}
+	if cfg.MoveConfirmationSecret == "" {
+		log.Println("MOVE_CONFIRMATION_SECRET not set, move confirmation tokens only work on this instance.")
+	}
+	moves := services.MoveConfirmation{Threshold: threshold, TTL: ttl, Secret: moveKey}
 
 	// Services
-	employeeService := services.NewEmployeeService(deptRepo, employeeRepo)
-	deptService := services.NewDepartmentService(deptRepo, employeeRepo)
+	employeeService := services.NewEmployeeService(deptRepo, employeeRepo, auditRepo, historyRepo)
+	deptService := services.NewDepartmentService(deptRepo, employeeRepo, auditRepo, historyRepo, versionRepo, moves)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo)
+	auditService := services.NewAuditService(auditRepo)
+	personalDataService := services.NewPersonalDataService(deptRepo, employeeRepo, historyRepo, versionRepo, auditRepo)
+
+	// Authentication
+	publicKey, err := cfg.JWTPublicKeyPEM()
+	if err != nil {
+		log.Fatal("Failed to load JWT public key: ", err)
+	}
+	verifier, err := auth.NewVerifier(auth.JWTConfig{
+		Algorithm: cfg.JWTAlgorithm,
+		Secret:    cfg.JWTSecret,
+		PublicKey: publicKey,
+		Issuer:    cfg.JWTIssuer,
+		Audience:  cfg.JWTAudience,
+	})
+	if err != nil {
+		log.Fatal("Failed to configure JWT authentication: ", err)
+	}
+	authn := middleware.NewAuth(verifier, deptService.ManagedSubtreeIDs, apiKeyService.AuthenticateAPIKey)
 
 	// Handlers
 	employeeHandler := handlers.NewEmployeeHandler(employeeService)
 	deptHandler := handlers.NewDepartamentoHandler(deptService)
 	managerHandler := handlers.NewManagerHandler(deptService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	personalDataHandler := handlers.NewPersonalDataHandler(personalDataService)
 
-	// Initialize Gin Router
-	r := gin.Default()
+	// Initialize Gin Router; every error leaves in the same JSON envelope
+	r := gin.New()
+	r.Use(gin.Logger(), middleware.Recovery(), middleware.ErrorHandler())
+	r.NoRoute(middleware.NoRoute)
 
 	// Setup Routes
-	routes.SetupRoutes(r, employeeHandler, deptHandler, managerHandler)
+	routes.SetupRoutes(r, employeeHandler, deptHandler, managerHandler, apiKeyHandler, auditHandler, personalDataHandler, authn)
 
 	// Setup Swagger
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// JWTConfig describes how incoming tokens are signed and what they must carry.
+type JWTConfig struct {
+	Algorithm string // HS256 or RS256
+	Secret    string // HS256 shared secret
+	PublicKey string // RS256 public key, PEM encoded
+	Issuer    string // Optional expected "iss"
+	Audience  string // Optional expected "aud"
+}
+
+// Claims is the payload the API expects in a token. The subject is the
+// caller's employee ID when they are one (always for managers).
+type Claims struct {
+	Roles []Role `json:"roles"`
+	jwt.RegisteredClaims
+}
+
+// Verifier validates signed JWTs and turns them into principals.
+type Verifier struct {
+	key    any
+	parser *jwt.Parser
+}
+
+// NewVerifier builds a verifier for the configured algorithm and key. Only
+// the configured algorithm is accepted, so an RS256 public key can never be
+// used as an HS256 secret.
+func NewVerifier(cfg JWTConfig) (*Verifier, error) {
+	var key any
+	switch cfg.Algorithm {
+	case "HS256":
+		if cfg.Secret == "" {
+			return nil, errors.New("JWT_SECRET is required for HS256")
+		}
+		key = []byte(cfg.Secret)
+	case "RS256":
+		pub, err := parseRSAPublicKey(cfg.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		key = pub
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", cfg.Algorithm)
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{cfg.Algorithm}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	return &Verifier{key: key, parser: jwt.NewParser(opts...)}, nil
+}
+
+// Verify checks the token signature and claims and returns its principal.
+func (v *Verifier) Verify(token string) (*Principal, error) {
+	var claims Claims
+	if _, err := v.parser.ParseWithClaims(token, &claims, func(*jwt.Token) (any, error) {
+		return v.key, nil
+	}); err != nil {
+		return nil, err
+	}
+
+	p := &Principal{Subject: claims.Subject, Roles: claims.Roles}
+	if id, err := uuid.Parse(claims.Subject); err == nil {
+		p.EmployeeID = id
+	}
+	if p.HasRole(RoleManager) && p.EmployeeID == uuid.Nil {
+		return nil, errors.New("manager token without an employee subject")
+	}
+	return p, nil
+}
+
+func parseRSAPublicKey(pemKey string) (*rsa.PublicKey, error) {
+	if pemKey == "" {
+		return nil, errors.New("JWT_PUBLIC_KEY is required for RS256")
+	}
+	key, err := jwt.ParseRSAPublicKeyFromPEM([]byte(pemKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_PUBLIC_KEY: %w", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Role is a permission level granted to an API caller.
+type Role string
+
+const (
+	RoleHRAdmin Role = "hr_admin" // Reads and changes everything
+	RoleManager Role = "manager"  // Reads employees of their own subtree only
+	RoleViewer  Role = "viewer"   // Reads everything, changes nothing
+)
+
+// Principal is the authenticated caller of a request: a user holding roles
+// from a JWT, or an integration holding the scopes of its API key.
+type Principal struct {
+	Subject    string
+	EmployeeID uuid.UUID // uuid.Nil when the subject is not an employee
+	Roles      []Role
+	APIKeyID   uuid.UUID // uuid.Nil unless authenticated with an API key
+	Scopes     []string  // API key scopes; empty for users
+}
+
+// HasRole reports whether the principal was granted any of roles.
+func (p *Principal) HasRole(roles ...Role) bool {
+	for _, r := range roles {
+		if slices.Contains(p.Roles, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAPIKey reports whether the principal authenticated with an API key.
+func (p *Principal) IsAPIKey() bool {
+	return p.APIKeyID != uuid.Nil
+}
+
+// Allows reports whether the principal may perform an operation that needs
+// scope from an API key, or one of roles from a user.
+func (p *Principal) Allows(scope string, roles ...Role) bool {
+	if p.IsAPIKey() {
+		return scope != "" && slices.Contains(p.Scopes, scope)
+	}
+	return p.HasRole(roles...)
+}
+
+// SubtreeOnly reports whether the principal may only read employees of the
+// departments below them, i.e. it is a manager without a wider role.
+func (p *Principal) SubtreeOnly() bool {
+	return p.HasRole(RoleManager) && !p.HasRole(RoleHRAdmin, RoleViewer)
+}
+
+const (
+	principalKey = "auth.principal"
+	scopeKey     = "auth.scope"
+)
+
+// SetPrincipal stores the authenticated caller in the request context.
+func SetPrincipal(c *gin.Context, p *Principal) {
+	c.Set(principalKey, p)
+}
+
+// PrincipalFrom returns the authenticated caller, or nil.
+func PrincipalFrom(c *gin.Context) *Principal {
+	p, _ := c.Get(principalKey)
+	principal, _ := p.(*Principal)
+	return principal
+}
+
+// SetScope restricts the request to the given departments.
+func SetScope(c *gin.Context, deptIDs []uuid.UUID) {
+	if deptIDs == nil {
+		deptIDs = []uuid.UUID{}
+	}
+	c.Set(scopeKey, deptIDs)
+}
+
+// ScopeFrom returns the departments the request is restricted to, or nil
+// when the caller may see every department.
+func ScopeFrom(c *gin.Context) []uuid.UUID {
+	s, _ := c.Get(scopeKey)
+	scope, _ := s.([]uuid.UUID)
+	return scope
+}
+
+// InScope reports whether deptID is visible under scope.
+func InScope(scope []uuid.UUID, deptID uuid.UUID) bool {
+	return scope == nil || slices.Contains(scope, deptID)
+}
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"crypto/rand"
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 )
 
 type Config struct {
@@ -11,6 +14,19 @@ type Config struct {
 	DBUser string
 	DBPass string
 	DBName string
+
+	// Authentication (see auth.JWTConfig)
+	JWTAlgorithm     string
+	JWTSecret        string
+	JWTPublicKey     string // PEM; JWTPublicKeyFile takes precedence
+	JWTPublicKeyFile string
+	JWTIssuer        string
+	JWTAudience      string
+
+	// Confirmation of large department moves (see services.MoveConfirmation)
+	MoveConfirmationThreshold string // Headcount; 0 turns confirmation off
+	MoveConfirmationTTL       string // Go duration, e.g. "15m"
+	MoveConfirmationSecret    string
 }
 
 func Load() *Config {
@@ -20,7 +36,59 @@ func Load() *Config {
 		DBUser: getenv("DB_USER", "postgres"),
 		DBPass: getenv("DB_PASSWORD", "postgres"),
 		DBName: getenv("DB_NAME", "colaboradores_db"),
+
+		JWTAlgorithm:     getenv("JWT_ALGORITHM", "HS256"),
+		JWTSecret:        os.Getenv("JWT_SECRET"),
+		JWTPublicKey:     os.Getenv("JWT_PUBLIC_KEY"),
+		JWTPublicKeyFile: os.Getenv("JWT_PUBLIC_KEY_FILE"),
+		JWTIssuer:        os.Getenv("JWT_ISSUER"),
+		JWTAudience:      os.Getenv("JWT_AUDIENCE"),
+
+		MoveConfirmationThreshold: getenv("MOVE_CONFIRMATION_THRESHOLD", "50"),
+		MoveConfirmationTTL:       getenv("MOVE_CONFIRMATION_TTL", "15m"),
+		MoveConfirmationSecret:    os.Getenv("MOVE_CONFIRMATION_SECRET"),
+	}
+}
+
+// JWTPublicKeyPEM returns the RS256 public key, reading it from
+// JWTPublicKeyFile when one is set.
+func (c *Config) JWTPublicKeyPEM() (string, error) {
+	if c.JWTPublicKeyFile == "" {
+		return c.JWTPublicKey, nil
+	}
+	raw, err := os.ReadFile(c.JWTPublicKeyFile)
+	if err != nil {
+		return "", fmt.Errorf("reading JWT_PUBLIC_KEY_FILE: %w", err)
+	}
+	return string(raw), nil
+}
+
+// MoveConfirmationLimits parses the headcount above which department moves
+// need confirmation and how long a confirmation token lasts.
+func (c *Config) MoveConfirmationLimits() (int, time.Duration, error) {
+	threshold, err := strconv.Atoi(c.MoveConfirmationThreshold)
+	if err != nil || threshold < 0 {
+		return 0, 0, fmt.Errorf("invalid MOVE_CONFIRMATION_THRESHOLD %q", c.MoveConfirmationThreshold)
+	}
+	ttl, err := time.ParseDuration(c.MoveConfirmationTTL)
+	if err != nil || ttl <= 0 {
+		return 0, 0, fmt.Errorf("invalid MOVE_CONFIRMATION_TTL %q", c.MoveConfirmationTTL)
+	}
+	return threshold, ttl, nil
+}
+
+// MoveConfirmationKey returns the key that signs move confirmation tokens.
+// Without MoveConfirmationSecret it is random, so tokens only work on the
+// instance that issued them and until it restarts.
+func (c *Config) MoveConfirmationKey() ([]byte, error) {
+	if c.MoveConfirmationSecret != "" {
+		return []byte(c.MoveConfirmationSecret), nil
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
 	}
+	return key, nil
 }
 
 func (c *Config) DatabaseDSN() string {
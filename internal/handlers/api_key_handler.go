@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// APIKeyHandler handles HTTP requests for API keys.
+type APIKeyHandler struct {
+	service services.APIKeyService
+}
+
+// NewAPIKeyHandler creates a new API key handler.
+func NewAPIKeyHandler(s services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service: s}
+}
+
+// Create issues an API key
+// @Summary Issue an API key
+// @Description Creates a key for a service integration. The key is returned only in this response; the API stores just its hash.
+// @Description Scopes: employees:read, employees:write, departments:read, departments:write.
+// @Tags Chaves de API
+// @Accept json
+// @Produce json
+// @Param chave body models.CreateAPIKeyDTO true "Key name, scopes and optional expiry"
+// @Success 201 {object} models.CreatedAPIKeyResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid request, unknown scope or expiry in the past"
+// @Failure 401 {object} utils.ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} utils.ErrorResponse "Role not allowed"
+// @Security BearerAuth
+// @Router /chaves-api [post]
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	var dto models.CreateAPIKeyDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		respondInvalidRequest(c, "", err)
+		return
+	}
+
+	key, plaintext, err := h.service.CreateAPIKey(dto.Name, dto.Scopes, dto.ExpiresAt)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.CreatedAPIKeyResponse{APIKey: key, Key: plaintext})
+}
+
+// List lists API keys
+// @Summary List API keys
+// @Description Returns every key, revoked and expired ones included, newest first. Keys themselves are never returned.
+// @Tags Chaves de API
+// @Produce json
+// @Success 200 {array} models.APIKey
+// @Failure 401 {object} utils.ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} utils.ErrorResponse "Role not allowed"
+// @Security BearerAuth
+// @Router /chaves-api [get]
+func (h *APIKeyHandler) List(c *gin.Context) {
+	keys, err := h.service.ListAPIKeys()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if keys == nil {
+		keys = []*models.APIKey{}
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// Revoke revokes an API key
+// @Summary Revoke an API key
+// @Description Revokes a key immediately. Revoking an already revoked key is a no-op.
+// @Tags Chaves de API
+// @Param id path string true "API key ID (UUID)"
+// @Success 204 "No content"
+// @Failure 400 {object} utils.ErrorResponse "Invalid ID"
+// @Failure 404 {object} utils.ErrorResponse "API key not found"
+// @Failure 401 {object} utils.ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} utils.ErrorResponse "Role not allowed"
+// @Security BearerAuth
+// @Router /chaves-api/{id} [delete]
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, err)
+		return
+	}
+
+	if err := h.service.RevokeAPIKey(id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"ManageEmployeesandDepartments/internal/auth"
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/services"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AuditHandler handles HTTP requests for the audit log.
+type AuditHandler struct {
+	service services.AuditService
+}
+
+// NewAuditHandler creates a new audit handler.
+func NewAuditHandler(s services.AuditService) *AuditHandler {
+	return &AuditHandler{service: s}
+}
+
+// List lists audit log entries
+// @Summary List audit log entries
+// @Description Returns who created, changed or removed employees and departments, newest first, with the before/after value of every changed field.
+// @Tags Auditoria
+// @Produce json
+// @Param entity_id query string false "Employee or department ID (UUID)"
+// @Param actor query string false "Token subject, or api-key:<id>"
+// @Param from query string false "Entries at or after this time (RFC 3339)"
+// @Param to query string false "Entries before this time (RFC 3339)"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 10, max: 100)"
+// @Success 200 {object} models.AuditListResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid query parameter"
+// @Failure 401 {object} utils.ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} utils.ErrorResponse "Role not allowed"
+// @Security BearerAuth
+// @Router /auditoria [get]
+func (h *AuditHandler) List(c *gin.Context) {
+	var filter models.AuditFilter
+
+	if raw := c.Query("entity_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			respondInvalidRequest(c, "entity_id", err)
+			return
+		}
+		filter.EntityID = &id
+	}
+	if raw := c.Query("actor"); raw != "" {
+		filter.Actor = &raw
+	}
+
+	var err error
+	if filter.From, err = queryTime(c, "from"); err != nil {
+		respondInvalidRequest(c, "from", err)
+		return
+	}
+	if filter.To, err = queryTime(c, "to"); err != nil {
+		respondInvalidRequest(c, "to", err)
+		return
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		respondInvalidRequest(c, "to", errors.New("to must be after from"))
+		return
+	}
+
+	page, pageSize, ok := queryPage(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.ListAuditEntries(filter, page, pageSize)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// queryTime reads an optional RFC 3339 query parameter.
+func queryTime(c *gin.Context, key string) (*time.Time, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// actorOf identifies the caller in the audit log.
+func actorOf(c *gin.Context) string {
+	if principal := auth.PrincipalFrom(c); principal != nil {
+		return principal.Subject
+	}
+	return ""
+}
@@ -1,15 +1,14 @@
 package handlers
 
 import (
+	"ManageEmployeesandDepartments/internal/auth"
 	"ManageEmployeesandDepartments/internal/models"
 	"ManageEmployeesandDepartments/internal/services"
-	"ManageEmployeesandDepartments/internal/utils"
-	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"gorm.io/gorm"
 )
 
 // DepartamentService lida com as requisições HTTP para Departamentos.
@@ -23,156 +22,428 @@ func NewDepartamentoHandler(s services.DepartmentService) *DepartamentoHandler {
 }
 
 // Create @Summary Cria um novo departamento
-// @Description Cria um novo departamento (valida gerente_id)
+// @Description Cria um novo departamento. O gerente_id é opcional: sem ele o departamento nasce sem gerente
+// @Description (útil para os primeiros departamentos) e pode receber um depois via PUT. Quando informado,
+// @Description o gerente é transferido para o novo departamento na mesma transação.
 // @Tags Departamentos
 // @Accept json
 // @Produce json
 // @Param departamento body CreateDepartamentoDTO true "Dados do Departamento"
 // @Success 201 {object} models.Departamento
-// @Failure 400 {object} map[string]string "Requisição inválida"
-// @Failure 422 {object} map[string]string "Erro de validação (Gerente/Depto Superior inválido)"
+// @Failure 400 {object} utils.ErrorResponse "Requisição inválida"
+// @Failure 422 {object} utils.ErrorResponse "Erro de validação (Gerente/Depto Superior inválido)"
+// @Failure 401 {object} utils.ErrorResponse "Token ausente ou inválido"
+// @Failure 403 {object} utils.ErrorResponse "Perfil sem permissão"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /departamentos [post]
 func (h *DepartamentoHandler) Create(c *gin.Context) {
 	var dto models.CreateDepartmentDTO
 	if err := c.ShouldBindJSON(&dto); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Requisição inválida: " + err.Error()})
+		respondInvalidRequest(c, "", err)
 		return
 	}
 
-	depto, err := h.service.CreateDepartment(dto.Name, dto.ManagerID, dto.ParentDepartmentID)
+	depto, err := h.service.WithActor(actorOf(c)).CreateDepartment(dto.Name, dto.ManagerID, dto.ParentDepartmentID)
 	if err != nil {
-		if err == utils.ErrManagerNotFound || err == utils.ErrDepartmentNotFound || err == utils.ErrManagerNotBelongToDepartment {
-			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, depto)
+}
+
+// Reorganize @Summary Aplica uma reorganização da estrutura
+// @Description Aplica um plano de operações (rename, reparent, change_manager, merge e move_employee) em uma
+// @Description única transação, na ordem dada. O plano é validado como um todo: a árvore resultante não pode ter
+// @Description ciclos, todo gerente precisa pertencer ao departamento que gerencia e nenhum colaborador pode ficar
+// @Description sem departamento. No merge, colaboradores e subdepartamentos passam para o departamento de destino
+// @Description e o de origem é removido. Com dry_run=true nada é gravado e o relatório traz a prévia das mudanças.
+// @Description Um plano inválido não é aplicado: o relatório lista os problemas, com o índice da operação.
+// @Description Como no PUT, reparent e merge de uma subárvore com mais colaboradores que o limite configurado exigem
+// @Description o confirmation_token da prévia de movimentação para o novo superior (ou para o destino do merge).
+// @Tags Departamentos
+// @Accept json
+// @Produce json
+// @Param plano body models.ReorganizationDTO true "Operações do plano"
+// @Param dry_run query bool false "Apenas valida e mostra as mudanças (padrão: false)"
+// @Success 200 {object} models.ReorganizationReport
+// @Failure 400 {object} utils.ErrorResponse "Requisição inválida"
+// @Failure 401 {object} utils.ErrorResponse "Token ausente ou inválido"
+// @Failure 403 {object} utils.ErrorResponse "Perfil sem permissão"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /reorganizacoes [post]
+func (h *DepartamentoHandler) Reorganize(c *gin.Context) {
+	var dto models.ReorganizationDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		respondInvalidRequest(c, "", err)
+		return
+	}
+
+	dryRun := false
+	if raw := c.Query("dry_run"); raw != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			respondInvalidRequest(c, "dry_run", err)
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar departamento"})
+	}
+
+	report, err := h.service.WithActor(actorOf(c)).Reorganize(dto.Operations, dryRun)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, depto)
+	c.JSON(http.StatusOK, report)
 }
 
 // GetByID @Summary Retorna um departamento por ID com árvore hierárquica
 // @Description Retorna departamento, gerente e a árvore hierárquica completa dos subdepartamentos
+// @Description Com as_of, retorna a árvore como era naquele momento: nomes, gerentes e vínculos vigentes na data,
+// @Description incluindo departamentos removidos depois. Uma data (AAAA-MM-DD) corresponde ao fim do dia, em UTC.
 // @Tags Departamentos
 // @Produce json
 // @Param id path string true "ID do Departamento (UUID)"
+// @Param max_depth query int false "Profundidade máxima da árvore (padrão: árvore completa)"
+// @Param as_of query string false "Data (AAAA-MM-DD) ou instante (RFC 3339) da versão desejada"
 // @Success 200 {object} models.Departamento "Departamento com SubDepartamentos preenchidos"
-// @Failure 404 {object} map[string]string "Departamento não encontrado"
+// @Failure 400 {object} utils.ErrorResponse "ID, max_depth ou as_of inválido"
+// @Failure 404 {object} utils.ErrorResponse "Departamento não encontrado (ou inexistente na data)"
+// @Failure 401 {object} utils.ErrorResponse "Token ausente ou inválido"
+// @Failure 403 {object} utils.ErrorResponse "Perfil sem permissão"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /departamentos/{id} [get]
 func (h *DepartamentoHandler) GetByID(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		respondInvalidID(c, err)
 		return
 	}
 
-	// O serviço deve carregar a árvore completa
-	depto, err := h.service.GetDepartmentWithTree(id)
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Departamento não encontrado"})
+	// max_depth é opcional; 0 carrega a árvore completa
+	maxDepth := 0
+	if raw := c.Query("max_depth"); raw != "" {
+		maxDepth, err = strconv.Atoi(raw)
+		if err != nil || maxDepth < 1 {
+			respondInvalidRequest(c, "max_depth", err)
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar departamento"})
+	}
+
+	asOf, err := queryAsOf(c)
+	if err != nil {
+		respondInvalidRequest(c, "as_of", err)
 		return
 	}
 
+	depto, err := h.service.GetDepartmentWithTree(id, maxDepth, asOf)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	redactManagers(auth.ScopeFrom(c), depto)
+
 	c.JSON(http.StatusOK, depto)
 }
 
 // Update @Summary Atualiza um departamento
 // @Description Atualiza dados de um departamento (impede ciclos)
+// @Description Mover um departamento cuja subárvore tem mais colaboradores que o limite configurado exige o
+// @Description confirmation_token da prévia (GET /departamentos/{id}/previa-movimentacao) para a mesma movimentação.
 // @Tags Departamentos
 // @Accept json
 // @Produce json
 // @Param id path string true "ID do Departamento (UUID)"
 // @Param departamento body UpdateDepartamentoDTO true "Dados para atualizar"
 // @Success 200 {object} models.Departamento
-// @Failure 400 {object} map[string]string "Requisição inválida"
-// @Failure 404 {object} map[string]string "Departamento não encontrado"
-// @Failure 422 {object} map[string]string "Erro de validação (Gerente/Depto Superior inválido ou Ciclo detectado)"
+// @Failure 400 {object} utils.ErrorResponse "Requisição inválida"
+// @Failure 404 {object} utils.ErrorResponse "Departamento não encontrado"
+// @Failure 422 {object} utils.ErrorResponse "Erro de validação (Gerente/Depto Superior inválido ou Ciclo detectado)"
+// @Failure 428 {object} utils.ErrorResponse "Movimentação exige confirmação, ou token inválido ou expirado"
+// @Failure 401 {object} utils.ErrorResponse "Token ausente ou inválido"
+// @Failure 403 {object} utils.ErrorResponse "Perfil sem permissão"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /departamentos/{id} [put]
 func (h *DepartamentoHandler) Update(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		respondInvalidID(c, err)
 		return
 	}
 
 	var dto models.UpdateDepartmentDTO
 	if err := c.ShouldBindJSON(&dto); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Requisição inválida: " + err.Error()})
+		respondInvalidRequest(c, "", err)
 		return
 	}
 
-	depto, err := h.service.UpdateDepartment(id, dto.Name, dto.ManagerID, dto.ParentDepartmentID)
+	depto, err := h.service.WithActor(actorOf(c)).UpdateDepartment(id, dto.Name, dto.ManagerID, dto.ParentDepartmentID, dto.ConfirmationToken)
 	if err != nil {
-		switch err {
-		case gorm.ErrRecordNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"error": "Departamento não encontrado"})
-		case utils.ErrCycleDetected, utils.ErrManagerNotFound, utils.ErrDepartmentHasSubDepartments, utils.ErrManagerNotBelongToDepartment:
-			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar departamento"})
-		}
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, depto)
 }
 
+// PreviewMove @Summary Prévia da movimentação de um departamento
+// @Description Mostra o impacto de mover o departamento, com toda a sua subárvore, para parent_department_id (o UUID
+// @Description nulo o torna raiz), sem mover nada: os departamentos e o número de colaboradores levados, a nova
+// @Description profundidade e os gerentes cuja cadeia de superiores muda. Traz também o token de confirmação que o
+// @Description PUT /departamentos/{id} exige quando a subárvore tem mais colaboradores que o limite configurado, e
+// @Description que também vale para o reparent ou merge do departamento para baixo de parent_department_id.
+// @Tags Departamentos
+// @Produce json
+// @Param id path string true "ID do Departamento (UUID)"
+// @Param parent_department_id query string true "ID do novo departamento superior (UUID nulo para raiz)"
+// @Success 200 {object} models.MovePreview
+// @Failure 400 {object} utils.ErrorResponse "ID ou parent_department_id inválido"
+// @Failure 404 {object} utils.ErrorResponse "Departamento não encontrado"
+// @Failure 422 {object} utils.ErrorResponse "Depto Superior inválido ou Ciclo detectado"
+// @Failure 401 {object} utils.ErrorResponse "Token ausente ou inválido"
+// @Failure 403 {object} utils.ErrorResponse "Perfil sem permissão"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /departamentos/{id}/previa-movimentacao [get]
+func (h *DepartamentoHandler) PreviewMove(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, err)
+		return
+	}
+
+	parentID, err := uuid.Parse(c.Query("parent_department_id"))
+	if err != nil {
+		respondInvalidRequest(c, "parent_department_id", err)
+		return
+	}
+
+	preview, err := h.service.PreviewMove(id, parentID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
 // Delete @Summary Remove um departamento
 // @Description Remove um departamento (soft delete)
 // @Tags Departamentos
 // @Param id path string true "ID do Departamento (UUID)"
 // @Success 204 "Sem conteúdo"
-// @Failure 404 {object} map[string]string "Departamento não encontrado"
-// @Failure 422 {object} map[string]string "Não é possível remover depto com colaboradores ou sub-deptos"
+// @Failure 404 {object} utils.ErrorResponse "Departamento não encontrado"
+// @Failure 422 {object} utils.ErrorResponse "Não é possível remover depto com colaboradores ou sub-deptos"
+// @Failure 401 {object} utils.ErrorResponse "Token ausente ou inválido"
+// @Failure 403 {object} utils.ErrorResponse "Perfil sem permissão"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /departamentos/{id} [delete]
 func (h *DepartamentoHandler) Delete(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		respondInvalidID(c, err)
 		return
 	}
 
-	err = h.service.DeleteDepartment(id)
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Departamento não encontrado"})
-			return
-		}
-		if errors.Is(err, utils.ErrEmployeeNotFound) || errors.Is(err, utils.ErrDepartmentHasSubDepartments) {
-			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao remover departamento"})
+	if err := h.service.WithActor(actorOf(c)).DeleteDepartment(id); err != nil {
+		respondError(c, err)
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
 
+// Merge @Summary Mescla um departamento em outro
+// @Description Move todos os colaboradores e subdepartamentos do departamento para o de destino e remove o de origem
+// @Description (soft delete), em uma única transação, com histórico e auditoria. O gerente do destino é mantido; sem ele,
+// @Description o gerente da origem assume. manager_id escolhe outro gerente, que precisa pertencer ao departamento
+// @Description mesclado; o UUID nulo deixa o destino sem gerente. Um destino abaixo da origem ocupa o lugar dela na árvore.
+// @Description Mesclar uma subárvore com mais colaboradores que o limite configurado exige o confirmation_token da prévia
+// @Description de mover a origem para baixo do destino (GET /departamentos/{id}/previa-movimentacao).
+// @Tags Departamentos
+// @Accept json
+// @Produce json
+// @Param id path string true "ID do Departamento de origem (UUID)"
+// @Param mesclagem body models.MergeDepartmentDTO true "Departamento de destino e gerente"
+// @Success 200 {object} models.Departamento "Departamento de destino após a mesclagem"
+// @Failure 400 {object} utils.ErrorResponse "ID ou requisição inválida (inclusive origem igual ao destino)"
+// @Failure 404 {object} utils.ErrorResponse "Departamento de origem não encontrado"
+// @Failure 422 {object} utils.ErrorResponse "Destino ou gerente inválido"
+// @Failure 428 {object} utils.ErrorResponse "Mesclagem exige confirmação, ou token inválido ou expirado"
+// @Failure 401 {object} utils.ErrorResponse "Token ausente ou inválido"
+// @Failure 403 {object} utils.ErrorResponse "Perfil sem permissão"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /departamentos/{id}/mesclar [post]
+func (h *DepartamentoHandler) Merge(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, err)
+		return
+	}
+
+	var dto models.MergeDepartmentDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		respondInvalidRequest(c, "", err)
+		return
+	}
+
+	depto, err := h.service.WithActor(actorOf(c)).MergeDepartment(id, *dto.TargetDepartmentID, dto.ManagerID, dto.ConfirmationToken)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, depto)
+}
+
+// ListDeleted @Summary Lista departamentos removidos
+// @Description Retorna os departamentos removidos (soft delete), dos mais recentes aos mais antigos, com a data de remoção
+// @Tags Departamentos
+// @Produce json
+// @Param page query int false "Número da página (padrão: 1)"
+// @Param page_size query int false "Tamanho da página (padrão: 10, máximo: 100)"
+// @Success 200 {object} models.DeletedDepartmentListResponse
+// @Failure 400 {object} utils.ErrorResponse "Parâmetro inválido"
+// @Failure 401 {object} utils.ErrorResponse "Token ausente ou inválido"
+// @Failure 403 {object} utils.ErrorResponse "Perfil sem permissão"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /departamentos/excluidos [get]
+func (h *DepartamentoHandler) ListDeleted(c *gin.Context) {
+	page, pageSize, ok := queryPage(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.ListDeletedDepartments(page, pageSize)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Restore @Summary Restaura um departamento removido
+// @Description Restaura um departamento removido, sob o mesmo departamento superior, que precisa existir
+// @Description (restaure-o antes, se for o caso). O departamento volta sem gerente.
+// @Tags Departamentos
+// @Produce json
+// @Param id path string true "ID do Departamento (UUID)"
+// @Success 200 {object} models.Departamento
+// @Failure 400 {object} utils.ErrorResponse "ID inválido"
+// @Failure 404 {object} utils.ErrorResponse "Departamento não encontrado"
+// @Failure 409 {object} utils.ErrorResponse "Departamento não está removido"
+// @Failure 422 {object} utils.ErrorResponse "Departamento superior removido"
+// @Failure 401 {object} utils.ErrorResponse "Token ausente ou inválido"
+// @Failure 403 {object} utils.ErrorResponse "Perfil sem permissão"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /departamentos/{id}/restaurar [post]
+func (h *DepartamentoHandler) Restore(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, err)
+		return
+	}
+
+	depto, err := h.service.WithActor(actorOf(c)).RestoreDepartment(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, depto)
+}
+
+// departmentExportHeader nomeia as colunas da exportação de departamentos.
+var departmentExportHeader = []string{"id", "name", "manager_id", "manager_name", "parent_department_id", "parent_department_name", "created_at", "updated_at"}
+
+// Export @Summary Exporta departamentos em CSV, XLSX ou JSON Lines
+// @Description Transmite todos os departamentos que atendem aos filtros da listagem, com os nomes do gerente e do
+// @Description departamento superior. O formato vem do parâmetro format ou, na falta dele, do cabeçalho Accept
+// @Description (text/csv, application/vnd.openxmlformats-officedocument.spreadsheetml.sheet ou application/x-ndjson);
+// @Description o padrão é CSV, gravado com BOM para que o Excel o leia como UTF-8. Exportações XLSX são limitadas a
+// @Description 50000 departamentos; acima disso, use CSV ou JSON Lines. Gerentes recebem apenas os departamentos
+// @Description da subárvore que comandam.
+// @Tags Departamentos
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/x-ndjson
+// @Param format query string false "csv, xlsx ou jsonl"
+// @Param name query string false "Parte do nome, sem diferenciar maiúsculas"
+// @Param manager_name query string false "Parte do nome do gerente, sem diferenciar maiúsculas"
+// @Param parent_department_id query string false "ID do departamento superior (UUID)"
+// @Param sort query string false "name, created_at, updated_at ou manager"
+// @Param order query string false "asc (padrão) ou desc"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.ErrorResponse "Parâmetro inválido"
+// @Failure 406 {object} utils.ErrorResponse "Nenhum formato suportado aceito"
+// @Failure 422 {object} utils.ErrorResponse "Departamentos demais para XLSX"
+// @Failure 401 {object} utils.ErrorResponse "Token ausente ou inválido"
+// @Failure 403 {object} utils.ErrorResponse "Perfil sem permissão"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /departamentos/exportar [get]
+func (h *DepartamentoHandler) Export(c *gin.Context) {
+	filter := models.DepartmentFilter{
+		Name:          queryString(c, "name"),
+		ManagerName:   queryString(c, "manager_name"),
+		DepartmentIDs: auth.ScopeFrom(c),
+	}
+	var err error
+	if filter.ParentDepartmentID, err = queryUUID(c, "parent_department_id"); err != nil {
+		respondInvalidRequest(c, "parent_department_id", err)
+		return
+	}
+	sort, ok := querySort(c)
+	if !ok {
+		return
+	}
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
+	streamExport(c, format, "departamentos", departmentExportHeader, func(w exportWriter) error {
+		return h.service.ExportDepartments(filter, sort, func(d *models.DepartmentExportRow) error {
+			return w.Write(d, []string{
+				d.ID.String(), d.Name, uuidCell(d.ManagerID), deref(d.ManagerName),
+				uuidCell(d.ParentDepartmentID), deref(d.ParentDepartmentName), csvTime(d.CreatedAt), csvTime(d.UpdatedAt),
+			})
+		})
+	})
+}
+
 // List @Summary Lista departamentos com filtros
 // @Description Retorna uma lista paginada de departamentos com base nos filtros
 // @Tags Departamentos
 // @Accept json
 // @Produce json
-// @Param filtros body ListDepartamentosDTO false "Filtros e Paginação"
-// @Success 200 {array} models.Departamento
-// @Failure 400 {object} map[string]string "Requisição inválida"
+// @Param filtros body ListDepartamentosDTO false "Filtros, ordenação (sort: name, created_at, updated_at, manager; order: asc/desc) e paginação (page_size máximo: 100)"
+// @Success 200 {object} models.DepartmentListResponse
+// @Failure 400 {object} utils.ErrorResponse "Requisição inválida"
+// @Failure 401 {object} utils.ErrorResponse "Token ausente ou inválido"
+// @Failure 403 {object} utils.ErrorResponse "Perfil sem permissão"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /departamentos/listar [post]
 func (h *DepartamentoHandler) List(c *gin.Context) {
 	var dto models.ListDepartmentsDTO
 
 	// Defaults
 	dto.Page = 1
-	dto.PageSize = 10
+	dto.PageSize = models.DefaultPageSize
 
 	if err := c.ShouldBindJSON(&dto); err != nil {
 		if err.Error() != "EOF" { // Permite body vazio
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Requisição inválida: " + err.Error()})
+			respondInvalidRequest(c, "", err)
 			return
 		}
 	}
@@ -181,18 +452,33 @@ func (h *DepartamentoHandler) List(c *gin.Context) {
 		dto.Page = 1
 	}
 	if dto.PageSize <= 0 {
-		dto.PageSize = 10
+		dto.PageSize = models.DefaultPageSize
 	}
 
-	deptos, err := h.service.ListDepartments(dto.Name, dto.ManagerName, dto.ParentDepartmentID, dto.Page, dto.PageSize)
+	deptos, err := h.service.ListDepartments(dto.Name, dto.ManagerName, dto.ParentDepartmentID, models.NewSort(dto.Sort, dto.Order), dto.Page, dto.PageSize)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar departamentos"})
+		respondError(c, err)
 		return
 	}
 
 	if deptos == nil {
-		deptos = []*models.Department{}
+		deptos = models.NewPage[*models.Department](nil, dto.Page, dto.PageSize, 0)
 	}
+	redactManagers(auth.ScopeFrom(c), deptos.Items...)
 
 	c.JSON(http.StatusOK, deptos)
 }
+
+// redactManagers reduz a ID e nome os gerentes, em toda a árvore de depts,
+// que estão fora do escopo do usuário, para não expor seus dados pessoais.
+func redactManagers(scope []uuid.UUID, depts ...*models.Department) {
+	for _, d := range depts {
+		if d == nil {
+			continue
+		}
+		if d.Manager != nil && !auth.InScope(scope, d.Manager.DepartmentID) {
+			d.RedactManager()
+		}
+		redactManagers(scope, d.SubDepartments...)
+	}
+}
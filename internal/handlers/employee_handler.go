@@ -1,14 +1,20 @@
 package handlers
 
 import (
+	"ManageEmployeesandDepartments/internal/auth"
 	"ManageEmployeesandDepartments/internal/models"
 	"ManageEmployeesandDepartments/internal/services"
 	"ManageEmployeesandDepartments/internal/utils"
 	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type EmployeeHandler struct {
@@ -22,33 +28,30 @@ func NewEmployeeHandler(s services.EmployeeService) *EmployeeHandler {
 
 // Create creates a new employee
 // @Summary Create a new employee
-// @Description Creates a new employee with the provided data
+// @Description Creates a new employee with the provided data. If the CPF belongs to a removed employee, that employee is rehired instead: the same record (ID, created_at and department history) comes back with the provided name, RG and department.
 // @Tags Colaboradores
 // @Accept json
 // @Produce json
 // @Param employee body models.CreateEmployeeDTO true "Employee data"
 // @Success 201 {object} models.Employee
-// @Failure 400 {object} map[string]string "Invalid request"
-// @Failure 409 {object} map[string]string "CPF or RG already exists"
-// @Failure 422 {object} map[string]string "Department not found"
+// @Failure 400 {object} utils.ErrorResponse "Invalid request or invalid CPF"
+// @Failure 409 {object} utils.ErrorResponse "CPF or RG held by an active employee"
+// @Failure 422 {object} utils.ErrorResponse "Department not found"
+// @Failure 401 {object} utils.ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} utils.ErrorResponse "Role not allowed"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /colaboradores [post]
 func (h *EmployeeHandler) Create(c *gin.Context) {
 	var dto models.CreateEmployeeDTO
 	if err := c.ShouldBindJSON(&dto); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		respondInvalidRequest(c, "", err)
 		return
 	}
 
-	employee, err := h.service.CreateEmployee(dto.Name, dto.CPF, dto.RG, dto.DepartmentID)
+	employee, err := h.service.WithActor(actorOf(c)).CreateEmployee(dto.Name, dto.CPF, dto.RG, dto.DepartmentID)
 	if err != nil {
-		switch err {
-		case utils.ErrDepartmentNotFound:
-			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
-		case utils.ErrCPFDuplicated, utils.ErrRGDuplicated:
-			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating employee"})
-		}
+		respondError(c, err)
 		return
 	}
 
@@ -62,29 +65,103 @@ func (h *EmployeeHandler) Create(c *gin.Context) {
 // @Produce json
 // @Param id path string true "Employee ID (UUID)"
 // @Success 200 {object} models.EmployeeWithManagerResponse
-// @Failure 400 {object} map[string]string "Invalid ID"
-// @Failure 404 {object} map[string]string "Employee not found"
+// @Failure 400 {object} utils.ErrorResponse "Invalid ID"
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Failure 401 {object} utils.ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} utils.ErrorResponse "Role not allowed"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /colaboradores/{id} [get]
 func (h *EmployeeHandler) GetByID(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		respondInvalidID(c, err)
 		return
 	}
 
 	response, err := h.service.GetEmployeeWithManager(id)
 	if err != nil {
-		if errors.Is(err, utils.ErrEmployeeNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching employee"})
+		respondError(c, err)
+		return
+	}
+	// Employees outside the caller's scope look the same as unknown ones
+	if response.Employee != nil && !auth.InScope(auth.ScopeFrom(c), response.Employee.DepartmentID) {
+		respondError(c, gorm.ErrRecordNotFound)
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// History returns the departments an employee belonged to over time
+// @Summary Get the department history of an employee
+// @Description Returns every department assignment of the employee, oldest first. Periods are half-open: effective_to is the instant of the next transfer, and is null for the current department.
+// @Description With as_of, only the assignment in effect at that time is returned (an empty list if the employee was not hired yet or had left). A date (YYYY-MM-DD) means the end of that day, UTC.
+// @Tags Colaboradores
+// @Produce json
+// @Param id path string true "Employee ID (UUID)"
+// @Param as_of query string false "Date (YYYY-MM-DD) or time (RFC 3339)"
+// @Success 200 {array} models.DepartmentAssignment
+// @Failure 400 {object} utils.ErrorResponse "Invalid ID or as_of"
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Failure 401 {object} utils.ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} utils.ErrorResponse "Role not allowed"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /colaboradores/{id}/historico [get]
+func (h *EmployeeHandler) History(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, err)
+		return
+	}
+
+	asOf, err := queryAsOf(c)
+	if err != nil {
+		respondInvalidRequest(c, "as_of", err)
+		return
+	}
+
+	history, err := h.service.GetDepartmentHistory(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	// Managers see the history of the employees currently under them; the
+	// others are reported as unknown
+	if current := history[len(history)-1]; !auth.InScope(auth.ScopeFrom(c), current.DepartmentID) {
+		respondError(c, utils.ErrEmployeeNotFound)
+		return
+	}
+
+	if asOf != nil {
+		history = slices.DeleteFunc(history, func(a *models.DepartmentAssignment) bool {
+			return !a.ActiveAt(*asOf)
+		})
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// queryAsOf reads the optional as_of query parameter, a date or an RFC 3339
+// time. A date stands for the last instant of that day in UTC, so the answer
+// reflects any transfer made during the day.
+func queryAsOf(c *gin.Context) (*time.Time, error) {
+	raw := c.Query("as_of")
+	if raw == "" {
+		return nil, nil
+	}
+	if day, err := time.Parse(time.DateOnly, raw); err == nil {
+		endOfDay := day.AddDate(0, 0, 1).Add(-time.Microsecond)
+		return &endOfDay, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
 // Update updates an existing employee
 // @Summary Update an employee
 // @Description Updates an existing employee with the provided data
@@ -94,36 +171,31 @@ func (h *EmployeeHandler) GetByID(c *gin.Context) {
 // @Param id path string true "Employee ID (UUID)"
 // @Param employee body models.UpdateEmployeeDTO true "Updated employee data"
 // @Success 200 {object} models.Employee
-// @Failure 400 {object} map[string]string "Invalid request"
-// @Failure 404 {object} map[string]string "Employee not found"
-// @Failure 409 {object} map[string]string "RG already exists"
-// @Failure 422 {object} map[string]string "Department not found"
+// @Failure 400 {object} utils.ErrorResponse "Invalid request"
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Failure 409 {object} utils.ErrorResponse "RG already exists"
+// @Failure 422 {object} utils.ErrorResponse "Department not found or employee manages their current department"
+// @Failure 401 {object} utils.ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} utils.ErrorResponse "Role not allowed"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /colaboradores/{id} [put]
 func (h *EmployeeHandler) Update(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		respondInvalidID(c, err)
 		return
 	}
 
 	var dto models.UpdateEmployeeDTO
 	if err := c.ShouldBindJSON(&dto); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		respondInvalidRequest(c, "", err)
 		return
 	}
 
-	employee, err := h.service.UpdateEmployee(id, dto.Name, dto.RG, *dto.DepartmentID)
+	employee, err := h.service.WithActor(actorOf(c)).UpdateEmployee(id, dto.Name, dto.RG, *dto.DepartmentID)
 	if err != nil {
-		switch err {
-		case utils.ErrEmployeeNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-		case utils.ErrDepartmentNotFound:
-			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
-		case utils.ErrRGDuplicated:
-			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating employee"})
-		}
+		respondError(c, err)
 		return
 	}
 
@@ -136,52 +208,318 @@ func (h *EmployeeHandler) Update(c *gin.Context) {
 // @Tags Colaboradores
 // @Param id path string true "Employee ID (UUID)"
 // @Success 204
-// @Failure 400 {object} map[string]string "Invalid ID"
-// @Failure 404 {object} map[string]string "Employee not found"
-// @Failure 422 {object} map[string]string "Manager cannot be deleted"
+// @Failure 400 {object} utils.ErrorResponse "Invalid ID"
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Failure 422 {object} utils.ErrorResponse "Manager cannot be deleted"
+// @Failure 401 {object} utils.ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} utils.ErrorResponse "Role not allowed"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /colaboradores/{id} [delete]
 func (h *EmployeeHandler) Delete(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		respondInvalidID(c, err)
 		return
 	}
 
-	err = h.service.DeleteEmployee(id)
+	err = h.service.WithActor(actorOf(c)).DeleteEmployee(id)
 	if err != nil {
-		switch err {
-		case utils.ErrEmployeeNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-		case utils.ErrManagerCannotBeDeleted:
-			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error removing employee"})
-		}
+		respondError(c, err)
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
 
+// ListDeleted lists removed employees
+// @Summary List removed employees
+// @Description Returns the removed (soft-deleted) employees, most recently removed first, with the time of removal
+// @Tags Colaboradores
+// @Produce json
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 10, max: 100)"
+// @Success 200 {object} models.DeletedEmployeeListResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid query parameter"
+// @Failure 401 {object} utils.ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} utils.ErrorResponse "Role not allowed"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /colaboradores/excluidos [get]
+func (h *EmployeeHandler) ListDeleted(c *gin.Context) {
+	page, pageSize, ok := queryPage(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.ListDeletedEmployees(page, pageSize)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Restore restores a removed employee
+// @Summary Restore a removed employee
+// @Description Brings back a removed employee, in the department they left. Fails when their CPF or RG now belongs to another employee, or when the department was removed too.
+// @Tags Colaboradores
+// @Produce json
+// @Param id path string true "Employee ID (UUID)"
+// @Success 200 {object} models.Employee
+// @Failure 400 {object} utils.ErrorResponse "Invalid ID"
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Failure 409 {object} utils.ErrorResponse "Employee is not removed, or CPF/RG in use"
+// @Failure 422 {object} utils.ErrorResponse "Department no longer exists"
+// @Failure 401 {object} utils.ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} utils.ErrorResponse "Role not allowed"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /colaboradores/{id}/restaurar [post]
+func (h *EmployeeHandler) Restore(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, err)
+		return
+	}
+
+	employee, err := h.service.WithActor(actorOf(c)).RestoreEmployee(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, employee)
+}
+
+// Anonymize erases the personal data of a removed employee
+// @Summary Anonymize a removed employee (LGPD)
+// @Description Irreversibly erases the name, CPF and RG of a removed employee, also from their audit entries. The CPF becomes a tombstone; the record, its department history and audit entries are kept, and the erasure itself is audited. With dry_run=true nothing is changed and the report tells what would be.
+// @Tags Colaboradores
+// @Produce json
+// @Param id path string true "Employee ID (UUID)"
+// @Param dry_run query bool false "Only report what would change (default: false)"
+// @Success 200 {object} models.AnonymizationReport
+// @Failure 400 {object} utils.ErrorResponse "Invalid ID or dry_run"
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Failure 409 {object} utils.ErrorResponse "Employee is not removed, or already anonymized"
+// @Failure 401 {object} utils.ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} utils.ErrorResponse "Role not allowed"
+// @Security BearerAuth
+// @Router /colaboradores/{id}/anonimizar [post]
+func (h *EmployeeHandler) Anonymize(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, err)
+		return
+	}
+
+	dryRun := false
+	if raw := c.Query("dry_run"); raw != "" {
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			respondInvalidRequest(c, "dry_run", err)
+			return
+		}
+	}
+
+	report, err := h.service.WithActor(actorOf(c)).AnonymizeEmployee(id, dryRun)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// maxImportFileSize caps the size of an uploaded import file.
+const maxImportFileSize = 10 << 20
+
+// importColumns maps accepted header names to the import column they stand for.
+var importColumns = map[string]string{
+	"nome":          "name",
+	"departamento":  "department",
+	"department_id": "department",
+}
+
+// Import creates employees in bulk from a spreadsheet
+// @Summary Import employees from CSV or XLSX
+// @Description Creates employees from the rows of a CSV or XLSX file with the columns name, cpf, rg and department (also accepted: nome, departamento, department_id). The department is given by ID or by name path from the root, such as "Empresa/TI". Each row is checked with the rules of employee creation, and a CPF of a removed employee rehires them. In atomic mode (default) nothing is written when any row is invalid; in best_effort mode every valid row is written. With dry_run=true nothing is written. The report tells the outcome of each row.
+// @Tags Colaboradores
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV (comma or semicolon separated) or XLSX file, up to 5000 rows"
+// @Param mode query string false "atomic (default) or best_effort"
+// @Param dry_run query bool false "Only validate the rows (default: false)"
+// @Success 200 {object} models.ImportReport
+// @Failure 400 {object} utils.ErrorResponse "Invalid file, mode or dry_run"
+// @Failure 401 {object} utils.ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} utils.ErrorResponse "Role not allowed"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /colaboradores/importar [post]
+func (h *EmployeeHandler) Import(c *gin.Context) {
+	opts := models.ImportOptions{Mode: c.DefaultQuery("mode", models.ImportModeAtomic)}
+	if opts.Mode != models.ImportModeAtomic && opts.Mode != models.ImportModeBestEffort {
+		respondInvalidRequest(c, "mode", fmt.Errorf("mode must be %s or %s", models.ImportModeAtomic, models.ImportModeBestEffort))
+		return
+	}
+	if raw := c.Query("dry_run"); raw != "" {
+		var err error
+		if opts.DryRun, err = strconv.ParseBool(raw); err != nil {
+			respondInvalidRequest(c, "dry_run", err)
+			return
+		}
+	}
+
+	rows, err := importRows(c)
+	if err != nil {
+		respondInvalidRequest(c, "file", err)
+		return
+	}
+
+	report, err := h.service.WithActor(actorOf(c)).ImportEmployees(rows, opts)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// importRows reads the employee rows of the uploaded file. The first
+// non-blank row is the header.
+func importRows(c *gin.Context) ([]*models.EmployeeImportRow, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileSize)
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+	format, err := tableFormat(header.Filename)
+	if err != nil {
+		return nil, err
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	table, err := readTable(file, format)
+	if err != nil {
+		return nil, err
+	}
+	if len(table)-1 > models.MaxImportRows {
+		return nil, fmt.Errorf("the file has %d rows, the limit is %d", len(table)-1, models.MaxImportRows)
+	}
+
+	columns := tableColumns(table[0].cells, importColumns)
+	for _, required := range []string{"name", "cpf", "department"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing column %q", required)
+		}
+	}
+	rg, ok := columns["rg"]
+	if !ok {
+		rg = -1
+	}
+
+	rows := make([]*models.EmployeeImportRow, 0, len(table)-1)
+	for _, r := range table[1:] {
+		rows = append(rows, &models.EmployeeImportRow{
+			Line:       r.line,
+			Name:       r.cell(columns["name"]),
+			CPF:        r.cell(columns["cpf"]),
+			RG:         r.cell(rg),
+			Department: r.cell(columns["department"]),
+		})
+	}
+	return rows, nil
+}
+
+// employeeExportHeader names the columns of an employee export.
+var employeeExportHeader = []string{"id", "name", "cpf", "rg", "department_id", "department_name", "manager_name", "created_at", "updated_at"}
+
+// Export streams employees as a file
+// @Summary Export employees as CSV, XLSX or JSON Lines
+// @Description Streams every employee matching the filters of the listing, with the names of their department and of its manager. The format comes from the format parameter or, when absent, from the Accept header (text/csv, application/vnd.openxmlformats-officedocument.spreadsheetml.sheet or application/x-ndjson); CSV is the default. CSV files start with a byte order mark so that Excel reads them as UTF-8. XLSX exports are limited to 50000 employees; use CSV or JSON Lines for more. Managers only get the employees of the departments they run.
+// @Tags Colaboradores
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/x-ndjson
+// @Param format query string false "csv, xlsx or jsonl"
+// @Param name query string false "Partial, case-insensitive name"
+// @Param cpf query string false "CPF, formatted or digits only"
+// @Param rg query string false "RG"
+// @Param department_id query string false "Department ID (UUID)"
+// @Param sort query string false "name, cpf, created_at, updated_at or department"
+// @Param order query string false "asc (default) or desc"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.ErrorResponse "Invalid query parameter"
+// @Failure 406 {object} utils.ErrorResponse "No supported format accepted"
+// @Failure 422 {object} utils.ErrorResponse "Too many employees for XLSX"
+// @Failure 401 {object} utils.ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} utils.ErrorResponse "Role not allowed"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /colaboradores/exportar [get]
+func (h *EmployeeHandler) Export(c *gin.Context) {
+	filter := models.EmployeeFilter{
+		Name:          queryString(c, "name"),
+		CPF:           queryString(c, "cpf"),
+		RG:            queryString(c, "rg"),
+		DepartmentIDs: auth.ScopeFrom(c),
+	}
+	var err error
+	if filter.DepartmentID, err = queryUUID(c, "department_id"); err != nil {
+		respondInvalidRequest(c, "department_id", err)
+		return
+	}
+	sort, ok := querySort(c)
+	if !ok {
+		return
+	}
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
+	streamExport(c, format, "colaboradores", employeeExportHeader, func(w exportWriter) error {
+		return h.service.ExportEmployees(filter, sort, func(e *models.EmployeeExportRow) error {
+			return w.Write(e, []string{
+				e.ID.String(), e.Name, e.CPF, deref(e.RG), e.DepartmentID.String(), e.DepartmentName,
+				deref(e.ManagerName), csvTime(e.CreatedAt), csvTime(e.UpdatedAt),
+			})
+		})
+	})
+}
+
 // List returns paginated employees with filters
 // @Summary List employees with filters
-// @Description Returns a paginated list of employees based on filters
+// @Description Returns a paginated list of employees based on filters. Sending "after" or "before"
+// @Description switches to cursor mode, which pages by the sort key and id instead of an offset
+// @Description and answers with next_cursor/prev_cursor instead of page counts.
 // @Tags Colaboradores
 // @Accept json
 // @Produce json
-// @Param filters body models.ListEmployeesDTO false "Filters and pagination"
-// @Success 200 {array} models.Employee
-// @Failure 400 {object} map[string]string "Invalid request"
+// @Param filters body models.ListEmployeesDTO false "Filters, sorting (sort: name, cpf, created_at, updated_at, department; order: asc/desc) and pagination (page_size up to 100)"
+// @Success 200 {object} models.EmployeeListResponse "Offset mode"
+// @Success 200 {object} models.EmployeeCursorPage "Cursor mode"
+// @Failure 400 {object} utils.ErrorResponse "Invalid request, sort or cursor"
+// @Failure 401 {object} utils.ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} utils.ErrorResponse "Role not allowed"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /colaboradores/listar [post]
 func (h *EmployeeHandler) List(c *gin.Context) {
 	var dto models.ListEmployeesDTO
 
 	// Pagination defaults
 	dto.Page = 1
-	dto.PageSize = 10
+	dto.PageSize = models.DefaultPageSize
 
 	if err := c.ShouldBindJSON(&dto); err != nil && err.Error() != "EOF" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		respondInvalidRequest(c, "", err)
 		return
 	}
 
@@ -189,17 +527,49 @@ func (h *EmployeeHandler) List(c *gin.Context) {
 		dto.Page = 1
 	}
 	if dto.PageSize <= 0 {
-		dto.PageSize = 10
+		dto.PageSize = models.DefaultPageSize
 	}
 
-	employees, err := h.service.ListEmployees(dto.Name, dto.CPF, dto.RG, dto.DepartmentID, dto.Page, dto.PageSize)
+	if dto.CursorMode() {
+		h.listByCursor(c, dto)
+		return
+	}
+
+	filter := dto.Filter()
+	filter.DepartmentIDs = auth.ScopeFrom(c)
+
+	employees, err := h.service.ListEmployees(filter, models.NewSort(dto.Sort, dto.Order), dto.Page, dto.PageSize)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if employees == nil {
+		employees = models.NewPage[*models.Employee](nil, dto.Page, dto.PageSize, 0)
+	}
+
+	c.JSON(http.StatusOK, employees)
+}
+
+// listByCursor serves List in cursor mode.
+func (h *EmployeeHandler) listByCursor(c *gin.Context, dto models.ListEmployeesDTO) {
+	if dto.After != nil && dto.Before != nil {
+		respondInvalidRequest(c, "before", errors.New("after and before cannot be used together"))
+		return
+	}
+
+	filter := dto.Filter()
+	filter.DepartmentIDs = auth.ScopeFrom(c)
+
+	sort := models.NewSort(dto.Sort, dto.Order)
+	employees, err := h.service.ListEmployeesByCursor(filter, sort, dto.After, dto.Before, dto.PageSize)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing employees"})
+		respondError(c, err)
 		return
 	}
 
 	if employees == nil {
-		employees = []*models.Employee{}
+		employees = &models.EmployeeCursorPage{Items: []*models.Employee{}, PageSize: dto.PageSize}
 	}
 
 	c.JSON(http.StatusOK, employees)
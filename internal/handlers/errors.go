@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"ManageEmployeesandDepartments/internal/middleware"
+	"ManageEmployeesandDepartments/internal/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondError writes err using the shared API error envelope.
+func respondError(c *gin.Context, err error) {
+	middleware.AbortWithError(c, err)
+}
+
+// respondInvalidRequest reports a body or query parameter that failed to
+// parse. field names the offending input when it is known.
+func respondInvalidRequest(c *gin.Context, field string, err error) {
+	ce := utils.NewCustomError(http.StatusBadRequest, "Invalid request.", "")
+	ce.Field = field
+	if err != nil {
+		ce.Details = err.Error()
+	}
+	respondError(c, ce)
+}
+
+// respondInvalidID reports a path parameter that is not a valid UUID.
+func respondInvalidID(c *gin.Context, err error) {
+	ce := utils.NewCustomError(http.StatusBadRequest, "Invalid ID.", err.Error())
+	ce.ErrorCode = "INVALID_ID"
+	ce.Field = "id"
+	respondError(c, ce)
+}
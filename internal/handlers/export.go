@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/utils"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// exportContentTypes maps each export format to its media type.
+var exportContentTypes = map[string]string{
+	formatCSV:   "text/csv; charset=utf-8",
+	formatXLSX:  "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	formatJSONL: "application/x-ndjson",
+}
+
+// exportFormat picks the format of an export from the format query
+// parameter or, when absent, from the Accept header, defaulting to CSV. It
+// responds and returns false when neither names a supported format.
+func exportFormat(c *gin.Context) (string, bool) {
+	if format := c.Query("format"); format != "" {
+		if _, ok := exportContentTypes[format]; !ok {
+			respondInvalidRequest(c, "format", fmt.Errorf("format must be %s, %s or %s", formatCSV, formatXLSX, formatJSONL))
+			return "", false
+		}
+		return format, true
+	}
+
+	switch c.NegotiateFormat("text/csv", exportContentTypes[formatXLSX], exportContentTypes[formatJSONL]) {
+	case "text/csv":
+		return formatCSV, true
+	case exportContentTypes[formatXLSX]:
+		return formatXLSX, true
+	case exportContentTypes[formatJSONL]:
+		return formatJSONL, true
+	}
+
+	ce := utils.NewCustomError(http.StatusNotAcceptable, "Not acceptable.", "Accept text/csv, "+exportContentTypes[formatXLSX]+" or "+exportContentTypes[formatJSONL]+", or set the format parameter.")
+	ce.ErrorCode = "NOT_ACCEPTABLE"
+	respondError(c, ce)
+	return "", false
+}
+
+// streamExport runs export, which writes its records to the given writer,
+// and streams them as a file named name. Errors before the first bytes are
+// sent get the usual error response; after that, the response can only be
+// cut short.
+func streamExport(c *gin.Context, format, name string, header []string, export func(exportWriter) error) {
+	w, err := newExportWriter(c.Writer, format, header)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", exportContentTypes[format])
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+	if err = export(w); err == nil {
+		err = w.Close()
+	} else {
+		w.Discard()
+	}
+	if err == nil {
+		return
+	}
+
+	if !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		respondError(c, err)
+		return
+	}
+	log.Printf("%s %s: export interrupted: %v", c.Request.Method, c.Request.URL.Path, err)
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// querySort reads the sort and order query parameters of an export.
+func querySort(c *gin.Context) (models.Sort, bool) {
+	order := c.Query("order")
+	if order != "" && order != "asc" && order != "desc" {
+		respondInvalidRequest(c, "order", errors.New("order must be asc or desc"))
+		return models.Sort{}, false
+	}
+	return models.NewSort(c.Query("sort"), order), true
+}
+
+// queryString reads an optional text query parameter.
+func queryString(c *gin.Context, key string) *string {
+	if raw := c.Query(key); raw != "" {
+		return &raw
+	}
+	return nil
+}
+
+// queryUUID reads an optional UUID query parameter.
+func queryUUID(c *gin.Context, key string) (*uuid.UUID, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func uuidCell(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"ManageEmployeesandDepartments/internal/auth"
 	"ManageEmployeesandDepartments/internal/models"
 	"ManageEmployeesandDepartments/internal/services"
 	"ManageEmployeesandDepartments/internal/utils"
 	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -23,34 +25,71 @@ func NewManagerHandler(ds services.DepartmentService) *ManagerHandler {
 
 // GetSubordinates lists employees subordinated to a manager
 // @Summary List employees subordinated to a manager
-// @Description Returns all employees from departments subordinated to the manager, recursively
+// @Description Returns all employees from the departments the manager runs and from every department below them, recursively
 // @Tags Gerentes
 // @Produce json
 // @Param id path string true "Manager ID (Employee UUID)"
-// @Success 200 {array} models.Employee
-// @Failure 400 {object} map[string]string "Invalid ID"
-// @Failure 404 {object} map[string]string "Manager not found"
+// @Param depth query int false "Levels below the managed departments (default: all)"
+// @Param include_managers query bool false "Include employees who manage a department in the line (default: true)"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 10, max: 100)"
+// @Success 200 {object} models.SubordinatesResponse
+// @Failure 400 {object} utils.ErrorResponse "Invalid ID or query parameter"
+// @Failure 404 {object} utils.ErrorResponse "Manager not found"
+// @Failure 401 {object} utils.ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} utils.ErrorResponse "Role not allowed"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /gerentes/{id}/colaboradores [get]
 func (h *ManagerHandler) GetSubordinates(c *gin.Context) {
 	managerID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid manager ID"})
+		respondInvalidID(c, err)
 		return
 	}
 
-	employees, err := h.deptService.GetSubordinateEmployeesRecursively(managerID)
+	filter := models.SubordinatesFilter{
+		Scope:           auth.ScopeFrom(c),
+		IncludeManagers: true,
+		Page:            1,
+		PageSize:        models.DefaultPageSize,
+	}
+	if filter.Depth, err = queryInt(c, "depth", 0); err != nil || filter.Depth < 0 {
+		respondInvalidRequest(c, "depth", err)
+		return
+	}
+	if raw := c.Query("include_managers"); raw != "" {
+		if filter.IncludeManagers, err = strconv.ParseBool(raw); err != nil {
+			respondInvalidRequest(c, "include_managers", err)
+			return
+		}
+	}
+	if filter.Page, err = queryInt(c, "page", filter.Page); err != nil || filter.Page < 1 {
+		respondInvalidRequest(c, "page", err)
+		return
+	}
+	if filter.PageSize, err = queryInt(c, "page_size", filter.PageSize); err != nil || filter.PageSize < 1 || filter.PageSize > models.MaxPageSize {
+		respondInvalidRequest(c, "page_size", err)
+		return
+	}
+
+	response, err := h.deptService.GetSubordinateEmployeesRecursively(managerID, filter)
 	if err != nil {
 		if errors.Is(err, utils.ErrManagerNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			// The manager is the addressed resource here, not a reference
+			respondError(c, utils.WithStatus(err, http.StatusNotFound))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching subordinate employees"})
+		respondError(c, err)
 		return
 	}
 
-	if employees == nil {
-		employees = []*models.Employee{} // Returns empty list
+	if response == nil {
+		response = models.NewPage[*models.SubordinateEmployee](nil, filter.Page, filter.PageSize, 0)
+	}
+	if response.Items == nil {
+		response.Items = []*models.SubordinateEmployee{} // Returns empty list
 	}
 
-	c.JSON(http.StatusOK, employees)
+	c.JSON(http.StatusOK, response)
 }
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/services"
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PersonalDataHandler handles LGPD data-subject access requests.
+type PersonalDataHandler struct {
+	service services.PersonalDataService
+}
+
+// NewPersonalDataHandler creates a new personal data handler.
+func NewPersonalDataHandler(s services.PersonalDataService) *PersonalDataHandler {
+	return &PersonalDataHandler{service: s}
+}
+
+// Export returns everything stored about an employee
+// @Summary Export the personal data of an employee (LGPD)
+// @Description Collects everything stored about a person, removed or not: the employee record, current manager, department history, departments they managed and the audit entries about them. format=zip returns the same JSON document plus one CSV file per section.
+// @Tags Colaboradores
+// @Produce json
+// @Produce application/zip
+// @Param id path string true "Employee ID (UUID)"
+// @Param format query string false "json (default) or zip"
+// @Success 200 {object} models.PersonalDataExport
+// @Failure 400 {object} utils.ErrorResponse "Invalid ID or format"
+// @Failure 404 {object} utils.ErrorResponse "Employee not found"
+// @Failure 401 {object} utils.ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} utils.ErrorResponse "Role not allowed"
+// @Security BearerAuth
+// @Router /colaboradores/{id}/dados-pessoais [get]
+func (h *PersonalDataHandler) Export(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, err)
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "zip" {
+		respondInvalidRequest(c, "format", fmt.Errorf("unknown format %q", format))
+		return
+	}
+
+	export, err := h.service.ExportPersonalData(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, export)
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="dados-pessoais-%s.zip"`, id))
+	c.Status(http.StatusOK)
+	if err := writePersonalDataZip(c.Writer, export); err != nil {
+		// The status is already sent; all that is left is to cut the archive short.
+		_ = c.Error(err)
+	}
+}
+
+// writePersonalDataZip writes the export as a zip archive with the JSON
+// document and one CSV file per section.
+func writePersonalDataZip(w io.Writer, export *models.PersonalDataExport) error {
+	archive := zip.NewWriter(w)
+
+	f, err := archive.Create("dados-pessoais.json")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(export); err != nil {
+		return err
+	}
+
+	e := export.Employee
+	var managerID, managerName string
+	if export.Manager != nil {
+		managerID, managerName = export.Manager.ID.String(), export.Manager.Name
+	}
+	sections := []struct {
+		name   string
+		header []string
+		rows   [][]string
+	}{
+		{
+			name:   "colaborador.csv",
+			header: []string{"id", "name", "cpf", "rg", "department_id", "manager_id", "manager_name", "created_at", "updated_at", "deleted_at", "anonymized_at"},
+			rows: [][]string{{
+				e.ID.String(), e.Name, e.CPF, deref(e.RG), e.DepartmentID.String(), managerID, managerName,
+				csvTime(e.CreatedAt), csvTime(e.UpdatedAt), csvTimePtr(export.DeletedAt), csvTimePtr(e.AnonymizedAt),
+			}},
+		},
+		{
+			name:   "historico_departamentos.csv",
+			header: []string{"department_id", "department_name", "effective_from", "effective_to"},
+			rows:   assignmentRows(export.DepartmentHistory),
+		},
+		{
+			name:   "departamentos_geridos.csv",
+			header: []string{"department_id", "department_name", "from", "to"},
+			rows:   managementRows(export.ManagedDepartments),
+		},
+		{
+			name:   "auditoria.csv",
+			header: []string{"id", "created_at", "actor", "action", "field", "before", "after"},
+			rows:   auditRows(export.AuditEntries),
+		},
+	}
+	for _, section := range sections {
+		f, err := archive.Create(section.name)
+		if err != nil {
+			return err
+		}
+		out := csv.NewWriter(f)
+		if err := out.Write(section.header); err != nil {
+			return err
+		}
+		if err := out.WriteAll(section.rows); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+func assignmentRows(history []*models.DepartmentAssignment) [][]string {
+	rows := make([][]string, len(history))
+	for i, a := range history {
+		rows[i] = []string{a.DepartmentID.String(), a.DepartmentName, csvTime(a.EffectiveFrom), csvTimePtr(a.EffectiveTo)}
+	}
+	return rows
+}
+
+func managementRows(periods []*models.ManagementPeriod) [][]string {
+	rows := make([][]string, len(periods))
+	for i, p := range periods {
+		rows[i] = []string{p.DepartmentID.String(), p.DepartmentName, csvTime(p.From), csvTimePtr(p.To)}
+	}
+	return rows
+}
+
+// auditRows writes one row per changed field, and one row without a field
+// for entries that changed none.
+func auditRows(entries []*models.AuditEntry) [][]string {
+	var rows [][]string
+	for _, e := range entries {
+		prefix := []string{e.ID.String(), csvTime(e.CreatedAt), e.Actor, e.Action}
+		if len(e.Changes) == 0 {
+			rows = append(rows, append(prefix, "", "", ""))
+			continue
+		}
+		for _, field := range slices.Sorted(maps.Keys(e.Changes)) {
+			change := e.Changes[field]
+			rows = append(rows, append(append([]string{}, prefix...), field, csvValue(change.Before), csvValue(change.After)))
+		}
+	}
+	return rows
+}
+
+// csvValue renders an audited value: strings as they are, anything else as
+// JSON, and null as an empty cell.
+func csvValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+func csvTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func csvTimePtr(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return csvTime(*t)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// queryPage reads the page and page_size query parameters. On invalid input
+// it responds with 400 and returns ok false.
+func queryPage(c *gin.Context) (page, pageSize int, ok bool) {
+	page, err := queryInt(c, "page", 1)
+	if err != nil || page < 1 {
+		respondInvalidRequest(c, "page", err)
+		return 0, 0, false
+	}
+	pageSize, err = queryInt(c, "page_size", models.DefaultPageSize)
+	if err != nil || pageSize < 1 || pageSize > models.MaxPageSize {
+		respondInvalidRequest(c, "page_size", err)
+		return 0, 0, false
+	}
+	return page, pageSize, true
+}
+
+// queryInt reads an optional integer query parameter, returning fallback when absent.
+func queryInt(c *gin.Context, key string, fallback int) (int, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(raw)
+}
@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/utils"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Spreadsheet formats accepted by the import and export endpoints.
+const (
+	formatCSV   = "csv"
+	formatXLSX  = "xlsx"
+	formatJSONL = "jsonl" // Export only: one JSON object per line
+)
+
+var errEmptySheet = errors.New("the file has no rows")
+
+// tableFormat tells the format of an uploaded file from its name.
+func tableFormat(filename string) (string, error) {
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".csv":
+		return formatCSV, nil
+	case ".xlsx":
+		return formatXLSX, nil
+	default:
+		return "", fmt.Errorf("unsupported file type %q, use .csv or .xlsx", ext)
+	}
+}
+
+// tableRow is one non-blank row of a spreadsheet and its 1-based line.
+type tableRow struct {
+	line  int
+	cells []string
+}
+
+// maxXLSXImportRowSize is a generous bound on the XML one imported row
+// takes once unzipped, counting its cells and shared strings.
+const maxXLSXImportRowSize = 4 << 10
+
+// maxXLSXImportUnzipSize caps the unzipped size of an imported workbook: the
+// XML of models.MaxImportRows rows plus room for styles, themes and the rest
+// of the package. Larger workbooks are rejected before they are unzipped, so
+// a small, highly compressed file cannot exhaust the memory.
+const maxXLSXImportUnzipSize = models.MaxImportRows*maxXLSXImportRowSize + 8<<20
+
+// readTable reads the rows of a CSV file, or of the first sheet of an XLSX
+// file, skipping blank ones. CSV files may be separated by commas or, as
+// Excel saves them in Brazilian locales, by semicolons.
+func readTable(r io.Reader, format string) ([]tableRow, error) {
+	var rows []tableRow
+	add := func(line int, cells []string) {
+		for _, cell := range cells {
+			if strings.TrimSpace(cell) != "" {
+				rows = append(rows, tableRow{line: line, cells: cells})
+				return
+			}
+		}
+	}
+
+	switch format {
+	case formatCSV:
+		br := bufio.NewReader(r)
+		// Skips the byte order mark Excel writes
+		if bom, _ := br.Peek(3); bytes.Equal(bom, []byte("\xef\xbb\xbf")) {
+			_, _ = br.Discard(3)
+		}
+		first, _ := br.Peek(br.Size())
+		if i := bytes.IndexByte(first, '\n'); i >= 0 {
+			first = first[:i]
+		}
+
+		reader := csv.NewReader(br)
+		reader.FieldsPerRecord = -1
+		if bytes.Count(first, []byte(";")) > bytes.Count(first, []byte(",")) {
+			reader.Comma = ';'
+		}
+		for {
+			cells, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			line, _ := reader.FieldPos(0)
+			add(line, cells)
+		}
+
+	case formatXLSX:
+		book, err := excelize.OpenReader(r, excelize.Options{
+			UnzipSizeLimit:    maxXLSXImportUnzipSize,
+			UnzipXMLSizeLimit: maxXLSXImportUnzipSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		defer book.Close()
+
+		sheet := book.GetSheetName(0)
+		sheetRows, err := book.Rows(sheet)
+		if err != nil {
+			return nil, err
+		}
+		defer sheetRows.Close()
+		for line := 1; sheetRows.Next(); line++ {
+			cells, err := sheetRows.Columns()
+			if err != nil {
+				return nil, err
+			}
+			add(line, cells)
+		}
+		if err := sheetRows.Error(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil, errEmptySheet
+	}
+	return rows, nil
+}
+
+// tableColumns maps the header cells of a spreadsheet to their index, by
+// lower-cased name, resolving aliases to the name they stand for.
+func tableColumns(header []string, aliases map[string]string) map[string]int {
+	columns := map[string]int{}
+	for i, cell := range header {
+		name := strings.ToLower(strings.TrimSpace(cell))
+		if alias, ok := aliases[name]; ok {
+			name = alias
+		}
+		if _, dup := columns[name]; !dup {
+			columns[name] = i
+		}
+	}
+	return columns
+}
+
+// cell returns the cell of row at index i, or "" when the row is shorter.
+func (r tableRow) cell(i int) string {
+	if i < 0 || i >= len(r.cells) {
+		return ""
+	}
+	return strings.TrimSpace(r.cells[i])
+}
+
+// exportWriter writes the records of an export in one format. Spreadsheet
+// formats take the cells of each record; JSON Lines takes the record itself.
+type exportWriter interface {
+	Write(record any, cells []string) error
+	// Close writes whatever is still buffered. No bytes reach the response
+	// before the first internal flush, so early errors can still be reported.
+	Close() error
+	// Discard drops whatever is still buffered, after a failed export.
+	Discard()
+}
+
+// newExportWriter starts an export to w in format, with header as the first
+// row of spreadsheet formats.
+func newExportWriter(w io.Writer, format string, header []string) (exportWriter, error) {
+	switch format {
+	case formatCSV:
+		out := csv.NewWriter(w)
+		// The byte order mark makes Excel read the file as UTF-8
+		if err := out.Write(append([]string{"\ufeff" + header[0]}, header[1:]...)); err != nil {
+			return nil, err
+		}
+		return &csvExportWriter{out: out}, nil
+
+	case formatXLSX:
+		book := excelize.NewFile()
+		stream, err := book.NewStreamWriter(book.GetSheetName(0))
+		if err != nil {
+			book.Close()
+			return nil, err
+		}
+		xw := &xlsxExportWriter{w: w, book: book, stream: stream}
+		if err := xw.Write(nil, header); err != nil {
+			book.Close()
+			return nil, err
+		}
+		return xw, nil
+
+	case formatJSONL:
+		buf := bufio.NewWriter(w)
+		return &jsonlExportWriter{buf: buf, enc: json.NewEncoder(buf)}, nil
+	}
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
+
+type csvExportWriter struct {
+	out *csv.Writer
+}
+
+func (w *csvExportWriter) Write(_ any, cells []string) error {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		escaped[i] = escapeCSVFormula(cell)
+	}
+	return w.out.Write(escaped)
+}
+
+// escapeCSVFormula keeps a spreadsheet from running a cell as a formula
+// when the CSV is opened, by prefixing the cell with a quote. XLSX cells are
+// written as strings and need no escaping.
+func escapeCSVFormula(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+func (w *csvExportWriter) Close() error {
+	w.out.Flush()
+	return w.out.Error()
+}
+
+func (w *csvExportWriter) Discard() {}
+
+// MaxXLSXExportRows caps the records of an XLSX export. excelize zips the
+// whole workbook in memory when it is written, so an XLSX export takes memory
+// in proportion to its rows; larger exports must use CSV or JSON Lines,
+// which are streamed.
+const MaxXLSXExportRows = 50_000
+
+// errXLSXTooLarge rejects an XLSX export above MaxXLSXExportRows.
+func errXLSXTooLarge() error {
+	ce := utils.NewCustomError(http.StatusUnprocessableEntity, "Export too large for XLSX.",
+		fmt.Sprintf("XLSX exports are limited to %d records, use format=%s or format=%s.", MaxXLSXExportRows, formatCSV, formatJSONL))
+	ce.ErrorCode = "EXPORT_TOO_LARGE"
+	ce.Field = "format"
+	return ce
+}
+
+// xlsxExportWriter writes rows through an excelize stream writer, which
+// spills the sheet to a temporary file as it grows. The workbook is only
+// assembled, in memory, and sent on Close, so nothing reaches the response
+// before every row is in and an export above MaxXLSXExportRows can still be
+// rejected.
+type xlsxExportWriter struct {
+	w      io.Writer
+	book   *excelize.File
+	stream *excelize.StreamWriter
+	rows   int
+}
+
+func (w *xlsxExportWriter) Write(_ any, cells []string) error {
+	if w.rows > MaxXLSXExportRows { // The header takes the first row
+		return errXLSXTooLarge()
+	}
+	w.rows++
+	values := make([]any, len(cells))
+	for i, cell := range cells {
+		values[i] = cell
+	}
+	axis, err := excelize.CoordinatesToCellName(1, w.rows)
+	if err != nil {
+		return err
+	}
+	return w.stream.SetRow(axis, values)
+}
+
+func (w *xlsxExportWriter) Close() error {
+	defer w.book.Close()
+	if err := w.stream.Flush(); err != nil {
+		return err
+	}
+	return w.book.Write(w.w)
+}
+
+func (w *xlsxExportWriter) Discard() {
+	w.book.Close()
+}
+
+type jsonlExportWriter struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func (w *jsonlExportWriter) Write(record any, _ []string) error {
+	return w.enc.Encode(record)
+}
+
+func (w *jsonlExportWriter) Close() error {
+	return w.buf.Flush()
+}
+
+func (w *jsonlExportWriter) Discard() {}
@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"ManageEmployeesandDepartments/internal/auth"
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/utils"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// APIKeyHeader carries the key of service-to-service callers.
+const APIKeyHeader = "X-API-Key"
+
+// SubtreeResolver returns the IDs of the departments a manager runs and of
+// every department below them.
+type SubtreeResolver func(managerID uuid.UUID) ([]uuid.UUID, error)
+
+// APIKeyResolver returns the active API key matching a plaintext key, or
+// utils.ErrUnauthorized.
+type APIKeyResolver func(key string) (*models.APIKey, error)
+
+// Auth authenticates requests and scopes what managers can read.
+type Auth struct {
+	verifier *auth.Verifier
+	subtree  SubtreeResolver
+	keys     APIKeyResolver
+}
+
+// NewAuth creates the authentication middleware factory.
+func NewAuth(verifier *auth.Verifier, subtree SubtreeResolver, keys APIKeyResolver) *Auth {
+	return &Auth{verifier: verifier, subtree: subtree, keys: keys}
+}
+
+// Authenticate requires either an API key in the X-API-Key header or a valid
+// bearer token. For managers it also resolves the departments they may read
+// (see auth.ScopeFrom).
+func (a *Auth) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key := c.GetHeader(APIKeyHeader); key != "" {
+			a.authenticateKey(c, key)
+			return
+		}
+
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			unauthorized(c, utils.ErrUnauthorized)
+			return
+		}
+
+		principal, err := a.verifier.Verify(token)
+		if err != nil {
+			unauthorized(c, fmt.Errorf("%w: %v", utils.ErrUnauthorized, err))
+			return
+		}
+		auth.SetPrincipal(c, principal)
+
+		if principal.SubtreeOnly() {
+			scope, err := a.subtree(principal.EmployeeID)
+			if err != nil {
+				AbortWithError(c, err)
+				return
+			}
+			auth.SetScope(c, scope)
+		}
+
+		c.Next()
+	}
+}
+
+func (a *Auth) authenticateKey(c *gin.Context, plaintext string) {
+	if a.keys == nil {
+		unauthorized(c, utils.ErrUnauthorized)
+		return
+	}
+
+	key, err := a.keys(plaintext)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	auth.SetPrincipal(c, &auth.Principal{
+		Subject:  "api-key:" + key.ID.String(),
+		APIKeyID: key.ID,
+		Scopes:   key.Scopes,
+	})
+	c.Next()
+}
+
+// RequireRoles lets the request through only when the caller is a user with
+// one of roles. API keys are always rejected.
+func RequireRoles(roles ...auth.Role) gin.HandlerFunc {
+	return RequireAccess("", roles...)
+}
+
+// RequireAccess lets the request through when the caller is a user with one
+// of roles or an API key granted scope.
+func RequireAccess(scope string, roles ...auth.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := auth.PrincipalFrom(c)
+		if principal == nil {
+			unauthorized(c, utils.ErrUnauthorized)
+			return
+		}
+		if !principal.Allows(scope, roles...) {
+			AbortWithError(c, utils.ErrForbidden)
+			return
+		}
+		c.Next()
+	}
+}
+
+func unauthorized(c *gin.Context, err error) {
+	c.Header("WWW-Authenticate", `Bearer realm="api"`)
+	AbortWithError(c, err)
+}
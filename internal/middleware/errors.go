@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"ManageEmployeesandDepartments/internal/utils"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AbortWithError writes err as the API error envelope and aborts the chain.
+// Server-side failures are logged and their details are kept out of the body.
+func AbortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+
+	ce := *utils.MapErrorToCustom(err)
+	if ce.Code >= http.StatusInternalServerError {
+		log.Printf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		ce.Details = ""
+	}
+
+	c.AbortWithStatusJSON(ce.Code, utils.ErrorResponse{Error: &ce})
+}
+
+// ErrorHandler renders the last error recorded with c.Error when the handler
+// did not produce a response (or a status) itself.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() || c.Writer.Status() != http.StatusOK {
+			return
+		}
+		AbortWithError(c, c.Errors.Last().Err)
+	}
+}
+
+// Recovery turns panics into a 500 envelope instead of an empty response.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered any) {
+		log.Printf("panic recovered on %s %s: %v", c.Request.Method, c.Request.URL.Path, recovered)
+		ce := utils.NewCustomError(http.StatusInternalServerError, "Internal server error.", "")
+		c.AbortWithStatusJSON(ce.Code, utils.ErrorResponse{Error: ce})
+	})
+}
+
+// NoRoute answers unknown paths with the API error envelope.
+func NoRoute(c *gin.Context) {
+	ce := utils.NewCustomError(http.StatusNotFound, "Resource not found.", "no route for "+c.Request.Method+" "+c.Request.URL.Path)
+	c.AbortWithStatusJSON(ce.Code, utils.ErrorResponse{Error: ce})
+}
@@ -1,6 +1,10 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // EmployeeWithManagerResponse is the DTO response for GetByID.
 type EmployeeWithManagerResponse struct {
@@ -10,7 +14,7 @@ type EmployeeWithManagerResponse struct {
 
 type CreateEmployeeDTO struct {
 	Name         string    `json:"name" binding:"required"`
-	CPF          string    `json:"cpf" binding:"required"` // Format validation (e.g: 11 digits) can be added
+	CPF          string    `json:"cpf" binding:"required"` // Formatted or digits only; stored as 11 digits
 	RG           *string   `json:"rg"`
 	DepartmentID uuid.UUID `json:"department_id" binding:"required"`
 }
@@ -23,32 +27,220 @@ type UpdateEmployeeDTO struct {
 
 type ListEmployeesDTO struct {
 	Name         *string    `json:"name"`
-	CPF          *string    `json:"cpf"`
+	CPF          *string    `json:"cpf"` // Formatted or digits only
 	RG           *string    `json:"rg"`
 	DepartmentID *uuid.UUID `json:"department_id"`
+	Sort         string     `json:"sort"`                                     // name, cpf, created_at, updated_at or department
+	Order        string     `json:"order" binding:"omitempty,oneof=asc desc"` // Defaults to asc
 	Page         int        `json:"page" binding:"omitempty,gte=1"`
-	PageSize     int        `json:"page_size" binding:"omitempty,gte=1"`
+	PageSize     int        `json:"page_size" binding:"omitempty,gte=1,lte=100"` // Up to MaxPageSize
+
+	// Cursor mode: set one of them to a cursor from a previous response, or to
+	// "" to start from the first (after) or last (before) row. Page is ignored.
+	After  *string `json:"after"`
+	Before *string `json:"before"`
+}
+
+// Filter returns the listing filters of the request.
+func (d ListEmployeesDTO) Filter() EmployeeFilter {
+	return EmployeeFilter{Name: d.Name, CPF: d.CPF, RG: d.RG, DepartmentID: d.DepartmentID}
+}
+
+// CursorMode reports whether the request asks for keyset pagination.
+func (d ListEmployeesDTO) CursorMode() bool {
+	return d.After != nil || d.Before != nil
+}
+
+// EmployeeFilter holds the filters of an employee listing.
+type EmployeeFilter struct {
+	Name         *string
+	CPF          *string
+	RG           *string
+	DepartmentID *uuid.UUID
+
+	// DepartmentIDs limits the listing to these departments when not nil. It
+	// is set by the server (a manager's subtree), never by the client.
+	DepartmentIDs []uuid.UUID
 }
 
 // Department DTOs
+
+// CreateDepartmentDTO is used to create a department. ManagerID is optional so
+// that a department can exist before its first employee; when set, the manager
+// is moved into the new department.
 type CreateDepartmentDTO struct {
 	Name               string     `json:"name" binding:"required"`
-	ManagerID          uuid.UUID  `json:"manager_id" binding:"required"`
+	ManagerID          uuid.UUID  `json:"manager_id"`
 	ParentDepartmentID *uuid.UUID `json:"parent_department_id"`
 }
 
-// UpdateDepartmentDTO is used to update a department.
+// UpdateDepartmentDTO is used to update a department. ConfirmationToken comes
+// from the move preview, for moves above the confirmation threshold.
 type UpdateDepartmentDTO struct {
 	Name               *string    `json:"name"`
 	ManagerID          *uuid.UUID `json:"manager_id"`
 	ParentDepartmentID *uuid.UUID `json:"parent_department_id"`
+	ConfirmationToken  string     `json:"confirmation_token"`
 }
 
-// ListDepartmentsDTO is used for filters and pagination.
+// ListDepartmentsDTO is used for filters, sorting and pagination.
 type ListDepartmentsDTO struct {
 	Name               *string    `json:"name"`
-	ManagerName        *string    `json:"manager_name"` // Special filter
+	ManagerName        *string    `json:"manager_name"` // Partial, case-insensitive match on the manager's name
 	ParentDepartmentID *uuid.UUID `json:"parent_department_id"`
+	Sort               string     `json:"sort"`                                     // name, created_at, updated_at or manager
+	Order              string     `json:"order" binding:"omitempty,oneof=asc desc"` // Defaults to asc
 	Page               int        `json:"page" binding:"omitempty,gte=1"`
-	PageSize           int        `json:"page_size" binding:"omitempty,gte=1"`
+	PageSize           int        `json:"page_size" binding:"omitempty,gte=1,lte=100"` // Up to MaxPageSize
+}
+
+// DepartmentFilter holds the filters of a department export.
+type DepartmentFilter struct {
+	Name               *string
+	ManagerName        *string
+	ParentDepartmentID *uuid.UUID
+
+	// DepartmentIDs limits the export to these departments when not nil. It
+	// is set by the server (a manager's subtree), never by the client.
+	DepartmentIDs []uuid.UUID
+}
+
+// MergeDepartmentDTO merges a department into TargetDepartmentID. ManagerID
+// picks the surviving manager: absent keeps the target's (or the source's,
+// when the target has none), the nil UUID leaves the target without one.
+// ConfirmationToken comes from the preview of moving the department under
+// the target, for merges above the confirmation threshold.
+type MergeDepartmentDTO struct {
+	TargetDepartmentID *uuid.UUID `json:"target_department_id" binding:"required"`
+	ManagerID          *uuid.UUID `json:"manager_id"`
+	ConfirmationToken  string     `json:"confirmation_token"`
+}
+
+// Reorganization DTOs
+
+// ReorganizationDTO is a plan of operations applied to the department tree
+// in one transaction, in order.
+type ReorganizationDTO struct {
+	Operations []ReorganizationOperation `json:"operations" binding:"required,min=1,max=1000,dive"` // Up to MaxReorganizationOperations
+}
+
+// API key DTOs
+
+// CreateAPIKeyDTO is used to issue an API key. Keys without ExpiresAt never
+// expire and stay valid until revoked.
+type CreateAPIKeyDTO struct {
+	Name      string     `json:"name" binding:"required"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1"` // See KnownScopes
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// CreatedAPIKeyResponse is returned once, on creation: Key is the only copy
+// of the plaintext key.
+type CreatedAPIKeyResponse struct {
+	*APIKey
+	Key string `json:"key"`
+}
+
+// Sort is the ordering requested for a listing. An empty Field keeps the
+// default order, which is creation order since IDs are UUIDv7.
+type Sort struct {
+	Field string
+	Desc  bool
 }
+
+// NewSort builds a Sort from the sort/order pair of a list request.
+func NewSort(field, order string) Sort {
+	return Sort{Field: field, Desc: order == "desc"}
+}
+
+// SubordinatesFilter holds the options for listing a manager's reporting line.
+type SubordinatesFilter struct {
+	Depth           int  // Levels below the managed departments (0 = all levels)
+	IncludeManagers bool // Also lists employees who manage a department in the line
+	Page            int
+	PageSize        int
+
+	// Scope limits the line to these departments when not nil (the caller's
+	// own subtree, for managers).
+	Scope []uuid.UUID
+}
+
+// SubordinateEmployee is an employee in a manager's reporting line, tagged with
+// the department they were found in.
+type SubordinateEmployee struct {
+	*Employee
+	DepartmentName string `json:"department_name"`
+	Depth          int    `json:"depth"` // 0 = a department managed directly
+}
+
+// SubordinatesResponse is the paginated response of GET /gerentes/:id/colaboradores.
+type SubordinatesResponse = Page[*SubordinateEmployee]
+
+// Pagination defaults shared by every list endpoint.
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 100 // Keep in sync with the lte= binding of the list DTOs
+)
+
+// Page is the envelope of every paginated list response.
+type Page[T any] struct {
+	Items      []T   `json:"items"`
+	Page       int   `json:"page"`
+	PageSize   int   `json:"page_size"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
+
+// NewPage builds the envelope for one page of a result of total rows.
+func NewPage[T any](items []T, page, pageSize int, total int64) *Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	totalPages := 0
+	if pageSize > 0 {
+		totalPages = int((total + int64(pageSize) - 1) / int64(pageSize))
+	}
+	return &Page[T]{
+		Items:      items,
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+}
+
+// EmployeeListResponse is the paginated response of POST /colaboradores/listar.
+type EmployeeListResponse = Page[*Employee]
+
+// DepartmentListResponse is the paginated response of POST /departamentos/listar.
+type DepartmentListResponse = Page[*Department]
+
+// DeletedEmployee is a removed employee, with the time of removal.
+type DeletedEmployee struct {
+	*Employee
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// DeletedDepartment is a removed department, with the time of removal.
+type DeletedDepartment struct {
+	*Department
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// DeletedEmployeeListResponse is the paginated response of GET /colaboradores/excluidos.
+type DeletedEmployeeListResponse = Page[*DeletedEmployee]
+
+// DeletedDepartmentListResponse is the paginated response of GET /departamentos/excluidos.
+type DeletedDepartmentListResponse = Page[*DeletedDepartment]
+
+// AuditFilter selects audit entries. Nil fields are not filtered on; From is
+// inclusive and To exclusive.
+type AuditFilter struct {
+	EntityID *uuid.UUID
+	Actor    *string
+	From     *time.Time
+	To       *time.Time
+}
+
+// AuditListResponse is the paginated response of GET /auditoria.
+type AuditListResponse = Page[*AuditEntry]
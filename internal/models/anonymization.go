@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AnonymizedName replaces the name of an anonymized employee.
+const AnonymizedName = "Anonymized"
+
+// RedactedValue replaces personal data in the audit entries of an anonymized
+// employee.
+const RedactedValue = "[redacted]"
+
+// EmployeePersonalFields are the employee fields, by JSON name, that identify
+// a person and are erased on anonymization.
+var EmployeePersonalFields = []string{"name", "cpf", "rg"}
+
+// AnonymizationReport describes an erasure, or in a dry run the erasure that
+// would be made. It never carries the erased values.
+type AnonymizationReport struct {
+	EmployeeID           uuid.UUID  `json:"employee_id"`
+	DryRun               bool       `json:"dry_run"`
+	Fields               []string   `json:"fields"`        // Fields that hold personal data and are scrubbed
+	CPFTombstone         string     `json:"cpf_tombstone"` // Value that replaces the CPF
+	AuditEntriesRedacted int        `json:"audit_entries_redacted"`
+	AnonymizedAt         *time.Time `json:"anonymized_at,omitempty"` // Empty in a dry run
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// API key scopes. Each one grants reading or changing one kind of resource.
+const (
+	ScopeEmployeesRead    = "employees:read"
+	ScopeEmployeesWrite   = "employees:write"
+	ScopeDepartmentsRead  = "departments:read"
+	ScopeDepartmentsWrite = "departments:write"
+)
+
+// KnownScopes lists every scope an API key can be granted.
+var KnownScopes = []string{ScopeEmployeesRead, ScopeEmployeesWrite, ScopeDepartmentsRead, ScopeDepartmentsWrite}
+
+// Scopes is a set of API key scopes, stored as a space-separated string.
+type Scopes []string
+
+// Has reports whether scope is in the set.
+func (s Scopes) Has(scope string) bool {
+	return slices.Contains(s, scope)
+}
+
+func (s Scopes) Value() (driver.Value, error) {
+	return strings.Join(s, " "), nil
+}
+
+func (s *Scopes) Scan(value any) error {
+	switch v := value.(type) {
+	case string:
+		*s = strings.Fields(v)
+	case []byte:
+		*s = strings.Fields(string(v))
+	case nil:
+		*s = nil
+	default:
+		return errors.New("unsupported type for Scopes")
+	}
+	return nil
+}
+
+// APIKey is a credential for service-to-service integrations. Only the
+// SHA-256 hash of the key is stored; the key itself is shown once, on creation.
+type APIKey struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	Name       string     `gorm:"not null" json:"name"`
+	Prefix     string     `gorm:"not null" json:"prefix"` // First characters of the key, to tell keys apart
+	KeyHash    string     `gorm:"not null;uniqueIndex" json:"-"`
+	Scopes     Scopes     `gorm:"type:text;not null" json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Active reports whether the key can still authenticate at the given time.
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// BeforeCreate is a GORM hook to generate UUID v7 before creating.
+func (k *APIKey) BeforeCreate(tx *gorm.DB) (err error) {
+	if k.ID == uuid.Nil {
+		k.ID, err = uuid.NewV7()
+	}
+	return err
+}
+
+// TableName specifies the table name for this model
+func (APIKey) TableName() string {
+	return "api_keys"
+}
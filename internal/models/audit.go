@@ -0,0 +1,87 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Audited entities.
+const (
+	AuditEntityEmployee   = "employee"
+	AuditEntityDepartment = "department"
+)
+
+// Audited actions.
+const (
+	AuditActionCreate    = "create"
+	AuditActionUpdate    = "update"
+	AuditActionDelete    = "delete"
+	AuditActionRestore   = "restore"
+	AuditActionRehire    = "rehire"
+	AuditActionAnonymize = "anonymize"
+)
+
+// AuditChange is the value of one field before and after a mutation. Before
+// is null on create and After is null on delete.
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// AuditChanges maps each changed field to its before/after values. It is
+// stored as a JSON document.
+type AuditChanges map[string]AuditChange
+
+func (c AuditChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(c)
+	return string(b), err
+}
+
+func (c *AuditChanges) Scan(value any) error {
+	switch v := value.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	case []byte:
+		return json.Unmarshal(v, c)
+	case nil:
+		*c = nil
+		return nil
+	default:
+		return errors.New("unsupported type for AuditChanges")
+	}
+}
+
+// AuditEntry records one mutation: who made it, when, on which entity, and
+// what changed. Entries are written in the transaction of the mutation and
+// never updated.
+type AuditEntry struct {
+	ID       uuid.UUID    `gorm:"type:uuid;primary_key;" json:"id"`
+	Actor    string       `gorm:"not null;index" json:"actor"` // Token subject, or api-key:<id>
+	Entity   string       `gorm:"not null" json:"entity"`
+	EntityID uuid.UUID    `gorm:"type:uuid;not null;index" json:"entity_id"`
+	Action   string       `gorm:"not null" json:"action"`
+	Changes  AuditChanges `gorm:"type:text;not null" json:"changes"`
+
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// BeforeCreate is a GORM hook to generate UUID v7 before creating.
+func (a *AuditEntry) BeforeCreate(tx *gorm.DB) (err error) {
+	if a.ID == uuid.Nil {
+		a.ID, err = uuid.NewV7()
+	}
+	return err
+}
+
+// TableName specifies the table name for this model
+func (AuditEntry) TableName() string {
+	return "audit_log"
+}
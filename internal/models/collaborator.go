@@ -11,15 +11,16 @@ import (
 type Employee struct {
 	ID   uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
 	Name string    `gorm:"not null" json:"name"`
-	CPF  string    `gorm:"not null;uniqueIndex" json:"cpf"`
-	RG   *string   `gorm:"uniqueIndex" json:"rg"` // Pointer to accept NULL
+	CPF  string    `gorm:"not null;uniqueIndex:uq_cpf,where:deleted_at IS NULL" json:"cpf"` // Unique among active employees
+	RG   *string   `gorm:"uniqueIndex:uq_rg,where:deleted_at IS NULL" json:"rg"`            // Pointer to accept NULL
 
 	DepartmentID uuid.UUID  `gorm:"not null" json:"department_id"`
 	Department   Department `gorm:"foreignKey:DepartmentID" json:"-"` // Avoids recursion in JSON
 
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+	AnonymizedAt *time.Time     `json:"anonymized_at,omitempty"` // Set once personal data is erased (LGPD)
 }
 
 // BeforeCreate is a GORM hook to generate UUID v7 before creating.
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// Cursor is the position of a row in a sorted listing: the sort it was taken
+// from, the row's value for the sort field and its ID as the tiebreak.
+// Clients only ever see it encoded, as an opaque string.
+type Cursor struct {
+	Sort string    `json:"s,omitempty"`
+	Desc bool      `json:"d,omitempty"`
+	Key  string    `json:"k,omitempty"`
+	ID   uuid.UUID `json:"id"`
+}
+
+// Encode returns the opaque form of the cursor.
+func (c Cursor) Encode() string {
+	raw, _ := json.Marshal(c) // Cannot fail for this struct
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor parses a cursor produced by Encode.
+func DecodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Matches reports whether the cursor was taken from a listing sorted by sort.
+func (c Cursor) Matches(sort Sort) bool {
+	return c.Sort == sort.Field && c.Desc == sort.Desc
+}
+
+// CursorPage is the envelope of a keyset-paginated list response. A nil
+// cursor means there is nothing more in that direction.
+type CursorPage[T any] struct {
+	Items      []T     `json:"items"`
+	PageSize   int     `json:"page_size"`
+	NextCursor *string `json:"next_cursor"`
+	PrevCursor *string `json:"prev_cursor"`
+}
+
+// EmployeeCursorPage is the response of POST /colaboradores/listar in cursor mode.
+type EmployeeCursorPage = CursorPage[*Employee]
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -24,6 +25,26 @@ type Department struct {
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	managerRedacted bool // Set by RedactManager
+}
+
+// RedactManager makes the department serialize its manager by ID and name
+// only, leaving out the manager's personal data.
+func (d *Department) RedactManager() {
+	d.managerRedacted = true
+}
+
+// MarshalJSON writes a redacted manager as a PersonRef.
+func (d Department) MarshalJSON() ([]byte, error) {
+	type department Department // Without the method, so it does not recurse
+	if !d.managerRedacted || d.Manager == nil {
+		return json.Marshal(department(d))
+	}
+	return json.Marshal(struct {
+		department
+		Manager *PersonRef `json:"manager"`
+	}{department(d), &PersonRef{ID: d.Manager.ID, Name: d.Manager.Name}})
 }
 
 // BeforeCreate is a GORM hook to generate UUID v7 before creating.
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DepartmentVersion is the state of a department (name, manager and parent)
+// during a period. Periods are half-open, [ValidFrom, ValidTo); the current
+// version has no ValidTo and a removed department has none current.
+type DepartmentVersion struct {
+	ID                 uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	DepartmentID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"department_id"`
+	Name               string     `gorm:"not null" json:"name"`
+	ManagerID          *uuid.UUID `json:"manager_id"`
+	ParentDepartmentID *uuid.UUID `gorm:"index" json:"parent_department_id"`
+	ValidFrom          time.Time  `gorm:"not null" json:"valid_from"`
+	ValidTo            *time.Time `json:"valid_to"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewDepartmentVersion captures the current state of dept, valid from at.
+func NewDepartmentVersion(dept *Department, at time.Time) *DepartmentVersion {
+	return &DepartmentVersion{
+		DepartmentID:       dept.ID,
+		Name:               dept.Name,
+		ManagerID:          dept.ManagerID,
+		ParentDepartmentID: dept.ParentDepartmentID,
+		ValidFrom:          at,
+	}
+}
+
+// Department rebuilds the department as it was during this version.
+// CreatedAt is left zero; UpdatedAt is when the version took effect.
+func (v *DepartmentVersion) Department() *Department {
+	return &Department{
+		ID:                 v.DepartmentID,
+		Name:               v.Name,
+		ManagerID:          v.ManagerID,
+		ParentDepartmentID: v.ParentDepartmentID,
+		UpdatedAt:          v.ValidFrom,
+	}
+}
+
+// BeforeCreate is a GORM hook to generate UUID v7 before creating.
+func (v *DepartmentVersion) BeforeCreate(tx *gorm.DB) (err error) {
+	if v.ID == uuid.Nil {
+		v.ID, err = uuid.NewV7()
+	}
+	return err
+}
+
+// TableName specifies the table name for this model
+func (DepartmentVersion) TableName() string {
+	return "department_history"
+}
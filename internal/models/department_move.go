@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MovePreview is the impact of moving a department, with its whole subtree,
+// under a new parent. Depths count the ancestors of a department, so a root
+// is at depth 0.
+type MovePreview struct {
+	DepartmentID          uuid.UUID          `json:"department_id"`
+	ParentDepartmentID    *uuid.UUID         `json:"parent_department_id"`     // Current parent
+	NewParentDepartmentID *uuid.UUID         `json:"new_parent_department_id"` // Nil when the department becomes a root
+	Depth                 int                `json:"depth"`
+	NewDepth              int                `json:"new_depth"`
+	Headcount             int64              `json:"headcount"` // Employees of the whole subtree
+	Departments           []*MovedDepartment `json:"departments"`
+	ReportingChanges      []*ReportingChange `json:"reporting_changes"`
+
+	// ConfirmationToken must be sent with the move (PUT /departamentos/:id,
+	// or a reparent or merge under the new parent) when
+	// ConfirmationRequired, that is, when Headcount is above the threshold.
+	ConfirmationRequired  bool      `json:"confirmation_required"`
+	ConfirmationThreshold int       `json:"confirmation_threshold"` // 0 when moves never need confirmation
+	ConfirmationToken     string    `json:"confirmation_token"`
+	ConfirmationExpiresAt time.Time `json:"confirmation_expires_at"`
+}
+
+// MovedDepartment is a department of the subtree being moved.
+type MovedDepartment struct {
+	ID                 uuid.UUID  `json:"id"`
+	Name               string     `json:"name"`
+	ManagerID          *uuid.UUID `json:"manager_id"`
+	ParentDepartmentID *uuid.UUID `json:"parent_department_id"` // After the move
+	Headcount          int64      `json:"headcount"`
+	NewDepth           int        `json:"new_depth"`
+}
+
+// ReportingChange is a manager of the moved subtree whose chain of superior
+// managers changes. The chains list manager IDs, nearest first.
+type ReportingChange struct {
+	ManagerID      uuid.UUID   `json:"manager_id"`
+	DepartmentID   uuid.UUID   `json:"department_id"`
+	DepartmentName string      `json:"department_name"`
+	Before         []uuid.UUID `json:"before"`
+	After          []uuid.UUID `json:"after"`
+}
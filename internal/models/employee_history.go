@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DepartmentAssignment is a period during which an employee belonged to a
+// department. Periods are half-open: the employee was in the department from
+// EffectiveFrom up to, but not including, EffectiveTo. The current period has
+// no EffectiveTo.
+type DepartmentAssignment struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	EmployeeID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"employee_id"`
+	DepartmentID   uuid.UUID  `gorm:"type:uuid;not null" json:"department_id"`
+	DepartmentName string     `gorm:"->;-:migration" json:"department_name"` // Loaded on read, even for removed departments
+	EffectiveFrom  time.Time  `gorm:"not null" json:"effective_from"`
+	EffectiveTo    *time.Time `json:"effective_to"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ActiveAt reports whether the employee was in this department at t.
+func (a *DepartmentAssignment) ActiveAt(t time.Time) bool {
+	return !t.Before(a.EffectiveFrom) && (a.EffectiveTo == nil || t.Before(*a.EffectiveTo))
+}
+
+// BeforeCreate is a GORM hook to generate UUID v7 before creating.
+func (a *DepartmentAssignment) BeforeCreate(tx *gorm.DB) (err error) {
+	if a.ID == uuid.Nil {
+		a.ID, err = uuid.NewV7()
+	}
+	return err
+}
+
+// TableName specifies the table name for this model
+func (DepartmentAssignment) TableName() string {
+	return "employee_department_history"
+}
@@ -0,0 +1,65 @@
+package models
+
+import "github.com/google/uuid"
+
+// MaxImportRows bounds the rows of one employee import.
+const MaxImportRows = 5000
+
+// Import modes.
+const (
+	ImportModeAtomic     = "atomic"      // All rows are written, or none
+	ImportModeBestEffort = "best_effort" // Valid rows are written, the rest reported
+)
+
+// Outcomes of an imported row.
+const (
+	ImportStatusValid   = "valid"   // Passed validation but was not written (dry run, or atomic import with invalid rows)
+	ImportStatusCreated = "created" // A new employee
+	ImportStatusRehired = "rehired" // A removed employee with the same CPF was brought back
+	ImportStatusInvalid = "invalid"
+)
+
+// EmployeeImportRow is one row of an employee import. Department is the
+// department ID or its name path from the root, as in "Empresa/TI/Suporte".
+type EmployeeImportRow struct {
+	Line       int // Line (CSV) or row (XLSX) number in the file, header included
+	Name       string
+	CPF        string
+	RG         string
+	Department string
+}
+
+// ImportOptions controls how an import is applied.
+type ImportOptions struct {
+	Mode   string
+	DryRun bool
+}
+
+// ImportReport is the outcome of an employee import, row by row.
+type ImportReport struct {
+	Mode      string             `json:"mode"`
+	DryRun    bool               `json:"dry_run"`
+	Committed bool               `json:"committed"` // Whether any row was written
+	Total     int                `json:"total"`
+	Valid     int                `json:"valid"`
+	Created   int                `json:"created"`
+	Rehired   int                `json:"rehired"`
+	Invalid   int                `json:"invalid"`
+	Rows      []*ImportRowResult `json:"rows"`
+}
+
+// ImportRowResult is the outcome of one imported row.
+type ImportRowResult struct {
+	Line       int          `json:"line"`
+	Status     string       `json:"status"`
+	EmployeeID *uuid.UUID   `json:"employee_id,omitempty"`
+	Error      *ImportError `json:"error,omitempty"`
+}
+
+// ImportError tells why a row was rejected, with the same codes as the
+// single-employee endpoints.
+type ImportError struct {
+	Code    string `json:"code"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmployeeExportRow is one employee in an export, with the names of their
+// department and of its manager resolved.
+type EmployeeExportRow struct {
+	ID             uuid.UUID `json:"id"`
+	Name           string    `json:"name"`
+	CPF            string    `json:"cpf"`
+	RG             *string   `json:"rg"`
+	DepartmentID   uuid.UUID `json:"department_id"`
+	DepartmentName string    `json:"department_name"`
+	ManagerName    *string   `json:"manager_name"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// DepartmentExportRow is one department in an export, with the names of its
+// manager and parent department resolved.
+type DepartmentExportRow struct {
+	ID                   uuid.UUID  `json:"id"`
+	Name                 string     `json:"name"`
+	ManagerID            *uuid.UUID `json:"manager_id"`
+	ManagerName          *string    `json:"manager_name"`
+	ParentDepartmentID   *uuid.UUID `json:"parent_department_id"`
+	ParentDepartmentName *string    `json:"parent_department_name"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PersonalDataExport gathers everything stored about one employee, to answer
+// LGPD data-subject access requests. New kinds of personal data (such as
+// attachments) belong here as new sections.
+type PersonalDataExport struct {
+	GeneratedAt        time.Time               `json:"generated_at"`
+	Employee           *Employee               `json:"employee"`
+	DeletedAt          *time.Time              `json:"deleted_at,omitempty"` // Set for removed employees
+	Manager            *PersonRef              `json:"manager,omitempty"`    // Current manager of the employee's department
+	DepartmentHistory  []*DepartmentAssignment `json:"department_history"`
+	ManagedDepartments []*ManagementPeriod     `json:"managed_departments"`
+	AuditEntries       []*AuditEntry           `json:"audit_entries"`
+}
+
+// PersonRef identifies another employee by ID and name.
+type PersonRef struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+// ManagementPeriod is a period in which the employee managed a department.
+// The period is half-open, [From, To); an open period has no To.
+type ManagementPeriod struct {
+	DepartmentID   uuid.UUID  `json:"department_id"`
+	DepartmentName string     `json:"department_name"`
+	From           time.Time  `json:"from"`
+	To             *time.Time `json:"to,omitempty"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import "github.com/google/uuid"
+
+// MaxReorganizationOperations bounds the operations of one reorganization plan.
+const MaxReorganizationOperations = 1000
+
+// Operations of a reorganization plan.
+const (
+	ReorganizeRename        = "rename"         // DepartmentID gets Name
+	ReorganizeReparent      = "reparent"       // DepartmentID moves under ParentDepartmentID (nil UUID: becomes a root)
+	ReorganizeChangeManager = "change_manager" // DepartmentID gets ManagerID (nil UUID: no manager)
+	ReorganizeMerge         = "merge"          // DepartmentID is merged into TargetDepartmentID and removed
+	ReorganizeMoveEmployee  = "move_employee"  // EmployeeID moves to DepartmentID
+)
+
+// ReorganizationOperation is one step of a reorganization plan. Which fields
+// apply depends on Type.
+type ReorganizationOperation struct {
+	Type               string     `json:"type" binding:"required,oneof=rename reparent change_manager merge move_employee"`
+	DepartmentID       *uuid.UUID `json:"department_id" binding:"required"`
+	Name               *string    `json:"name"`
+	ParentDepartmentID *uuid.UUID `json:"parent_department_id"`
+	ManagerID          *uuid.UUID `json:"manager_id"`
+	TargetDepartmentID *uuid.UUID `json:"target_department_id"`
+	EmployeeID         *uuid.UUID `json:"employee_id"`
+
+	// ConfirmationToken confirms a reparent or merge of a subtree with more
+	// employees than the move confirmation threshold. It comes from the move
+	// preview under ParentDepartmentID, or under TargetDepartmentID.
+	ConfirmationToken string `json:"confirmation_token"`
+}
+
+// ReorganizationReport is the outcome of a reorganization plan: the problems
+// that keep it from being applied, or the changes it makes (or would make,
+// in a dry run).
+type ReorganizationReport struct {
+	DryRun   bool                     `json:"dry_run"`
+	Valid    bool                     `json:"valid"`
+	Applied  bool                     `json:"applied"`
+	Problems []*ReorganizationProblem `json:"problems"`
+	Changes  []*ReorganizationChange  `json:"changes"`
+}
+
+// ReorganizationProblem is a reason a plan cannot be applied, with the same
+// codes as the single-department endpoints.
+type ReorganizationProblem struct {
+	Operation *int       `json:"operation,omitempty"` // Index of the operation; absent for checks of the resulting tree
+	EntityID  *uuid.UUID `json:"entity_id,omitempty"` // Department or employee at fault
+	Code      string     `json:"code"`
+	Field     string     `json:"field,omitempty"`
+	Message   string     `json:"message"`
+}
+
+// ReorganizationChange is what a plan changes in one employee or department,
+// as it is recorded in the audit log.
+type ReorganizationChange struct {
+	Entity   string       `json:"entity"` // AuditEntityEmployee or AuditEntityDepartment
+	EntityID uuid.UUID    `json:"entity_id"`
+	Name     string       `json:"name"`
+	Action   string       `json:"action"` // AuditActionUpdate, or AuditActionDelete for merged departments
+	Changes  AuditChanges `json:"changes"`
+}
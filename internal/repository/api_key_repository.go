@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	Create(key *models.APIKey) error
+	FindByID(id uuid.UUID) (*models.APIKey, error)
+	FindByHash(hash string) (*models.APIKey, error)
+	List() ([]*models.APIKey, error)
+	Revoke(id uuid.UUID, at time.Time) error
+	TouchLastUsed(id uuid.UUID, at time.Time) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(key *models.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *apiKeyRepository) FindByID(id uuid.UUID) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.First(&key, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) FindByHash(hash string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.First(&key, "key_hash = ?", hash).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// List returns every key, revoked ones included, newest first.
+func (r *apiKeyRepository) List() ([]*models.APIKey, error) {
+	var keys []*models.APIKey
+	err := r.db.Order("id DESC").Find(&keys).Error
+	return keys, err
+}
+
+// Revoke marks the key as revoked. Revoking twice keeps the first date.
+func (r *apiKeyRepository) Revoke(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error
+}
+
+// TouchLastUsed records a use of the key without bumping updated_at.
+func (r *apiKeyRepository) TouchLastUsed(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&models.APIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", at).Error
+}
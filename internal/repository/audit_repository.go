@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuditRepository interface {
+	WithTx(tx *gorm.DB) AuditRepository
+	Create(entry *models.AuditEntry) error
+	List(filter models.AuditFilter, page, pageSize int) ([]*models.AuditEntry, int64, error)
+	ListByEntity(entityID uuid.UUID) ([]*models.AuditEntry, error)
+	UpdateChanges(entry *models.AuditEntry) error
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+// WithTx binds the repository to tx, so entries commit or roll back together
+// with the mutation they describe.
+func (r *auditRepository) WithTx(tx *gorm.DB) AuditRepository {
+	return &auditRepository{db: tx}
+}
+
+func (r *auditRepository) Create(entry *models.AuditEntry) error {
+	return r.db.Create(entry).Error
+}
+
+// List returns one page of matching entries, newest first.
+func (r *auditRepository) List(filter models.AuditFilter, page, pageSize int) ([]*models.AuditEntry, int64, error) {
+	query := r.db.Model(&models.AuditEntry{})
+	if filter.EntityID != nil {
+		query = query.Where("entity_id = ?", *filter.EntityID)
+	}
+	if filter.Actor != nil {
+		query = query.Where("actor = ?", *filter.Actor)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []*models.AuditEntry
+	err := query.Order("created_at DESC").Order("id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&entries).Error
+	return entries, total, err
+}
+
+// ListByEntity returns every entry about the entity, oldest first.
+func (r *auditRepository) ListByEntity(entityID uuid.UUID) ([]*models.AuditEntry, error) {
+	var entries []*models.AuditEntry
+	err := r.db.Where("entity_id = ?", entityID).Order("created_at, id").Find(&entries).Error
+	return entries, err
+}
+
+// UpdateChanges rewrites the changes of an entry. Entries are otherwise
+// immutable; this exists only to erase personal data from them.
+func (r *auditRepository) UpdateChanges(entry *models.AuditEntry) error {
+	return r.db.Model(entry).Update("changes", entry.Changes).Error
+}
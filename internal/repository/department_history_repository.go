@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DepartmentHistoryRepository interface {
+	WithTx(tx *gorm.DB) DepartmentHistoryRepository
+	Create(version *models.DepartmentVersion) error
+	CloseCurrent(deptID uuid.UUID, at time.Time) error
+	FindAt(id uuid.UUID, at time.Time) (*models.Department, error)
+	FindDescendantsAt(id uuid.UUID, at time.Time, maxDepth int) ([]*models.Department, error)
+	ListByManager(managerID uuid.UUID) ([]*models.DepartmentVersion, error)
+}
+
+// validAt restricts department_history rows (aliased as h) to the versions in
+// effect at a given time, which is bound twice.
+const validAt = "h.valid_from <= ? AND (h.valid_to IS NULL OR h.valid_to > ?)"
+
+// descendantsAtCTE is descendantsCTE over the versions in effect at a given
+// time. Arguments: parent, time (twice), time (twice), depth.
+const descendantsAtCTE = `WITH RECURSIVE tree(id, depth) AS (
+	SELECT h.department_id, 1 FROM department_history h
+	WHERE h.parent_department_id = ? AND ` + validAt + `
+	UNION ALL
+	SELECT h.department_id, t.depth + 1 FROM department_history h
+	INNER JOIN tree t ON h.parent_department_id = t.id
+	WHERE ` + validAt + ` AND t.depth < ?
+)
+SELECT id FROM tree`
+
+type departmentHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewDepartmentHistoryRepository(db *gorm.DB) DepartmentHistoryRepository {
+	return &departmentHistoryRepository{db: db}
+}
+
+func (r *departmentHistoryRepository) WithTx(tx *gorm.DB) DepartmentHistoryRepository {
+	return &departmentHistoryRepository{db: tx}
+}
+
+func (r *departmentHistoryRepository) Create(version *models.DepartmentVersion) error {
+	return r.db.Create(version).Error
+}
+
+// CloseCurrent ends the department's current version at the given time. It
+// does nothing when there is none.
+func (r *departmentHistoryRepository) CloseCurrent(deptID uuid.UUID, at time.Time) error {
+	return r.db.Model(&models.DepartmentVersion{}).
+		Where("department_id = ? AND valid_to IS NULL", deptID).
+		Update("valid_to", at).Error
+}
+
+// FindAt returns the department as it was at the given time, with its
+// manager at that time.
+func (r *departmentHistoryRepository) FindAt(id uuid.UUID, at time.Time) (*models.Department, error) {
+	var version models.DepartmentVersion
+	err := r.db.Table("department_history h").
+		Where("h.department_id = ?", id).
+		Where(validAt, at, at).
+		Take(&version).Error
+	if err != nil {
+		return nil, err
+	}
+
+	depts := []*models.Department{version.Department()}
+	if err := r.attachManagers(depts); err != nil {
+		return nil, err
+	}
+	return depts[0], nil
+}
+
+// FindDescendantsAt returns every department below id as they were at the
+// given time, up to maxDepth levels (0 means the whole subtree), with their
+// managers at that time.
+func (r *departmentHistoryRepository) FindDescendantsAt(id uuid.UUID, at time.Time, maxDepth int) ([]*models.Department, error) {
+	if maxDepth <= 0 || maxDepth > maxHierarchyDepth {
+		maxDepth = maxHierarchyDepth
+	}
+
+	var versions []*models.DepartmentVersion
+	err := r.db.Table("department_history h").
+		Where("h.department_id IN (?)", gorm.Expr(descendantsAtCTE, id, at, at, at, at, maxDepth)).
+		Where(validAt, at, at).
+		Order("h.department_id").
+		Find(&versions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	depts := make([]*models.Department, len(versions))
+	for i, v := range versions {
+		depts[i] = v.Department()
+	}
+	if err := r.attachManagers(depts); err != nil {
+		return nil, err
+	}
+	return depts, nil
+}
+
+// attachManagers loads the managers of depts, including employees removed
+// since then. Only their ID and name are loaded, and the departments are set
+// to serialize nothing else: the tree of a past date is open to any reader,
+// and must not expose personal data.
+func (r *departmentHistoryRepository) attachManagers(depts []*models.Department) error {
+	var ids []uuid.UUID
+	for _, d := range depts {
+		if d.ManagerID != nil {
+			ids = append(ids, *d.ManagerID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var managers []*models.Employee
+	if err := r.db.Unscoped().Select("id", "name").Where("id IN ?", ids).Find(&managers).Error; err != nil {
+		return err
+	}
+	byID := make(map[uuid.UUID]*models.Employee, len(managers))
+	for _, m := range managers {
+		byID[m.ID] = m
+	}
+	for _, d := range depts {
+		if d.ManagerID != nil {
+			d.Manager = byID[*d.ManagerID]
+			d.RedactManager()
+		}
+	}
+	return nil
+}
+
+// ListByManager returns the versions of every department in which the
+// employee was the manager, ordered by department and time.
+func (r *departmentHistoryRepository) ListByManager(managerID uuid.UUID) ([]*models.DepartmentVersion, error) {
+	var versions []*models.DepartmentVersion
+	err := r.db.Where("manager_id = ?", managerID).Order("department_id, valid_from").Find(&versions).Error
+	return versions, err
+}
@@ -5,13 +5,22 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type DepartmentRepository interface {
+	Transaction(fn func(tx *gorm.DB) error) error
+	WithTx(tx *gorm.DB) DepartmentRepository
 	Create(dept *models.Department) error
 	FindByID(id uuid.UUID) (*models.Department, error)
+	FindAll() ([]*models.Department, error)
+	FindAllForUpdate() ([]*models.Department, error)
+	FindByIDForUpdate(id uuid.UUID) (*models.Department, error)
+	FindByIDForShare(id uuid.UUID) (*models.Department, error)
+	LockAncestors(id uuid.UUID) error
 	FindByIDWithManager(id uuid.UUID) (*models.Department, error)
 	FindSubDepartments(parentID uuid.UUID) ([]*models.Department, error)
+	FindDescendants(id uuid.UUID, maxDepth int) ([]*models.Department, error)
 	Update(dept *models.Department) error
 	Delete(id uuid.UUID) error
 	CountSubDepartments(id uuid.UUID) (int64, error)
@@ -19,9 +28,51 @@ type DepartmentRepository interface {
 	FindByManagerID(managerID uuid.UUID) ([]*models.Department, error)
 	IsSubordinate(parentID, subordinateID uuid.UUID) (bool, error)
 	FindAllSubordinateIDs(id uuid.UUID) ([]uuid.UUID, error)
-	List(name, managerName *string, parentID *uuid.UUID, page, pageSize int) ([]*models.Department, error)
+	List(name, managerName *string, parentID *uuid.UUID, sort models.Sort, page, pageSize int) ([]*models.Department, int64, error)
+	FindByIDWithDeletedForUpdate(id uuid.UUID) (*models.Department, error)
+	ListDeleted(page, pageSize int) ([]*models.Department, int64, error)
+	Export(filter models.DepartmentFilter, sort models.Sort, fn func(*models.DepartmentExportRow) error) error
+	Restore(id uuid.UUID) error
 }
 
+// maxHierarchyDepth bounds every recursive walk of the department tree, so a
+// corrupted (cyclic) hierarchy can never make a query run forever.
+const maxHierarchyDepth = 100
+
+// descendantsCTE selects the IDs of every active department below the given
+// parent, down to the given depth (direct children are depth 1).
+const descendantsCTE = `WITH RECURSIVE tree(id, depth) AS (
+	SELECT id, 1 FROM departments WHERE parent_department_id = ? AND deleted_at IS NULL
+	UNION ALL
+	SELECT d.id, t.depth + 1 FROM departments d
+	INNER JOIN tree t ON d.parent_department_id = t.id
+	WHERE d.deleted_at IS NULL AND t.depth < ?
+)
+SELECT id FROM tree`
+
+// subtreeCTE selects the ID of a department and of every active department
+// below it. UNION discards rows already visited, which stops the walk on a
+// cyclic hierarchy.
+const subtreeCTE = `WITH RECURSIVE tree(id) AS (
+	SELECT id FROM departments WHERE id = ? AND deleted_at IS NULL
+	UNION
+	SELECT d.id FROM departments d
+	INNER JOIN tree t ON d.parent_department_id = t.id
+	WHERE d.deleted_at IS NULL
+)
+SELECT id FROM tree`
+
+// ancestorsCTE selects the ID of a department and of every department above
+// it, up to the root.
+const ancestorsCTE = `WITH RECURSIVE chain(id, parent_id) AS (
+	SELECT id, parent_department_id FROM departments WHERE id = ? AND deleted_at IS NULL
+	UNION
+	SELECT d.id, d.parent_department_id FROM departments d
+	INNER JOIN chain c ON d.id = c.parent_id
+	WHERE d.deleted_at IS NULL
+)
+SELECT id FROM chain`
+
 type departmentRepository struct {
 	db *gorm.DB
 }
@@ -30,6 +81,16 @@ func NewDepartmentRepository(db *gorm.DB) DepartmentRepository {
 	return &departmentRepository{db: db}
 }
 
+// Transaction runs fn inside a database transaction. Repositories bound to
+// the transaction are obtained with WithTx.
+func (r *departmentRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
+func (r *departmentRepository) WithTx(tx *gorm.DB) DepartmentRepository {
+	return &departmentRepository{db: tx}
+}
+
 func (r *departmentRepository) Create(dept *models.Department) error {
 	return r.db.Create(dept).Error
 }
@@ -42,6 +103,56 @@ func (r *departmentRepository) FindByID(id uuid.UUID) (*models.Department, error
 	return &dept, nil
 }
 
+// FindAll returns every active department, in the order of
+// FindAllForUpdate.
+func (r *departmentRepository) FindAll() ([]*models.Department, error) {
+	var depts []*models.Department
+	err := r.db.Order("id").Find(&depts).Error
+	return depts, err
+}
+
+// FindAllForUpdate returns every active department, locking their rows
+// until the surrounding transaction ends.
+func (r *departmentRepository) FindAllForUpdate() ([]*models.Department, error) {
+	var depts []*models.Department
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Order("id").Find(&depts).Error
+	return depts, err
+}
+
+// FindByIDForShare loads the department and keeps its row from being
+// updated or removed until the surrounding transaction ends. Hires and
+// transfers take it, so a department cannot be removed or merged away while
+// an employee is joining it.
+func (r *departmentRepository) FindByIDForShare(id uuid.UUID) (*models.Department, error) {
+	var dept models.Department
+	if err := r.db.Clauses(clause.Locking{Strength: "SHARE"}).First(&dept, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &dept, nil
+}
+
+// FindByIDForUpdate loads the department and locks its row until the
+// surrounding transaction ends.
+func (r *departmentRepository) FindByIDForUpdate(id uuid.UUID) (*models.Department, error) {
+	var dept models.Department
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&dept, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &dept, nil
+}
+
+// LockAncestors locks the rows of the department and of every department
+// above it until the surrounding transaction ends. Moves that could close a
+// cycle through this branch are serialized behind the lock.
+func (r *departmentRepository) LockAncestors(id uuid.UUID) error {
+	var ids []uuid.UUID
+	return r.db.Model(&models.Department{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN (?)", gorm.Expr(ancestorsCTE, id)).
+		Order("id").
+		Pluck("id", &ids).Error
+}
+
 func (r *departmentRepository) FindByIDWithManager(id uuid.UUID) (*models.Department, error) {
 	var dept models.Department
 	if err := r.db.Preload("Manager").First(&dept, "id = ?", id).Error; err != nil {
@@ -56,6 +167,22 @@ func (r *departmentRepository) FindSubDepartments(parentID uuid.UUID) ([]*models
 	return departments, err
 }
 
+// FindDescendants returns every department below id, up to maxDepth levels
+// (0 means the whole subtree), with their managers preloaded. The subtree is
+// resolved in a single recursive query regardless of its size.
+func (r *departmentRepository) FindDescendants(id uuid.UUID, maxDepth int) ([]*models.Department, error) {
+	if maxDepth <= 0 || maxDepth > maxHierarchyDepth {
+		maxDepth = maxHierarchyDepth
+	}
+
+	var departments []*models.Department
+	err := r.db.Preload("Manager").
+		Where("id IN (?)", gorm.Expr(descendantsCTE, id, maxDepth)).
+		Order("id").
+		Find(&departments).Error
+	return departments, err
+}
+
 func (r *departmentRepository) Update(dept *models.Department) error {
 	return r.db.Save(dept).Error
 }
@@ -82,28 +209,131 @@ func (r *departmentRepository) FindByManagerID(managerID uuid.UUID) ([]*models.D
 	return departments, err
 }
 
+// IsSubordinate reports whether subordinateID sits anywhere below parentID
+// in the hierarchy. A department is not its own subordinate.
 func (r *departmentRepository) IsSubordinate(parentID, subordinateID uuid.UUID) (bool, error) {
-	// Simplified implementation for now
-	return false, nil
+	var count int64
+	err := r.db.Model(&models.Department{}).
+		Where("id = ?", subordinateID).
+		Where("id IN (?)", gorm.Expr(descendantsCTE, parentID, maxHierarchyDepth)).
+		Count(&count).Error
+	return count > 0, err
 }
 
+// FindAllSubordinateIDs returns the ID of the department itself plus the IDs
+// of every department below it, at any depth.
 func (r *departmentRepository) FindAllSubordinateIDs(id uuid.UUID) ([]uuid.UUID, error) {
-	// Simplified implementation for now
-	return []uuid.UUID{}, nil
+	var ids []uuid.UUID
+	if err := r.db.Raw(subtreeCTE, id).Scan(&ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
 }
 
-func (r *departmentRepository) List(name, managerName *string, parentID *uuid.UUID, page, pageSize int) ([]*models.Department, error) {
-	var departments []*models.Department
+// List returns one page of the departments matching the filters, in the
+// requested order, along with the total number of matches. managerName
+// matches the manager's name case-insensitively and partially; departments
+// without a manager never match it. The manager is preloaded on every
+// returned department.
+func (r *departmentRepository) List(name, managerName *string, parentID *uuid.UUID, sort models.Sort, page, pageSize int) ([]*models.Department, int64, error) {
+	if err := checkSort(sort, departmentSortColumns); err != nil {
+		return nil, 0, err
+	}
 	query := r.db.Model(&models.Department{})
 
 	if name != nil {
-		query = query.Where("name ILIKE ?", "%"+*name+"%")
+		query = query.Where("LOWER(departments.name) LIKE LOWER(?)", "%"+*name+"%")
+	}
+	if managerName != nil {
+		query = query.
+			Joins("JOIN employees ON employees.id = departments.manager_id AND employees.deleted_at IS NULL").
+			Where("LOWER(employees.name) LIKE LOWER(?)", "%"+*managerName+"%")
 	}
 	if parentID != nil {
-		query = query.Where("parent_department_id = ?", *parentID)
+		query = query.Where("departments.parent_department_id = ?", *parentID)
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if sort.Field == "manager" && managerName == nil {
+		query = query.Joins("LEFT JOIN employees ON employees.id = departments.manager_id AND employees.deleted_at IS NULL")
+	}
+	query, err := orderBy(query, sort, departmentSortColumns, "departments.id")
+	if err != nil {
+		return nil, 0, err
 	}
 
+	var departments []*models.Department
 	offset := (page - 1) * pageSize
-	err := query.Limit(pageSize).Offset(offset).Find(&departments).Error
-	return departments, err
+	err = query.Preload("Manager").Limit(pageSize).Offset(offset).Find(&departments).Error
+	return departments, total, err
+}
+
+// Export calls fn with every department matching the filters of List, in the
+// given sort, along with the names of its manager and parent department. As
+// with the employee export, rows are streamed and the row passed to fn is
+// reused.
+func (r *departmentRepository) Export(filter models.DepartmentFilter, sort models.Sort, fn func(*models.DepartmentExportRow) error) error {
+	query := r.db.Model(&models.Department{}).
+		Select("departments.id, departments.name, departments.manager_id, employees.name AS manager_name, " +
+			"departments.parent_department_id, parents.name AS parent_department_name, departments.created_at, departments.updated_at").
+		Joins("LEFT JOIN employees ON employees.id = departments.manager_id AND employees.deleted_at IS NULL").
+		Joins("LEFT JOIN departments AS parents ON parents.id = departments.parent_department_id")
+
+	if filter.Name != nil {
+		query = query.Where("LOWER(departments.name) LIKE LOWER(?)", "%"+*filter.Name+"%")
+	}
+	if filter.ManagerName != nil {
+		query = query.Where("LOWER(employees.name) LIKE LOWER(?)", "%"+*filter.ManagerName+"%")
+	}
+	if filter.ParentDepartmentID != nil {
+		query = query.Where("departments.parent_department_id = ?", *filter.ParentDepartmentID)
+	}
+	if filter.DepartmentIDs != nil {
+		query = query.Where("departments.id IN ?", filter.DepartmentIDs)
+	}
+
+	query, err := orderBy(query, sort, departmentSortColumns, "departments.id")
+	if err != nil {
+		return err
+	}
+	return eachRow(query, fn)
+}
+
+// FindByIDWithDeletedForUpdate loads the department even when removed,
+// locking its row until the transaction ends.
+func (r *departmentRepository) FindByIDWithDeletedForUpdate(id uuid.UUID) (*models.Department, error) {
+	var dept models.Department
+	if err := r.db.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&dept, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &dept, nil
+}
+
+// ListDeleted returns one page of the removed departments, most recently
+// removed first, along with their total.
+func (r *departmentRepository) ListDeleted(page, pageSize int) ([]*models.Department, int64, error) {
+	query := r.db.Unscoped().Model(&models.Department{}).Where("deleted_at IS NOT NULL").Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var departments []*models.Department
+	offset := (page - 1) * pageSize
+	err := query.Order("deleted_at DESC").Order("id").Limit(pageSize).Offset(offset).Find(&departments).Error
+	return departments, total, err
+}
+
+// Restore undoes the soft delete of the department. Its manager is cleared:
+// a department can only be removed once empty, so whoever managed it no
+// longer belongs to it.
+func (r *departmentRepository) Restore(id uuid.UUID) error {
+	return r.db.Unscoped().Model(&models.Department{}).Where("id = ?", id).
+		Updates(map[string]any{"deleted_at": nil, "manager_id": nil}).Error
 }
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type EmployeeHistoryRepository interface {
+	WithTx(tx *gorm.DB) EmployeeHistoryRepository
+	Create(assignment *models.DepartmentAssignment) error
+	CloseCurrent(employeeID uuid.UUID, at time.Time) error
+	ListByEmployee(employeeID uuid.UUID) ([]*models.DepartmentAssignment, error)
+}
+
+type employeeHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewEmployeeHistoryRepository(db *gorm.DB) EmployeeHistoryRepository {
+	return &employeeHistoryRepository{db: db}
+}
+
+func (r *employeeHistoryRepository) WithTx(tx *gorm.DB) EmployeeHistoryRepository {
+	return &employeeHistoryRepository{db: tx}
+}
+
+func (r *employeeHistoryRepository) Create(assignment *models.DepartmentAssignment) error {
+	return r.db.Create(assignment).Error
+}
+
+// CloseCurrent ends the employee's current assignment at the given time. It
+// does nothing when there is none.
+func (r *employeeHistoryRepository) CloseCurrent(employeeID uuid.UUID, at time.Time) error {
+	return r.db.Model(&models.DepartmentAssignment{}).
+		Where("employee_id = ? AND effective_to IS NULL", employeeID).
+		Update("effective_to", at).Error
+}
+
+// ListByEmployee returns every assignment of the employee, oldest first, with
+// the department names (of removed departments too).
+func (r *employeeHistoryRepository) ListByEmployee(employeeID uuid.UUID) ([]*models.DepartmentAssignment, error) {
+	var history []*models.DepartmentAssignment
+	err := r.db.Model(&models.DepartmentAssignment{}).
+		Select("employee_department_history.*, departments.name AS department_name").
+		Joins("LEFT JOIN departments ON departments.id = employee_department_history.department_id").
+		Where("employee_department_history.employee_id = ?", employeeID).
+		Order("employee_department_history.effective_from").
+		Order("employee_department_history.id").
+		Find(&history).Error
+	return history, err
+}
@@ -2,24 +2,43 @@ package repository
 
 import (
 	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/utils"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Public interface for the employee repository
 type EmployeeRepository interface {
+	WithTx(tx *gorm.DB) EmployeeRepository
 	Create(employee *models.Employee) error
 	FindByID(id uuid.UUID) (*models.Employee, error)
+	FindByIDForUpdate(id uuid.UUID) (*models.Employee, error)
 	FindAll() ([]models.Employee, error)
 	Update(employee *models.Employee) error
 	Delete(id uuid.UUID) error
 	CountByDepartmentID(deptID uuid.UUID) (int64, error)
+	CountByDepartmentIDs(deptIDs []uuid.UUID) (map[uuid.UUID]int64, error)
 	FindByDepartmentIDs(deptIDs []uuid.UUID) ([]*models.Employee, error)
-	List(name, cpf, rg *string, deptID *uuid.UUID, page, pageSize int) ([]*models.Employee, error)
+	FindByDepartmentIDsForUpdate(deptIDs []uuid.UUID) ([]*models.Employee, error)
+	ListByDepartmentIDs(deptIDs, excludeIDs []uuid.UUID, page, pageSize int) ([]*models.Employee, int64, error)
+	List(filter models.EmployeeFilter, sort models.Sort, page, pageSize int) ([]*models.Employee, int64, error)
+	ListByCursor(filter models.EmployeeFilter, sort models.Sort, cursor *models.Cursor, backward bool, limit int) ([]*models.Employee, []models.Cursor, error)
+	Export(filter models.EmployeeFilter, sort models.Sort, fn func(*models.EmployeeExportRow) error) error
 	IsCPFDuplicated(err error) bool
 	IsRGDuplicated(err error) bool
+	FindByIDWithDeleted(id uuid.UUID) (*models.Employee, error)
+	FindByIDWithDeletedForUpdate(id uuid.UUID) (*models.Employee, error)
+	ListDeleted(page, pageSize int) ([]*models.Employee, int64, error)
+	Restore(id uuid.UUID) error
+	Rehire(employee *models.Employee) error
+	IsCPFInUse(cpf string) (bool, error)
+	IsRGInUse(rg string) (bool, error)
+	FindDeletedByCPFForUpdate(cpf string) (*models.Employee, error)
+	Anonymize(employee *models.Employee) error
 }
 
 type employeeRepository struct {
@@ -30,6 +49,10 @@ func NewEmployeeRepository(db *gorm.DB) EmployeeRepository {
 	return &employeeRepository{db: db}
 }
 
+func (r *employeeRepository) WithTx(tx *gorm.DB) EmployeeRepository {
+	return &employeeRepository{db: tx}
+}
+
 func (r *employeeRepository) Create(employee *models.Employee) error {
 	return r.db.Create(employee).Error
 }
@@ -42,6 +65,16 @@ func (r *employeeRepository) FindByID(id uuid.UUID) (*models.Employee, error) {
 	return &employee, nil
 }
 
+// FindByIDForUpdate loads the employee and locks its row until the
+// surrounding transaction ends.
+func (r *employeeRepository) FindByIDForUpdate(id uuid.UUID) (*models.Employee, error) {
+	var employee models.Employee
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&employee, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &employee, nil
+}
+
 func (r *employeeRepository) FindAll() ([]models.Employee, error) {
 	var employees []models.Employee
 	if err := r.db.Find(&employees).Error; err != nil {
@@ -64,32 +97,231 @@ func (r *employeeRepository) CountByDepartmentID(deptID uuid.UUID) (int64, error
 	return count, err
 }
 
+// CountByDepartmentIDs returns the number of active employees of each of the
+// given departments; departments without employees are left out.
+func (r *employeeRepository) CountByDepartmentIDs(deptIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+	var rows []struct {
+		DepartmentID uuid.UUID
+		Count        int64
+	}
+	err := r.db.Model(&models.Employee{}).
+		Select("department_id, COUNT(*) AS count").
+		Where("department_id IN ?", deptIDs).
+		Group("department_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uuid.UUID]int64, len(rows))
+	for _, row := range rows {
+		counts[row.DepartmentID] = row.Count
+	}
+	return counts, nil
+}
+
 func (r *employeeRepository) FindByDepartmentIDs(deptIDs []uuid.UUID) ([]*models.Employee, error) {
 	var employees []*models.Employee
 	err := r.db.Where("department_id IN ?", deptIDs).Find(&employees).Error
 	return employees, err
 }
 
-func (r *employeeRepository) List(name, cpf, rg *string, deptID *uuid.UUID, page, pageSize int) ([]*models.Employee, error) {
+// FindByDepartmentIDsForUpdate returns the employees of the given
+// departments, locking their rows until the surrounding transaction ends.
+func (r *employeeRepository) FindByDepartmentIDsForUpdate(deptIDs []uuid.UUID) ([]*models.Employee, error) {
 	var employees []*models.Employee
-	query := r.db.Model(&models.Employee{})
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("department_id IN ?", deptIDs).Order("id").Find(&employees).Error
+	return employees, err
+}
 
-	if name != nil {
-		query = query.Where("name ILIKE ?", "%"+*name+"%")
+// ListByDepartmentIDs returns one page of the employees of the given
+// departments, leaving out excludeIDs, together with the total match count.
+func (r *employeeRepository) ListByDepartmentIDs(deptIDs, excludeIDs []uuid.UUID, page, pageSize int) ([]*models.Employee, int64, error) {
+	query := r.db.Model(&models.Employee{}).Where("department_id IN ?", deptIDs)
+	if len(excludeIDs) > 0 {
+		query = query.Where("id NOT IN ?", excludeIDs)
 	}
-	if cpf != nil {
-		query = query.Where("cpf = ?", *cpf)
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
 	}
-	if rg != nil {
-		query = query.Where("rg = ?", *rg)
+
+	var employees []*models.Employee
+	offset := (page - 1) * pageSize
+	err := query.Order("name").Order("id").Limit(pageSize).Offset(offset).Find(&employees).Error
+	return employees, total, err
+}
+
+// List returns one page of the employees matching the filters, in the
+// requested order, along with the total number of matches.
+func (r *employeeRepository) List(filter models.EmployeeFilter, sort models.Sort, page, pageSize int) ([]*models.Employee, int64, error) {
+	if err := checkSort(sort, employeeSortColumns); err != nil {
+		return nil, 0, err
 	}
-	if deptID != nil {
-		query = query.Where("department_id = ?", *deptID)
+	query := r.filtered(filter).Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
 	}
 
+	if sort.Field == "department" {
+		query = query.Joins("LEFT JOIN departments ON departments.id = employees.department_id")
+	}
+	query, err := orderBy(query, sort, employeeSortColumns, "employees.id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var employees []*models.Employee
 	offset := (page - 1) * pageSize
-	err := query.Limit(pageSize).Offset(offset).Find(&employees).Error
-	return employees, err
+	err = query.Limit(pageSize).Offset(offset).Find(&employees).Error
+	return employees, total, err
+}
+
+// ListByCursor returns up to limit employees matching the filters that come
+// strictly after cursor in the given sort, or strictly before it when
+// backward is set (then in reverse order, nearest first). A nil cursor starts
+// from the first or last row. Each employee is returned with its own cursor.
+func (r *employeeRepository) ListByCursor(filter models.EmployeeFilter, sort models.Sort, cursor *models.Cursor, backward bool, limit int) ([]*models.Employee, []models.Cursor, error) {
+	if err := checkSort(sort, employeeSortColumns); err != nil {
+		return nil, nil, err
+	}
+	var key any
+	if cursor != nil && sort.Field != "" {
+		var err error
+		if key, err = employeeCursorValue(sort.Field, cursor.Key); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	query := r.filtered(filter)
+	if sort.Field == "department" {
+		query = query.Joins("LEFT JOIN departments ON departments.id = employees.department_id")
+	}
+
+	// Walking backward is walking forward in the opposite direction
+	scan := models.Sort{Field: sort.Field, Desc: sort.Desc != backward}
+	query, err := orderBy(query, scan, employeeSortColumns, "employees.id")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if cursor != nil {
+		op := ">"
+		if scan.Desc {
+			op = "<"
+		}
+		if sort.Field == "" {
+			query = query.Where("employees.id "+op+" ?", cursor.ID)
+		} else {
+			column := employeeSortColumns[sort.Field]
+			query = query.Where("("+column+" "+op+" ? OR ("+column+" = ? AND employees.id "+op+" ?))", key, key, cursor.ID)
+		}
+	}
+
+	var employees []*models.Employee
+	if err := query.Limit(limit).Find(&employees).Error; err != nil {
+		return nil, nil, err
+	}
+
+	cursors, err := r.cursorsFor(employees, sort)
+	return employees, cursors, err
+}
+
+// Export calls fn with every employee matching the filters, in the given
+// sort, along with the names of their department and its manager. Rows are
+// read one at a time from the database and the row passed to fn is reused,
+// so memory does not grow with the number of employees. An error from fn
+// stops the export and is returned.
+func (r *employeeRepository) Export(filter models.EmployeeFilter, sort models.Sort, fn func(*models.EmployeeExportRow) error) error {
+	query := r.filtered(filter).
+		Select("employees.id, employees.name, employees.cpf, employees.rg, employees.department_id, " +
+			"departments.name AS department_name, managers.name AS manager_name, employees.created_at, employees.updated_at").
+		Joins("LEFT JOIN departments ON departments.id = employees.department_id").
+		Joins("LEFT JOIN employees AS managers ON managers.id = departments.manager_id AND managers.deleted_at IS NULL")
+	query, err := orderBy(query, sort, employeeSortColumns, "employees.id")
+	if err != nil {
+		return err
+	}
+	return eachRow(query, fn)
+}
+
+// filtered applies the listing filters shared by List and ListByCursor.
+func (r *employeeRepository) filtered(filter models.EmployeeFilter) *gorm.DB {
+	query := r.db.Model(&models.Employee{})
+
+	if filter.Name != nil {
+		query = query.Where("LOWER(employees.name) LIKE LOWER(?)", "%"+*filter.Name+"%")
+	}
+	if filter.CPF != nil {
+		query = query.Where("employees.cpf = ?", *filter.CPF)
+	}
+	if filter.RG != nil {
+		query = query.Where("employees.rg = ?", *filter.RG)
+	}
+	if filter.DepartmentID != nil {
+		query = query.Where("employees.department_id = ?", *filter.DepartmentID)
+	}
+	if filter.DepartmentIDs != nil {
+		query = query.Where("employees.department_id IN ?", filter.DepartmentIDs)
+	}
+	return query
+}
+
+// cursorsFor builds the cursor of every employee for the given sort.
+func (r *employeeRepository) cursorsFor(employees []*models.Employee, sort models.Sort) ([]models.Cursor, error) {
+	var deptNames map[uuid.UUID]string
+	if sort.Field == "department" && len(employees) > 0 {
+		deptIDs := make([]uuid.UUID, 0, len(employees))
+		for _, e := range employees {
+			deptIDs = append(deptIDs, e.DepartmentID)
+		}
+		var depts []models.Department
+		// Unscoped to match the LEFT JOIN, which does not skip removed departments
+		if err := r.db.Unscoped().Select("id", "name").Where("id IN ?", deptIDs).Find(&depts).Error; err != nil {
+			return nil, err
+		}
+		deptNames = make(map[uuid.UUID]string, len(depts))
+		for _, d := range depts {
+			deptNames[d.ID] = d.Name
+		}
+	}
+
+	cursors := make([]models.Cursor, 0, len(employees))
+	for _, e := range employees {
+		c := models.Cursor{Sort: sort.Field, Desc: sort.Desc, ID: e.ID}
+		switch sort.Field {
+		case "name":
+			c.Key = e.Name
+		case "cpf":
+			c.Key = e.CPF
+		case "created_at":
+			c.Key = e.CreatedAt.Format(time.RFC3339Nano)
+		case "updated_at":
+			c.Key = e.UpdatedAt.Format(time.RFC3339Nano)
+		case "department":
+			c.Key = deptNames[e.DepartmentID]
+		}
+		cursors = append(cursors, c)
+	}
+	return cursors, nil
+}
+
+// employeeCursorValue converts a cursor key back to the type of its column.
+func employeeCursorValue(field, key string) (any, error) {
+	switch field {
+	case "created_at", "updated_at":
+		t, err := time.Parse(time.RFC3339Nano, key)
+		if err != nil {
+			return nil, utils.ErrInvalidCursor
+		}
+		return t, nil
+	default:
+		return key, nil
+	}
 }
 
 func (r *employeeRepository) IsCPFDuplicated(err error) bool {
@@ -105,3 +337,92 @@ func (r *employeeRepository) IsRGDuplicated(err error) bool {
 	}
 	return strings.Contains(err.Error(), "uq_rg") || strings.Contains(err.Error(), "employees_rg_key")
 }
+
+// FindByIDWithDeleted loads the employee even when removed.
+func (r *employeeRepository) FindByIDWithDeleted(id uuid.UUID) (*models.Employee, error) {
+	var employee models.Employee
+	if err := r.db.Unscoped().First(&employee, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &employee, nil
+}
+
+// FindByIDWithDeletedForUpdate loads the employee even when removed, locking
+// its row until the transaction ends.
+func (r *employeeRepository) FindByIDWithDeletedForUpdate(id uuid.UUID) (*models.Employee, error) {
+	var employee models.Employee
+	if err := r.db.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&employee, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &employee, nil
+}
+
+// ListDeleted returns one page of the removed employees, most recently
+// removed first, along with their total.
+func (r *employeeRepository) ListDeleted(page, pageSize int) ([]*models.Employee, int64, error) {
+	query := r.db.Unscoped().Model(&models.Employee{}).Where("deleted_at IS NOT NULL").Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var employees []*models.Employee
+	offset := (page - 1) * pageSize
+	err := query.Order("deleted_at DESC").Order("id").Limit(pageSize).Offset(offset).Find(&employees).Error
+	return employees, total, err
+}
+
+// Restore undoes the soft delete of the employee.
+func (r *employeeRepository) Restore(id uuid.UUID) error {
+	return r.db.Unscoped().Model(&models.Employee{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+// Rehire undoes the soft delete of the employee and saves its name, RG and
+// department in the same statement, so the unique indexes only ever see the
+// new RG on an active row.
+func (r *employeeRepository) Rehire(employee *models.Employee) error {
+	employee.DeletedAt = gorm.DeletedAt{}
+	return r.db.Unscoped().Model(employee).
+		Select("name", "rg", "department_id", "deleted_at", "updated_at").
+		Updates(employee).Error
+}
+
+// IsCPFInUse reports whether an active employee holds the CPF.
+func (r *employeeRepository) IsCPFInUse(cpf string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Employee{}).Where("cpf = ?", cpf).Count(&count).Error
+	return count > 0, err
+}
+
+// IsRGInUse reports whether an active employee holds the RG.
+func (r *employeeRepository) IsRGInUse(rg string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Employee{}).Where("rg = ?", rg).Count(&count).Error
+	return count > 0, err
+}
+
+// FindDeletedByCPFForUpdate loads the most recently removed employee with the
+// CPF, locking its row until the transaction ends.
+func (r *employeeRepository) FindDeletedByCPFForUpdate(cpf string) (*models.Employee, error) {
+	var employee models.Employee
+	err := r.db.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("cpf = ? AND deleted_at IS NOT NULL", cpf).
+		Order("deleted_at DESC").
+		First(&employee).Error
+	if err != nil {
+		return nil, err
+	}
+	return &employee, nil
+}
+
+// Anonymize writes the scrubbed name, CPF and RG of the employee and marks it
+// anonymized. The employee is removed, so the update bypasses soft delete.
+func (r *employeeRepository) Anonymize(employee *models.Employee) error {
+	return r.db.Unscoped().Model(&models.Employee{}).Where("id = ?", employee.ID).Updates(map[string]any{
+		"name":          employee.Name,
+		"cpf":           employee.CPF,
+		"rg":            employee.RG,
+		"anonymized_at": employee.AnonymizedAt,
+	}).Error
+}
@@ -0,0 +1,26 @@
+package repository
+
+import "gorm.io/gorm"
+
+// eachRow runs query and calls fn with each row scanned into a T, reading
+// one row at a time instead of loading the result. The same T is passed to
+// every call, so fn must not keep it.
+func eachRow[T any](query *gorm.DB, fn func(*T) error) error {
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var row T
+	for rows.Next() {
+		row = *new(T)
+		if err := query.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
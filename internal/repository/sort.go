@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// employeeSortColumns is the allow-list of fields employees can be sorted by.
+var employeeSortColumns = map[string]string{
+	"name":       "employees.name",
+	"cpf":        "employees.cpf",
+	"created_at": "employees.created_at",
+	"updated_at": "employees.updated_at",
+	"department": "departments.name",
+}
+
+// departmentSortColumns is the allow-list of fields departments can be sorted by.
+var departmentSortColumns = map[string]string{
+	"name":       "departments.name",
+	"created_at": "departments.created_at",
+	"updated_at": "departments.updated_at",
+	"manager":    "employees.name",
+}
+
+// checkSort rejects a sort field missing from columns. Listings call it
+// before running any query, so a bad request costs nothing.
+func checkSort(sort models.Sort, columns map[string]string) error {
+	if _, ok := columns[sort.Field]; sort.Field != "" && !ok {
+		return utils.ErrInvalidSort
+	}
+	return nil
+}
+
+// orderBy applies sort to query, looking the field up in columns, and always
+// breaks ties on idColumn so that a page never changes between requests.
+func orderBy(query *gorm.DB, sort models.Sort, columns map[string]string, idColumn string) (*gorm.DB, error) {
+	if err := checkSort(sort, columns); err != nil {
+		return nil, err
+	}
+	dir := " ASC"
+	if sort.Desc {
+		dir = " DESC"
+	}
+
+	if sort.Field != "" {
+		query = query.Order(columns[sort.Field] + dir)
+	}
+	return query.Order(idColumn + dir), nil
+}
@@ -1,44 +1,89 @@
 package routes
 
 import (
+	"ManageEmployeesandDepartments/internal/auth"
 	"ManageEmployeesandDepartments/internal/handlers"
+	"ManageEmployeesandDepartments/internal/middleware"
+	"ManageEmployeesandDepartments/internal/models"
 
 	"github.com/gin-gonic/gin"
 )
 
-// SetupRoutes configures all API endpoints in the Gin router.
+// SetupRoutes configures all API endpoints in the Gin router. Every endpoint
+// requires authentication, by user token or API key. Users read with any role
+// (managers only see their own subtree) and change data as hr_admin; API keys
+// are limited to their scopes and can never reach the hr_admin-only endpoints:
+// API keys, the audit log and personal data (LGPD export and anonymization).
 func SetupRoutes(
 	r *gin.Engine,
 	employeeHandler *handlers.EmployeeHandler,
 	deptHandler *handlers.DepartamentoHandler,
 	managerHandler *handlers.ManagerHandler,
+	apiKeyHandler *handlers.APIKeyHandler,
+	auditHandler *handlers.AuditHandler,
+	personalDataHandler *handlers.PersonalDataHandler,
+	authn *middleware.Auth,
 ) {
-	v1 := r.Group("/api/v1")
+	read := func(scope string) gin.HandlerFunc {
+		return middleware.RequireAccess(scope, auth.RoleHRAdmin, auth.RoleViewer, auth.RoleManager)
+	}
+	write := func(scope string) gin.HandlerFunc {
+		return middleware.RequireAccess(scope, auth.RoleHRAdmin)
+	}
+	admin := middleware.RequireRoles(auth.RoleHRAdmin)
+
+	v1 := r.Group("/api/v1", authn.Authenticate())
 	{
 		// Rotas de Colaboradores
 		colab := v1.Group("/colaboradores")
 		{
-			colab.POST("", employeeHandler.Create)
-			colab.GET("/:id", employeeHandler.GetByID)
-			colab.PUT("/:id", employeeHandler.Update)
-			colab.DELETE("/:id", employeeHandler.Delete)
-			colab.POST("/listar", employeeHandler.List)
+			colab.POST("", write(models.ScopeEmployeesWrite), employeeHandler.Create)
+			colab.GET("/:id", read(models.ScopeEmployeesRead), employeeHandler.GetByID)
+			colab.GET("/:id/historico", read(models.ScopeEmployeesRead), employeeHandler.History)
+			colab.PUT("/:id", write(models.ScopeEmployeesWrite), employeeHandler.Update)
+			colab.DELETE("/:id", write(models.ScopeEmployeesWrite), employeeHandler.Delete)
+			colab.POST("/listar", read(models.ScopeEmployeesRead), employeeHandler.List)
+			colab.GET("/exportar", read(models.ScopeEmployeesRead), employeeHandler.Export)
+			colab.POST("/importar", write(models.ScopeEmployeesWrite), employeeHandler.Import)
+			colab.GET("/excluidos", write(models.ScopeEmployeesWrite), employeeHandler.ListDeleted)
+			colab.POST("/:id/restaurar", write(models.ScopeEmployeesWrite), employeeHandler.Restore)
+			colab.POST("/:id/anonimizar", admin, employeeHandler.Anonymize)
+			colab.GET("/:id/dados-pessoais", admin, personalDataHandler.Export)
 		}
 
 		// Rotas de Departamentos
 		depto := v1.Group("/departamentos")
 		{
-			depto.POST("", deptHandler.Create)
-			depto.GET("/:id", deptHandler.GetByID)
-			depto.PUT("/:id", deptHandler.Update)
-			depto.DELETE("/:id", deptHandler.Delete)
-			depto.POST("/listar", deptHandler.List)
+			depto.POST("", write(models.ScopeDepartmentsWrite), deptHandler.Create)
+			depto.GET("/:id", read(models.ScopeDepartmentsRead), deptHandler.GetByID)
+			depto.PUT("/:id", write(models.ScopeDepartmentsWrite), deptHandler.Update)
+			depto.GET("/:id/previa-movimentacao", write(models.ScopeDepartmentsWrite), deptHandler.PreviewMove)
+			depto.DELETE("/:id", write(models.ScopeDepartmentsWrite), deptHandler.Delete)
+			depto.POST("/listar", read(models.ScopeDepartmentsRead), deptHandler.List)
+			depto.GET("/exportar", read(models.ScopeDepartmentsRead), deptHandler.Export)
+			depto.GET("/excluidos", write(models.ScopeDepartmentsWrite), deptHandler.ListDeleted)
+			depto.POST("/:id/restaurar", write(models.ScopeDepartmentsWrite), deptHandler.Restore)
+			depto.POST("/:id/mesclar", write(models.ScopeDepartmentsWrite), write(models.ScopeEmployeesWrite), deptHandler.Merge)
 		}
 
+		// Reorganizações movem departamentos e colaboradores de uma só vez
+		v1.POST("/reorganizacoes", write(models.ScopeDepartmentsWrite), write(models.ScopeEmployeesWrite), deptHandler.Reorganize)
+
 		// Rotas de Gerentes
 		gerentes := v1.Group("/gerentes")
 		{
-			gerentes.GET("/:id/colaboradores", managerHandler.GetSubordinates)
+			gerentes.GET("/:id/colaboradores", read(models.ScopeEmployeesRead), managerHandler.GetSubordinates)
 		}
+
+		// Rotas de Chaves de API
+		chaves := v1.Group("/chaves-api", admin)
+		{
+			chaves.POST("", apiKeyHandler.Create)
+			chaves.GET("", apiKeyHandler.List)
+			chaves.DELETE("/:id", apiKeyHandler.Revoke)
+		}
+
+		// Rotas de Auditoria
+		v1.GET("/auditoria", admin, auditHandler.List)
 	}
 }
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/utils"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AnonymizeEmployee irreversibly erases the personal data of a removed
+// employee, for LGPD erasure requests. The name becomes AnonymizedName, the
+// RG is cleared and the CPF is replaced by a tombstone; the same values are
+// redacted from the employee's audit entries. The row itself stays, so
+// department history and audit entries keep pointing at it, and the erasure
+// is recorded in the audit log. Active employees must be removed first.
+//
+// With dryRun nothing is written and the report tells what would change.
+func (s *employeeService) AnonymizeEmployee(id uuid.UUID, dryRun bool) (*models.AnonymizationReport, error) {
+	var report *models.AnonymizationReport
+	err := s.deptRepo.Transaction(func(tx *gorm.DB) error {
+		employeeRepo := s.employeeRepo.WithTx(tx)
+		auditRepo := s.auditRepo.WithTx(tx)
+
+		employee, err := employeeRepo.FindByIDWithDeletedForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if employee.AnonymizedAt != nil {
+			return utils.ErrEmployeeAnonymized
+		}
+		if !employee.DeletedAt.Valid {
+			return utils.ErrNotDeleted
+		}
+
+		entries, err := auditRepo.ListByEntity(id)
+		if err != nil {
+			return err
+		}
+		var redacted []*models.AuditEntry
+		for _, entry := range entries {
+			if redactPersonalData(entry.Changes) {
+				redacted = append(redacted, entry)
+			}
+		}
+
+		before := map[string]any{"name": models.RedactedValue, "cpf": models.RedactedValue}
+		fields := []string{"name", "cpf"}
+		if employee.RG != nil {
+			before["rg"] = models.RedactedValue
+			fields = append(fields, "rg")
+		}
+		report = &models.AnonymizationReport{
+			EmployeeID:           id,
+			DryRun:               dryRun,
+			Fields:               fields,
+			CPFTombstone:         cpfTombstone(id),
+			AuditEntriesRedacted: len(redacted),
+		}
+		if dryRun {
+			return nil
+		}
+
+		now := time.Now()
+		employee.Name = models.AnonymizedName
+		employee.CPF = report.CPFTombstone
+		employee.RG = nil
+		employee.AnonymizedAt = &now
+		if err := employeeRepo.Anonymize(employee); err != nil {
+			return err
+		}
+		for _, entry := range redacted {
+			if err := auditRepo.UpdateChanges(entry); err != nil {
+				return err
+			}
+		}
+		report.AnonymizedAt = &now
+
+		after := map[string]any{"name": employee.Name, "cpf": employee.CPF, "anonymized_at": now}
+		if _, ok := before["rg"]; ok {
+			after["rg"] = nil
+		}
+		return s.audit(tx, id, models.AuditActionAnonymize, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// redactPersonalData replaces the personal data in changes by RedactedValue,
+// reporting whether there was any.
+func redactPersonalData(changes models.AuditChanges) bool {
+	redacted := false
+	for _, field := range models.EmployeePersonalFields {
+		change, ok := changes[field]
+		if !ok {
+			continue
+		}
+		if change.Before != nil && change.Before != models.RedactedValue {
+			change.Before = models.RedactedValue
+			redacted = true
+		}
+		if change.After != nil && change.After != models.RedactedValue {
+			change.After = models.RedactedValue
+			redacted = true
+		}
+		changes[field] = change
+	}
+	return redacted
+}
+
+// cpfTombstone is the CPF of an anonymized employee: "X" and ten hex digits
+// of a hash of the employee ID. It fits the CPF column, can never be a valid
+// CPF and says nothing about the erased one.
+func cpfTombstone(id uuid.UUID) string {
+	sum := sha256.Sum256(id[:])
+	return "X" + hex.EncodeToString(sum[:])[:10]
+}
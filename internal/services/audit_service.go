@@ -0,0 +1,91 @@
+package services
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/repository"
+	"encoding/json"
+	"reflect"
+
+	"github.com/google/uuid"
+)
+
+// SystemActor is recorded as the actor of mutations made without a caller.
+const SystemActor = "system"
+
+// auditIgnoredFields are derived or nested values that are not part of the
+// audited row; their changes are already reported through the ID fields.
+var auditIgnoredFields = map[string]bool{
+	"created_at":      true,
+	"updated_at":      true,
+	"manager":         true,
+	"sub_departments": true,
+}
+
+type AuditService interface {
+	ListAuditEntries(filter models.AuditFilter, page, pageSize int) (*models.AuditListResponse, error)
+}
+
+type auditService struct {
+	repo repository.AuditRepository
+}
+
+func NewAuditService(repo repository.AuditRepository) AuditService {
+	return &auditService{repo: repo}
+}
+
+func (s *auditService) ListAuditEntries(filter models.AuditFilter, page, pageSize int) (*models.AuditListResponse, error) {
+	entries, total, err := s.repo.List(filter, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewPage(entries, page, pageSize, total), nil
+}
+
+// auditSnapshot captures the audited fields of an entity as they are now.
+// Take it before changing the entity in place.
+func auditSnapshot(entity any) (map[string]any, error) {
+	b, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	for field := range auditIgnoredFields {
+		delete(fields, field)
+	}
+	return fields, nil
+}
+
+// auditDiff lists the fields whose value differs between two snapshots. A
+// nil before (create) or after (delete) reports every field.
+func auditDiff(before, after map[string]any) models.AuditChanges {
+	changes := models.AuditChanges{}
+	for field, old := range before {
+		if now, ok := after[field]; !ok || !reflect.DeepEqual(old, now) {
+			changes[field] = models.AuditChange{Before: old, After: after[field]}
+		}
+	}
+	for field, now := range after {
+		if _, ok := before[field]; !ok {
+			changes[field] = models.AuditChange{After: now}
+		}
+	}
+	return changes
+}
+
+// recordAudit writes an audit entry for a mutation. repo must be bound to
+// the mutation's transaction.
+func recordAudit(repo repository.AuditRepository, actor, entity string, id uuid.UUID, action string, before, after map[string]any) error {
+	if actor == "" {
+		actor = SystemActor
+	}
+	return repo.Create(&models.AuditEntry{
+		Actor:    actor,
+		Entity:   entity,
+		EntityID: id,
+		Action:   action,
+		Changes:  auditDiff(before, after),
+	})
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// recordDepartmentVersion records the current state of dept as in effect from
+// at on, ending the previous version at the same instant. repo must be bound
+// to the transaction that changes the department.
+func recordDepartmentVersion(repo repository.DepartmentHistoryRepository, dept *models.Department, at time.Time) error {
+	if err := repo.CloseCurrent(dept.ID, at); err != nil {
+		return err
+	}
+	return repo.Create(models.NewDepartmentVersion(dept, at))
+}
+
+// sameDepartmentVersion reports whether two versions hold the same name,
+// manager and parent.
+func sameDepartmentVersion(a, b *models.DepartmentVersion) bool {
+	return a.Name == b.Name && equalIDs(a.ManagerID, b.ManagerID) && equalIDs(a.ParentDepartmentID, b.ParentDepartmentID)
+}
+
+func equalIDs(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
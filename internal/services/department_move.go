@@ -0,0 +1,268 @@
+package services
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/repository"
+	"ManageEmployeesandDepartments/internal/utils"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DefaultMoveConfirmationTTL is how long a move confirmation token stays
+// valid when MoveConfirmation.TTL is not set.
+const DefaultMoveConfirmationTTL = 15 * time.Minute
+
+// MoveConfirmation configures the confirmation of large department moves:
+// moving a subtree with more than Threshold employees under a new parent, by
+// update, reorganization or merge, takes the token returned by PreviewMove.
+type MoveConfirmation struct {
+	Threshold int           // 0 turns confirmation off
+	TTL       time.Duration // Defaults to DefaultMoveConfirmationTTL
+	Secret    []byte        // Signs the tokens; shared by every instance of the API
+}
+
+// moveClaims is what a confirmation token vouches for: moving department D
+// from parent F to parent P (nil for a root) before E.
+type moveClaims struct {
+	D uuid.UUID  `json:"d"`
+	F *uuid.UUID `json:"f,omitempty"`
+	P *uuid.UUID `json:"p,omitempty"`
+	E int64      `json:"e"`
+}
+
+// sign returns the token for claims, as base64 JSON and its HMAC.
+func (m MoveConfirmation) sign(claims moveClaims) string {
+	raw, _ := json.Marshal(claims) // Cannot fail for this struct
+	return base64.RawURLEncoding.EncodeToString(raw) + "." + base64.RawURLEncoding.EncodeToString(m.mac(raw))
+}
+
+// verify reports whether token was signed for moving dept from its current
+// parent to parentID, and has not expired.
+func (m MoveConfirmation) verify(token string, dept *models.Department, parentID *uuid.UUID, now time.Time) bool {
+	payload, mac, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return false
+	}
+	sum, err := base64.RawURLEncoding.DecodeString(mac)
+	if err != nil || !hmac.Equal(sum, m.mac(raw)) {
+		return false
+	}
+
+	var claims moveClaims
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		return false
+	}
+	return claims.D == dept.ID && equalIDs(claims.F, dept.ParentDepartmentID) &&
+		equalIDs(claims.P, parentID) && now.Unix() < claims.E
+}
+
+// confirm checks token for moving dept, whose subtree has headcount
+// employees, from its current parent to parentID. Only moves above the
+// threshold need one.
+func (m MoveConfirmation) confirm(token string, dept *models.Department, parentID *uuid.UUID, headcount int64) error {
+	if m.Threshold <= 0 || headcount <= int64(m.Threshold) {
+		return nil
+	}
+	if token == "" {
+		return utils.ErrConfirmationRequired
+	}
+	if !m.verify(token, dept, parentID, time.Now()) {
+		return utils.ErrInvalidConfirmation
+	}
+	return nil
+}
+
+func (m MoveConfirmation) mac(raw []byte) []byte {
+	h := hmac.New(sha256.New, m.Secret)
+	h.Write(raw)
+	return h.Sum(nil)
+}
+
+func (m MoveConfirmation) ttl() time.Duration {
+	if m.TTL <= 0 {
+		return DefaultMoveConfirmationTTL
+	}
+	return m.TTL
+}
+
+// PreviewMove describes moving department id, with its whole subtree, under
+// parentID (the nil UUID makes it a root), without moving anything: the
+// departments and employees that go along, the depth they end up at and the
+// managers whose chain of superiors changes. It checks the move as
+// UpdateDepartment would and signs a token for it, which UpdateDepartment
+// asks for when the headcount is above the configured threshold. Reparent
+// and merge operations take the same token, previewed as a move under the
+// new parent or under the merge target.
+func (s *departmentService) PreviewMove(id, parentID uuid.UUID) (*models.MovePreview, error) {
+	depts, err := s.deptRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*models.Department, len(depts))
+	for _, d := range depts {
+		byID[d.ID] = d
+	}
+
+	dept, ok := byID[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	var newParent *uuid.UUID
+	if parentID != uuid.Nil {
+		if parentID == id {
+			return nil, utils.ErrCycleDetected
+		}
+		if _, ok := byID[parentID]; !ok {
+			return nil, utils.ErrParentDepartmentNotFound
+		}
+		newParent = &parentID
+	}
+
+	subtree, err := s.deptRepo.FindAllSubordinateIDs(id)
+	if err != nil {
+		return nil, err
+	}
+	if newParent != nil && slices.Contains(subtree, parentID) {
+		return nil, utils.ErrCycleDetected
+	}
+	counts, err := s.employeeRepo.CountByDepartmentIDs(subtree)
+	if err != nil {
+		return nil, err
+	}
+
+	// The tree before and after the move differ only in the parent of dept
+	before := func(d *models.Department) *uuid.UUID { return d.ParentDepartmentID }
+	after := func(d *models.Department) *uuid.UUID {
+		if d.ID == id {
+			return newParent
+		}
+		return d.ParentDepartmentID
+	}
+
+	preview := &models.MovePreview{
+		DepartmentID:          id,
+		ParentDepartmentID:    dept.ParentDepartmentID,
+		NewParentDepartmentID: newParent,
+		Depth:                 len(ancestors(byID, dept, before)),
+		NewDepth:              len(ancestors(byID, dept, after)),
+		Departments:           []*models.MovedDepartment{},
+		ReportingChanges:      []*models.ReportingChange{},
+		ConfirmationThreshold: s.moves.Threshold,
+	}
+	for _, subID := range subtree {
+		sub, ok := byID[subID]
+		if !ok {
+			continue
+		}
+		preview.Headcount += counts[subID]
+		preview.Departments = append(preview.Departments, &models.MovedDepartment{
+			ID:                 sub.ID,
+			Name:               sub.Name,
+			ManagerID:          sub.ManagerID,
+			ParentDepartmentID: after(sub),
+			Headcount:          counts[subID],
+			NewDepth:           len(ancestors(byID, sub, after)),
+		})
+
+		if sub.ManagerID == nil {
+			continue
+		}
+		chainBefore, chainAfter := managerChain(byID, sub, before), managerChain(byID, sub, after)
+		if !slices.Equal(chainBefore, chainAfter) {
+			preview.ReportingChanges = append(preview.ReportingChanges, &models.ReportingChange{
+				ManagerID:      *sub.ManagerID,
+				DepartmentID:   sub.ID,
+				DepartmentName: sub.Name,
+				Before:         chainBefore,
+				After:          chainAfter,
+			})
+		}
+	}
+	slices.SortStableFunc(preview.Departments, func(a, b *models.MovedDepartment) int { return a.NewDepth - b.NewDepth })
+
+	preview.ConfirmationRequired = s.moves.Threshold > 0 && preview.Headcount > int64(s.moves.Threshold)
+	preview.ConfirmationExpiresAt = time.Now().Add(s.moves.ttl()).Truncate(time.Second)
+	preview.ConfirmationToken = s.moves.sign(moveClaims{
+		D: id,
+		F: dept.ParentDepartmentID,
+		P: newParent,
+		E: preview.ConfirmationExpiresAt.Unix(),
+	})
+	return preview, nil
+}
+
+// confirmMove checks that moving dept under parentID is confirmed, when the
+// employees of its subtree are more than the threshold. It runs within the
+// transaction of the move, after the new parent has been checked.
+func (s *departmentService) confirmMove(deptRepo repository.DepartmentRepository, employeeRepo repository.EmployeeRepository, dept *models.Department, parentID *uuid.UUID, token string) error {
+	if s.moves.Threshold <= 0 || equalIDs(dept.ParentDepartmentID, parentID) {
+		return nil
+	}
+
+	subtree, err := deptRepo.FindAllSubordinateIDs(dept.ID)
+	if err != nil {
+		return err
+	}
+	headcount, err := countEmployees(employeeRepo, subtree)
+	if err != nil {
+		return err
+	}
+	return s.moves.confirm(token, dept, parentID, headcount)
+}
+
+// countEmployees returns the number of active employees of deptIDs.
+func countEmployees(employeeRepo repository.EmployeeRepository, deptIDs []uuid.UUID) (int64, error) {
+	counts, err := employeeRepo.CountByDepartmentIDs(deptIDs)
+	if err != nil {
+		return 0, err
+	}
+	var headcount int64
+	for _, count := range counts {
+		headcount += count
+	}
+	return headcount, nil
+}
+
+// ancestors returns the departments above d, nearest first, as parentOf
+// links them. The walk stops at a missing parent or after visiting every
+// department, so a cycle cannot make it loop.
+func ancestors(byID map[uuid.UUID]*models.Department, d *models.Department, parentOf func(*models.Department) *uuid.UUID) []*models.Department {
+	var chain []*models.Department
+	for cur := d; len(chain) < len(byID); {
+		parentID := parentOf(cur)
+		if parentID == nil {
+			break
+		}
+		parent, ok := byID[*parentID]
+		if !ok {
+			break
+		}
+		chain = append(chain, parent)
+		cur = parent
+	}
+	return chain
+}
+
+// managerChain returns the managers above the manager of d, nearest first,
+// skipping departments without one.
+func managerChain(byID map[uuid.UUID]*models.Department, d *models.Department, parentOf func(*models.Department) *uuid.UUID) []uuid.UUID {
+	chain := []uuid.UUID{}
+	for _, a := range ancestors(byID, d, parentOf) {
+		if a.ManagerID != nil {
+			chain = append(chain, *a.ManagerID)
+		}
+	}
+	return chain
+}
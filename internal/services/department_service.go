@@ -4,142 +4,557 @@ import (
 	"ManageEmployeesandDepartments/internal/models"
 	"ManageEmployeesandDepartments/internal/repository"
 	"ManageEmployeesandDepartments/internal/utils"
+	"slices"
+	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type DepartmentService interface {
+	WithActor(actor string) DepartmentService
 	CreateDepartment(name string, managerID uuid.UUID, parentID *uuid.UUID) (*models.Department, error)
-	GetDepartmentWithTree(id uuid.UUID) (*models.Department, error)
-	UpdateDepartment(id uuid.UUID, name *string, managerID *uuid.UUID, parentID *uuid.UUID) (*models.Department, error)
+	GetDepartmentWithTree(id uuid.UUID, maxDepth int, asOf *time.Time) (*models.Department, error)
+	UpdateDepartment(id uuid.UUID, name *string, managerID *uuid.UUID, parentID *uuid.UUID, confirmation string) (*models.Department, error)
+	PreviewMove(id, parentID uuid.UUID) (*models.MovePreview, error)
 	DeleteDepartment(id uuid.UUID) error
-	ListDepartments(name, managerName *string, parentID *uuid.UUID, page, pageSize int) ([]*models.Department, error)
-	GetSubordinateEmployeesRecursively(managerID uuid.UUID) ([]*models.Employee, error)
+	ListDepartments(name, managerName *string, parentID *uuid.UUID, sort models.Sort, page, pageSize int) (*models.DepartmentListResponse, error)
+	ExportDepartments(filter models.DepartmentFilter, sort models.Sort, fn func(*models.DepartmentExportRow) error) error
+	GetSubordinateEmployeesRecursively(managerID uuid.UUID, filter models.SubordinatesFilter) (*models.SubordinatesResponse, error)
+	ManagedSubtreeIDs(managerID uuid.UUID) ([]uuid.UUID, error)
+	ListDeletedDepartments(page, pageSize int) (*models.DeletedDepartmentListResponse, error)
+	RestoreDepartment(id uuid.UUID) (*models.Department, error)
+	Reorganize(ops []models.ReorganizationOperation, dryRun bool) (*models.ReorganizationReport, error)
+	MergeDepartment(id, targetID uuid.UUID, managerID *uuid.UUID, confirmation string) (*models.Department, error)
 }
 
 type departmentService struct {
 	deptRepo     repository.DepartmentRepository
 	employeeRepo repository.EmployeeRepository
+	auditRepo    repository.AuditRepository
+	historyRepo  repository.EmployeeHistoryRepository
+	versionRepo  repository.DepartmentHistoryRepository
+	moves        MoveConfirmation
+	actor        string
 }
 
-func NewDepartmentService(dr repository.DepartmentRepository, cr repository.EmployeeRepository) DepartmentService {
-	return &departmentService{deptRepo: dr, employeeRepo: cr}
+func NewDepartmentService(dr repository.DepartmentRepository, cr repository.EmployeeRepository, ar repository.AuditRepository, hr repository.EmployeeHistoryRepository, vr repository.DepartmentHistoryRepository, mc MoveConfirmation) DepartmentService {
+	return &departmentService{deptRepo: dr, employeeRepo: cr, auditRepo: ar, historyRepo: hr, versionRepo: vr, moves: mc}
 }
 
+// WithActor returns a service that records actor as the author of the
+// mutations it makes in the audit log.
+func (s *departmentService) WithActor(actor string) DepartmentService {
+	bound := *s
+	bound.actor = actor
+	return &bound
+}
+
+// audit records a mutation of entity id in the audit log, within tx.
+func (s *departmentService) audit(tx *gorm.DB, entity string, id uuid.UUID, action string, before, after map[string]any) error {
+	return recordAudit(s.auditRepo.WithTx(tx), s.actor, entity, id, action, before, after)
+}
+
+// CreateDepartment creates a department. A nil managerID creates it without a
+// manager, which is how the first departments are bootstrapped; otherwise the
+// manager is moved into the new department in the same transaction, since a
+// manager must belong to the department they run.
 func (s *departmentService) CreateDepartment(name string, managerID uuid.UUID, parentID *uuid.UUID) (*models.Department, error) {
-	// Validates Manager
-	_, err := s.employeeRepo.FindByID(managerID)
-	if err != nil {
-		return nil, utils.ErrManagerNotFound
-	}
+	dept := &models.Department{Name: name}
 
-	// Validates Parent Department (if provided)
-	if parentID != nil && *parentID != uuid.Nil {
-		if _, err := s.deptRepo.FindByID(*parentID); err != nil {
-			return nil, utils.ErrParentDepartmentNotFound
+	err := s.deptRepo.Transaction(func(tx *gorm.DB) error {
+		deptRepo := s.deptRepo.WithTx(tx)
+		employeeRepo := s.employeeRepo.WithTx(tx)
+
+		// Validates Manager
+		var manager *models.Employee
+		if managerID != uuid.Nil {
+			var err error
+			manager, err = employeeRepo.FindByIDForUpdate(managerID)
+			if err != nil {
+				return utils.ErrManagerNotFound
+			}
+			dept.ManagerID = &manager.ID
 		}
-	}
 
-	dept := &models.Department{
-		Name:               name,
-		ManagerID:          &managerID,
-		ParentDepartmentID: parentID,
-	}
+		// Validates Parent Department (if provided)
+		if parentID != nil && *parentID != uuid.Nil {
+			if _, err := deptRepo.FindByID(*parentID); err != nil {
+				return utils.ErrParentDepartmentNotFound
+			}
+			dept.ParentDepartmentID = parentID
+		}
+
+		if manager != nil {
+			managed, err := deptRepo.FindByManagerID(manager.ID)
+			if err != nil {
+				return err
+			}
+			if len(managed) > 0 {
+				return utils.ErrManagerOfAnotherDepartment
+			}
+		}
+
+		if err := deptRepo.Create(dept); err != nil {
+			return err
+		}
+		if err := recordDepartmentVersion(s.versionRepo.WithTx(tx), dept, dept.CreatedAt); err != nil {
+			return err
+		}
+		after, err := auditSnapshot(dept)
+		if err != nil {
+			return err
+		}
+		if err := s.audit(tx, models.AuditEntityDepartment, dept.ID, models.AuditActionCreate, nil, after); err != nil {
+			return err
+		}
 
-	if err := s.deptRepo.Create(dept); err != nil {
+		if manager != nil && manager.DepartmentID != dept.ID {
+			before, err := auditSnapshot(manager)
+			if err != nil {
+				return err
+			}
+			manager.DepartmentID = dept.ID
+			if err := employeeRepo.Update(manager); err != nil {
+				return err
+			}
+			if err := assignDepartment(s.historyRepo.WithTx(tx), manager.ID, dept.ID, manager.UpdatedAt); err != nil {
+				return err
+			}
+			after, err := auditSnapshot(manager)
+			if err != nil {
+				return err
+			}
+			return s.audit(tx, models.AuditEntityEmployee, manager.ID, models.AuditActionUpdate, before, after)
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return dept, nil
 }
 
-func (s *departmentService) GetDepartmentWithTree(id uuid.UUID) (*models.Department, error) {
+// GetDepartmentWithTree returns the department with its manager and the
+// hierarchy below it, limited to maxDepth levels (0 loads the whole tree).
+// With asOf, names, managers and parent links are the ones in effect at that
+// time, and departments removed since then are included.
+func (s *departmentService) GetDepartmentWithTree(id uuid.UUID, maxDepth int, asOf *time.Time) (*models.Department, error) {
+	if asOf != nil {
+		return s.departmentTreeAt(id, maxDepth, *asOf)
+	}
+
 	dept, err := s.deptRepo.FindByIDWithManager(id)
 	if err != nil {
 		return nil, err
 	}
 
-	// Load sub-departments recursively (simplified for now)
-	subDepts, err := s.deptRepo.FindSubDepartments(id)
+	descendants, err := s.deptRepo.FindDescendants(id, maxDepth)
 	if err != nil {
 		return nil, err
 	}
-	dept.SubDepartments = subDepts
+	attachSubDepartments(dept, descendants)
 
 	return dept, nil
 }
 
-func (s *departmentService) UpdateDepartment(id uuid.UUID, name *string, managerID *uuid.UUID, parentID *uuid.UUID) (*models.Department, error) {
-	dept, err := s.deptRepo.FindByID(id)
+func (s *departmentService) departmentTreeAt(id uuid.UUID, maxDepth int, at time.Time) (*models.Department, error) {
+	dept, err := s.versionRepo.FindAt(id, at)
 	if err != nil {
-		return nil, utils.ErrDepartmentNotFound
+		return nil, err
 	}
 
-	if name != nil {
-		dept.Name = *name
+	descendants, err := s.versionRepo.FindDescendantsAt(id, at, maxDepth)
+	if err != nil {
+		return nil, err
 	}
-	if managerID != nil {
-		dept.ManagerID = managerID
+	attachSubDepartments(dept, descendants)
+
+	return dept, nil
+}
+
+// attachSubDepartments links a flat list of descendants to their parents,
+// building the SubDepartments tree under root.
+func attachSubDepartments(root *models.Department, descendants []*models.Department) {
+	nodes := make(map[uuid.UUID]*models.Department, len(descendants)+1)
+	nodes[root.ID] = root
+	for _, dept := range descendants {
+		nodes[dept.ID] = dept
 	}
-	if parentID != nil {
-		dept.ParentDepartmentID = parentID
+
+	for _, dept := range descendants {
+		if dept.ParentDepartmentID == nil {
+			continue
+		}
+		if parent, ok := nodes[*dept.ParentDepartmentID]; ok {
+			parent.SubDepartments = append(parent.SubDepartments, dept)
+		}
 	}
+}
 
-	if err := s.deptRepo.Update(dept); err != nil {
+// UpdateDepartment applies the provided changes. A new manager must already
+// belong to the department, and a nil UUID as manager removes it. A new parent
+// is rejected when it is the department itself or one of its descendants; a
+// nil UUID as parent turns the department into a root. The checks and the
+// update run in one transaction holding row locks on the rows involved, so
+// concurrent moves cannot close a cycle or pull the manager out. Moving a
+// subtree with more employees than the threshold of MoveConfirmation takes
+// the confirmation token of PreviewMove for that same move.
+func (s *departmentService) UpdateDepartment(id uuid.UUID, name *string, managerID *uuid.UUID, parentID *uuid.UUID, confirmation string) (*models.Department, error) {
+	var dept *models.Department
+	err := s.deptRepo.Transaction(func(tx *gorm.DB) error {
+		deptRepo := s.deptRepo.WithTx(tx)
+
+		var err error
+		dept, err = deptRepo.FindByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		before, err := auditSnapshot(dept)
+		if err != nil {
+			return err
+		}
+		previous := *models.NewDepartmentVersion(dept, time.Time{})
+
+		if name != nil {
+			dept.Name = *name
+		}
+		if managerID != nil {
+			if err := checkNewManager(s.employeeRepo.WithTx(tx), id, *managerID); err != nil {
+				return err
+			}
+			if *managerID == uuid.Nil {
+				dept.ManagerID = nil
+			} else {
+				dept.ManagerID = managerID
+			}
+		}
+		if parentID != nil {
+			if err := checkNewParent(deptRepo, id, *parentID); err != nil {
+				return err
+			}
+			newParent := parentID
+			if *parentID == uuid.Nil {
+				newParent = nil
+			}
+			if err := s.confirmMove(deptRepo, s.employeeRepo.WithTx(tx), dept, newParent, confirmation); err != nil {
+				return err
+			}
+			dept.ParentDepartmentID = newParent
+		}
+
+		if err := deptRepo.Update(dept); err != nil {
+			return err
+		}
+		if current := models.NewDepartmentVersion(dept, time.Time{}); !sameDepartmentVersion(&previous, current) {
+			if err := recordDepartmentVersion(s.versionRepo.WithTx(tx), dept, dept.UpdatedAt); err != nil {
+				return err
+			}
+		}
+
+		after, err := auditSnapshot(dept)
+		if err != nil {
+			return err
+		}
+		return s.audit(tx, models.AuditEntityDepartment, dept.ID, models.AuditActionUpdate, before, after)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return dept, nil
 }
 
-func (s *departmentService) DeleteDepartment(id uuid.UUID) error {
-	// Check if department has employees
-	count, err := s.employeeRepo.CountByDepartmentID(id)
+// checkNewManager validates managerID as the new manager of department id,
+// locking the employee row so they cannot be transferred concurrently.
+func checkNewManager(employeeRepo repository.EmployeeRepository, id, managerID uuid.UUID) error {
+	if managerID == uuid.Nil {
+		return nil
+	}
+
+	manager, err := employeeRepo.FindByIDForUpdate(managerID)
 	if err != nil {
+		return utils.ErrManagerNotFound
+	}
+	if manager.DepartmentID != id {
+		return utils.ErrManagerNotBelongToDepartment
+	}
+	return nil
+}
+
+// checkNewParent validates parentID as the new parent of department id. It
+// must run inside a transaction: the parent's ancestor chain is locked before
+// the cycle check, so the check sees any move committed in the meantime.
+func checkNewParent(deptRepo repository.DepartmentRepository, id, parentID uuid.UUID) error {
+	if parentID == uuid.Nil {
+		return nil
+	}
+	if parentID == id {
+		return utils.ErrCycleDetected
+	}
+
+	if err := deptRepo.LockAncestors(parentID); err != nil {
 		return err
 	}
-	if count > 0 {
-		return utils.ErrDepartmentHasEmployees
+	if _, err := deptRepo.FindByID(parentID); err != nil {
+		return utils.ErrParentDepartmentNotFound
 	}
 
-	// Check if department has sub-departments
-	subCount, err := s.deptRepo.CountSubDepartments(id)
+	isSubordinate, err := deptRepo.IsSubordinate(id, parentID)
 	if err != nil {
 		return err
 	}
-	if subCount > 0 {
-		return utils.ErrDepartmentHasSubDepartments
+	if isSubordinate {
+		return utils.ErrCycleDetected
 	}
+	return nil
+}
+
+func (s *departmentService) DeleteDepartment(id uuid.UUID) error {
+	return s.deptRepo.Transaction(func(tx *gorm.DB) error {
+		deptRepo := s.deptRepo.WithTx(tx)
+
+		dept, err := deptRepo.FindByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
 
-	return s.deptRepo.Delete(id)
+		// Check if department has employees
+		count, err := s.employeeRepo.WithTx(tx).CountByDepartmentID(id)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return utils.ErrDepartmentHasEmployees
+		}
+
+		// Check if department has sub-departments
+		subCount, err := deptRepo.CountSubDepartments(id)
+		if err != nil {
+			return err
+		}
+		if subCount > 0 {
+			return utils.ErrDepartmentHasSubDepartments
+		}
+
+		if err := deptRepo.Delete(id); err != nil {
+			return err
+		}
+		if err := s.versionRepo.WithTx(tx).CloseCurrent(id, time.Now()); err != nil {
+			return err
+		}
+
+		before, err := auditSnapshot(dept)
+		if err != nil {
+			return err
+		}
+		return s.audit(tx, models.AuditEntityDepartment, dept.ID, models.AuditActionDelete, before, nil)
+	})
 }
 
-func (s *departmentService) ListDepartments(name, managerName *string, parentID *uuid.UUID, page, pageSize int) ([]*models.Department, error) {
-	return s.deptRepo.List(name, managerName, parentID, page, pageSize)
+// ListDeletedDepartments lists removed departments, most recently removed
+// first.
+func (s *departmentService) ListDeletedDepartments(page, pageSize int) (*models.DeletedDepartmentListResponse, error) {
+	departments, total, err := s.deptRepo.ListDeleted(page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*models.DeletedDepartment, len(departments))
+	for i, d := range departments {
+		items[i] = &models.DeletedDepartment{Department: d, DeletedAt: d.DeletedAt.Time}
+	}
+	return models.NewPage(items, page, pageSize, total), nil
 }
 
-func (s *departmentService) GetSubordinateEmployeesRecursively(managerID uuid.UUID) ([]*models.Employee, error) {
-	// Find departments managed by this manager
-	departments, err := s.deptRepo.FindByManagerID(managerID)
+// RestoreDepartment undoes the removal of a department, under the parent it
+// had. The parent must still exist; restore it first otherwise. The
+// department comes back without a manager (see repository Restore).
+func (s *departmentService) RestoreDepartment(id uuid.UUID) (*models.Department, error) {
+	var dept *models.Department
+	err := s.deptRepo.Transaction(func(tx *gorm.DB) error {
+		deptRepo := s.deptRepo.WithTx(tx)
+
+		var err error
+		dept, err = deptRepo.FindByIDWithDeletedForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if !dept.DeletedAt.Valid {
+			return utils.ErrNotDeleted
+		}
+		before, err := auditSnapshot(dept)
+		if err != nil {
+			return err
+		}
+
+		if dept.ParentDepartmentID != nil {
+			if err := deptRepo.LockAncestors(*dept.ParentDepartmentID); err != nil {
+				return err
+			}
+			if _, err := deptRepo.FindByID(*dept.ParentDepartmentID); err != nil {
+				return utils.ErrParentDepartmentNotFound
+			}
+		}
+
+		if err := deptRepo.Restore(id); err != nil {
+			return err
+		}
+		if dept, err = deptRepo.FindByID(id); err != nil {
+			return err
+		}
+		if err := recordDepartmentVersion(s.versionRepo.WithTx(tx), dept, dept.UpdatedAt); err != nil {
+			return err
+		}
+
+		after, err := auditSnapshot(dept)
+		if err != nil {
+			return err
+		}
+		return s.audit(tx, models.AuditEntityDepartment, id, models.AuditActionRestore, before, after)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if len(departments) == 0 {
+	return dept, nil
+}
+
+func (s *departmentService) ListDepartments(name, managerName *string, parentID *uuid.UUID, sort models.Sort, page, pageSize int) (*models.DepartmentListResponse, error) {
+	departments, total, err := s.deptRepo.List(name, managerName, parentID, sort, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewPage(departments, page, pageSize, total), nil
+}
+
+// ExportDepartments streams every department matching filter to fn, one at a
+// time.
+func (s *departmentService) ExportDepartments(filter models.DepartmentFilter, sort models.Sort, fn func(*models.DepartmentExportRow) error) error {
+	return s.deptRepo.Export(filter, sort, fn)
+}
+
+// GetSubordinateEmployeesRecursively lists the employees of every department
+// the manager runs directly and of all departments below those, down to
+// filter.Depth levels.
+func (s *departmentService) GetSubordinateEmployeesRecursively(managerID uuid.UUID, filter models.SubordinatesFilter) (*models.SubordinatesResponse, error) {
+	if _, err := s.employeeRepo.FindByID(managerID); err != nil {
+		return nil, utils.ErrManagerNotFound
+	}
+
+	// Find departments managed by this manager
+	managed, err := s.deptRepo.FindByManagerID(managerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(managed) == 0 {
 		return nil, utils.ErrManagerNotFound
 	}
 
-	// Get department IDs
-	var deptIDs []uuid.UUID
-	for _, dept := range departments {
-		deptIDs = append(deptIDs, dept.ID)
+	line, err := s.reportingLine(managed, filter.Depth)
+	if err != nil {
+		return nil, err
+	}
+
+	deptIDs := make([]uuid.UUID, 0, len(line))
+	var excludeIDs []uuid.UUID
+	for id, node := range line {
+		if filter.Scope != nil && !slices.Contains(filter.Scope, id) {
+			continue
+		}
+		deptIDs = append(deptIDs, id)
+		if !filter.IncludeManagers && node.dept.ManagerID != nil {
+			excludeIDs = append(excludeIDs, *node.dept.ManagerID)
+		}
 	}
 
-	// Find all employees in these departments
-	employees, err := s.employeeRepo.FindByDepartmentIDs(deptIDs)
+	employees, total, err := s.employeeRepo.ListByDepartmentIDs(deptIDs, excludeIDs, filter.Page, filter.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*models.SubordinateEmployee, 0, len(employees))
+	for _, employee := range employees {
+		item := &models.SubordinateEmployee{Employee: employee}
+		if node, ok := line[employee.DepartmentID]; ok {
+			item.DepartmentName = node.dept.Name
+			item.Depth = node.depth
+		}
+		items = append(items, item)
+	}
+
+	return models.NewPage(items, filter.Page, filter.PageSize, total), nil
+}
+
+// ManagedSubtreeIDs returns the departments the manager runs directly and
+// every department below them. It is empty for an employee who manages none.
+func (s *departmentService) ManagedSubtreeIDs(managerID uuid.UUID) ([]uuid.UUID, error) {
+	managed, err := s.deptRepo.FindByManagerID(managerID)
 	if err != nil {
 		return nil, err
 	}
 
-	return employees, nil
+	seen := make(map[uuid.UUID]bool)
+	ids := []uuid.UUID{}
+	for _, dept := range managed {
+		subtree, err := s.deptRepo.FindAllSubordinateIDs(dept.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range subtree {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids, nil
+}
+
+// lineNode is a department in a manager's reporting line and its distance
+// from the closest department the manager runs directly.
+type lineNode struct {
+	dept  *models.Department
+	depth int
+}
+
+// reportingLine collects the managed departments and their descendants up to
+// maxDepth levels (0 = all), keyed by department ID.
+func (s *departmentService) reportingLine(managed []*models.Department, maxDepth int) (map[uuid.UUID]*lineNode, error) {
+	line := make(map[uuid.UUID]*lineNode)
+	queue := make([]uuid.UUID, 0, len(managed))
+	for _, dept := range managed {
+		line[dept.ID] = &lineNode{dept: dept}
+		queue = append(queue, dept.ID)
+	}
+
+	children := make(map[uuid.UUID][]*models.Department)
+	for _, dept := range managed {
+		subDepts, err := s.deptRepo.FindDescendants(dept.ID, maxDepth)
+		if err != nil {
+			return nil, err
+		}
+		for _, sub := range subDepts {
+			if sub.ParentDepartmentID != nil {
+				children[*sub.ParentDepartmentID] = append(children[*sub.ParentDepartmentID], sub)
+			}
+		}
+	}
+
+	// Breadth-first from the managed departments, so each department gets its
+	// shortest distance even when the manager runs nested departments.
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, child := range children[id] {
+			if _, seen := line[child.ID]; seen {
+				continue
+			}
+			line[child.ID] = &lineNode{dept: child, depth: line[id].depth + 1}
+			queue = append(queue, child.ID)
+		}
+	}
+
+	return line, nil
 }
@@ -0,0 +1,23 @@
+package services
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// assignDepartment records that the employee belongs to deptID from at on:
+// the current assignment, if any, ends at the same instant. repo must be
+// bound to the transaction that moves the employee.
+func assignDepartment(repo repository.EmployeeHistoryRepository, employeeID, deptID uuid.UUID, at time.Time) error {
+	if err := repo.CloseCurrent(employeeID, at); err != nil {
+		return err
+	}
+	return repo.Create(&models.DepartmentAssignment{
+		EmployeeID:    employeeID,
+		DepartmentID:  deptID,
+		EffectiveFrom: at,
+	})
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/utils"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// errImportAborted rolls back an atomic import after a row failed to write.
+var errImportAborted = errors.New("import aborted")
+
+// pendingHire is an import row that passed validation.
+type pendingHire struct {
+	result       *models.ImportRowResult
+	name         string
+	cpf          string
+	rg           *string
+	departmentID uuid.UUID
+}
+
+// ImportEmployees creates employees in bulk. Every row is checked with the
+// rules of CreateEmployee, plus CPF or RG repeated within the file; a CPF of
+// a removed employee rehires them, as in CreateEmployee. Nothing is written
+// in a dry run. Otherwise an atomic import writes every row in a single
+// transaction, and nothing when any row is invalid; a best-effort import
+// writes each valid row on its own. The report says what happened to each
+// row.
+func (s *employeeService) ImportEmployees(rows []*models.EmployeeImportRow, opts models.ImportOptions) (*models.ImportReport, error) {
+	depts, err := s.deptRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	paths := newDepartmentPaths(depts)
+
+	report := &models.ImportReport{Mode: opts.Mode, DryRun: opts.DryRun, Rows: make([]*models.ImportRowResult, len(rows))}
+	var pending []*pendingHire
+	seenCPF, seenRG := map[string]int{}, map[string]int{}
+	for i, row := range rows {
+		result := &models.ImportRowResult{Line: row.Line, Status: models.ImportStatusValid}
+		report.Rows[i] = result
+
+		hire, field, err := s.validateImportRow(row, paths, seenCPF, seenRG)
+		if err != nil {
+			rejectRow(result, field, err)
+			continue
+		}
+		hire.result = result
+		pending = append(pending, hire)
+	}
+
+	// An atomic import with invalid rows writes nothing
+	if !opts.DryRun {
+		if opts.Mode == models.ImportModeBestEffort {
+			err = s.importEach(pending)
+		} else if len(pending) == len(rows) {
+			err = s.importAll(pending)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, result := range report.Rows {
+		switch result.Status {
+		case models.ImportStatusValid:
+			report.Valid++
+		case models.ImportStatusCreated:
+			report.Created++
+		case models.ImportStatusRehired:
+			report.Rehired++
+		case models.ImportStatusInvalid:
+			report.Invalid++
+		}
+	}
+	report.Total = len(rows)
+	report.Committed = report.Created+report.Rehired > 0
+	return report, nil
+}
+
+// validateImportRow checks a row as CreateEmployee would, naming the column
+// at fault when it fails. seenCPF and seenRG map the documents of the rows
+// accepted so far to their line.
+func (s *employeeService) validateImportRow(row *models.EmployeeImportRow, paths departmentPaths, seenCPF, seenRG map[string]int) (*pendingHire, string, error) {
+	hire := &pendingHire{name: strings.TrimSpace(row.Name), cpf: utils.NormalizeCPF(row.CPF)}
+	if hire.name == "" {
+		return nil, "name", fmt.Errorf("%w: name is required", utils.ErrInvalid)
+	}
+	if !utils.IsCPFValido(hire.cpf) {
+		return nil, "cpf", utils.ErrInvalidCPF
+	}
+	if rg := strings.TrimSpace(row.RG); rg != "" {
+		hire.rg = &rg
+	}
+
+	var err error
+	if hire.departmentID, err = paths.resolve(row.Department); err != nil {
+		return nil, "department", err
+	}
+
+	if line, ok := seenCPF[hire.cpf]; ok {
+		return nil, "cpf", fmt.Errorf("%w: same as line %d", utils.ErrCPFDuplicated, line)
+	}
+	if inUse, err := s.employeeRepo.IsCPFInUse(hire.cpf); err != nil {
+		return nil, "", err
+	} else if inUse {
+		return nil, "cpf", utils.ErrCPFDuplicated
+	}
+	if hire.rg != nil {
+		if line, ok := seenRG[*hire.rg]; ok {
+			return nil, "rg", fmt.Errorf("%w: same as line %d", utils.ErrRGDuplicated, line)
+		}
+		if inUse, err := s.employeeRepo.IsRGInUse(*hire.rg); err != nil {
+			return nil, "", err
+		} else if inUse {
+			return nil, "rg", utils.ErrRGDuplicated
+		}
+		seenRG[*hire.rg] = row.Line
+	}
+	seenCPF[hire.cpf] = row.Line
+
+	return hire, "", nil
+}
+
+// importEach writes every row in its own transaction.
+func (s *employeeService) importEach(pending []*pendingHire) error {
+	for _, p := range pending {
+		err := s.deptRepo.Transaction(func(tx *gorm.DB) error {
+			return s.importRow(tx, p)
+		})
+		if err != nil && !errors.Is(err, errImportAborted) {
+			return err
+		}
+	}
+	return nil
+}
+
+// importAll writes every row in a single transaction. When a row fails, none
+// is kept and the others go back to valid.
+func (s *employeeService) importAll(pending []*pendingHire) error {
+	err := s.deptRepo.Transaction(func(tx *gorm.DB) error {
+		for _, p := range pending {
+			if err := s.importRow(tx, p); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if !errors.Is(err, errImportAborted) {
+		return err
+	}
+
+	for _, p := range pending {
+		if p.result.Status != models.ImportStatusInvalid {
+			p.result.Status, p.result.EmployeeID = models.ImportStatusValid, nil
+		}
+	}
+	return nil
+}
+
+// importRow writes a validated row within tx. A rule the row breaks at this
+// point (e.g. a CPF taken since validation) is recorded in its result and
+// reported as errImportAborted, so the caller rolls tx back.
+func (s *employeeService) importRow(tx *gorm.DB, p *pendingHire) error {
+	employee, rehired, err := s.hire(tx, p.name, p.cpf, p.rg, p.departmentID)
+	if err != nil {
+		if utils.MapErrorToCustom(err).Code >= 500 {
+			return err
+		}
+		rejectRow(p.result, "", err)
+		return errImportAborted
+	}
+
+	p.result.EmployeeID = &employee.ID
+	p.result.Status = models.ImportStatusCreated
+	if rehired {
+		p.result.Status = models.ImportStatusRehired
+	}
+	return nil
+}
+
+// rejectRow marks a row invalid because of err. field overrides the field
+// err maps to, since import columns are named differently from JSON fields.
+func rejectRow(result *models.ImportRowResult, field string, err error) {
+	mapped := utils.MapErrorToCustom(err)
+	if field == "" {
+		field = mapped.Field
+	}
+	if field == "department_id" {
+		field = "department"
+	}
+	result.Status = models.ImportStatusInvalid
+	result.Error = &models.ImportError{Code: mapped.ErrorCode, Field: field, Message: err.Error()}
+}
+
+// departmentPaths finds active departments by ID or by name path from the
+// root ("Empresa/TI/Suporte"), ignoring case and spaces around names.
+type departmentPaths struct {
+	byID   map[uuid.UUID]*models.Department
+	byPath map[string]uuid.UUID // uuid.Nil when the path is ambiguous
+}
+
+func newDepartmentPaths(depts []*models.Department) departmentPaths {
+	paths := departmentPaths{byID: map[uuid.UUID]*models.Department{}, byPath: map[string]uuid.UUID{}}
+	for _, d := range depts {
+		paths.byID[d.ID] = d
+	}
+
+	for _, d := range depts {
+		names := []string{}
+		for cur := d; cur != nil && len(names) <= len(depts); {
+			names = append(names, cur.Name)
+			if cur.ParentDepartmentID == nil {
+				break
+			}
+			cur = paths.byID[*cur.ParentDepartmentID]
+		}
+		slices.Reverse(names)
+
+		key := pathKey(strings.Join(names, "/"))
+		if _, ok := paths.byPath[key]; ok {
+			paths.byPath[key] = uuid.Nil
+		} else {
+			paths.byPath[key] = d.ID
+		}
+	}
+	return paths
+}
+
+func (p departmentPaths) resolve(ref string) (uuid.UUID, error) {
+	if id, err := uuid.Parse(strings.TrimSpace(ref)); err == nil {
+		if _, ok := p.byID[id]; !ok {
+			return uuid.Nil, utils.ErrDepartmentNotFound
+		}
+		return id, nil
+	}
+
+	id, ok := p.byPath[pathKey(ref)]
+	if !ok {
+		return uuid.Nil, fmt.Errorf("%w: %q", utils.ErrDepartmentNotFound, ref)
+	}
+	if id == uuid.Nil {
+		return uuid.Nil, fmt.Errorf("%w: %q", utils.ErrDepartmentAmbiguous, ref)
+	}
+	return id, nil
+}
+
+// pathKey normalizes a department name path for lookups.
+func pathKey(path string) string {
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		parts[i] = strings.ToLower(strings.TrimSpace(part))
+	}
+	return strings.Join(parts, "/")
+}
@@ -4,41 +4,104 @@ import (
 	"ManageEmployeesandDepartments/internal/models"
 	"ManageEmployeesandDepartments/internal/repository"
 	"ManageEmployeesandDepartments/internal/utils"
+	"errors"
+	"slices"
+	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type EmployeeService interface {
+	WithActor(actor string) EmployeeService
 	CreateEmployee(name string, cpf string, rg *string, departmentID uuid.UUID) (*models.Employee, error)
 	GetEmployeeWithManager(id uuid.UUID) (*EmployeeWithManagerResponse, error)
 	UpdateEmployee(id uuid.UUID, name *string, rg *string, departmentID uuid.UUID) (*models.Employee, error)
 	DeleteEmployee(id uuid.UUID) error
-	ListEmployees(name *string, cpf *string, rg *string, deptID *uuid.UUID, page, pageSize int) ([]*models.Employee, error)
+	GetDepartmentHistory(id uuid.UUID) ([]*models.DepartmentAssignment, error)
+	ListDeletedEmployees(page, pageSize int) (*models.DeletedEmployeeListResponse, error)
+	RestoreEmployee(id uuid.UUID) (*models.Employee, error)
+	AnonymizeEmployee(id uuid.UUID, dryRun bool) (*models.AnonymizationReport, error)
+	ImportEmployees(rows []*models.EmployeeImportRow, opts models.ImportOptions) (*models.ImportReport, error)
+	ListEmployees(filter models.EmployeeFilter, sort models.Sort, page, pageSize int) (*models.EmployeeListResponse, error)
+	ListEmployeesByCursor(filter models.EmployeeFilter, sort models.Sort, after, before *string, pageSize int) (*models.EmployeeCursorPage, error)
+	ExportEmployees(filter models.EmployeeFilter, sort models.Sort, fn func(*models.EmployeeExportRow) error) error
 }
 
 type employeeService struct {
 	deptRepo     repository.DepartmentRepository
 	employeeRepo repository.EmployeeRepository
+	auditRepo    repository.AuditRepository
+	historyRepo  repository.EmployeeHistoryRepository
+	actor        string
 }
 
-func NewEmployeeService(deptRepo repository.DepartmentRepository, employeeRepo repository.EmployeeRepository) EmployeeService {
+func NewEmployeeService(deptRepo repository.DepartmentRepository, employeeRepo repository.EmployeeRepository, auditRepo repository.AuditRepository, historyRepo repository.EmployeeHistoryRepository) EmployeeService {
 	return &employeeService{
 		deptRepo:     deptRepo,
 		employeeRepo: employeeRepo,
+		auditRepo:    auditRepo,
+		historyRepo:  historyRepo,
 	}
 }
 
+// WithActor returns a service that records actor as the author of the
+// mutations it makes in the audit log.
+func (s *employeeService) WithActor(actor string) EmployeeService {
+	bound := *s
+	bound.actor = actor
+	return &bound
+}
+
+// audit records a mutation of an employee in the audit log, within tx.
+func (s *employeeService) audit(tx *gorm.DB, id uuid.UUID, action string, before, after map[string]any) error {
+	return recordAudit(s.auditRepo.WithTx(tx), s.actor, models.AuditEntityEmployee, id, action, before, after)
+}
+
 type EmployeeWithManagerResponse struct {
 	Employee    *models.Employee `json:"employee"`
 	ManagerName string           `json:"manager_name,omitempty"`
 }
 
-// CreateEmployee creates a new employee with CPF/RG and department validation
+// CreateEmployee creates a new employee with CPF/RG and department validation.
+// The CPF is stored as digits only, so "123.456.789-09" and "12345678909" are
+// the same person. When the CPF belongs to a removed employee, the person is
+// being rehired: their record is brought back with the given name, RG and
+// department, keeping its ID and department history.
 func (s *employeeService) CreateEmployee(name string, cpf string, rg *string, departmentID uuid.UUID) (*models.Employee, error) {
-	// Checks if department exists
-	_, err := s.deptRepo.FindByID(departmentID)
+	cpf = utils.NormalizeCPF(cpf)
+	if !utils.IsCPFValido(cpf) {
+		return nil, utils.ErrInvalidCPF
+	}
+
+	var employee *models.Employee
+	err := s.deptRepo.Transaction(func(tx *gorm.DB) error {
+		var err error
+		employee, _, err = s.hire(tx, name, cpf, rg, departmentID)
+		return err
+	})
 	if err != nil {
-		return nil, utils.ErrDepartmentNotFound
+		return nil, err
+	}
+
+	return employee, nil
+}
+
+// hire creates an employee within tx, or rehires them when the CPF belongs to
+// a removed employee, reporting which. The CPF must be normalized and valid.
+func (s *employeeService) hire(tx *gorm.DB, name string, cpf string, rg *string, departmentID uuid.UUID) (*models.Employee, bool, error) {
+	// Checks if department exists, keeping it from being removed meanwhile
+	if _, err := s.deptRepo.WithTx(tx).FindByIDForShare(departmentID); err != nil {
+		return nil, false, utils.ErrDepartmentNotFound
+	}
+
+	former, err := s.employeeRepo.WithTx(tx).FindDeletedByCPFForUpdate(cpf)
+	if err == nil {
+		employee, err := s.rehire(tx, former, name, rg, departmentID)
+		return employee, true, err
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
 	}
 
 	// Creates the employee
@@ -49,8 +112,46 @@ func (s *employeeService) CreateEmployee(name string, cpf string, rg *string, de
 		RG:           rg,
 		DepartmentID: departmentID,
 	}
+	err = s.employeeRepo.WithTx(tx).Create(employee)
+	if s.employeeRepo.IsCPFDuplicated(err) {
+		return nil, false, utils.ErrCPFDuplicated
+	}
+	if s.employeeRepo.IsRGDuplicated(err) {
+		return nil, false, utils.ErrRGDuplicated
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if err := assignDepartment(s.historyRepo.WithTx(tx), employee.ID, departmentID, employee.CreatedAt); err != nil {
+		return nil, false, err
+	}
 
-	err = s.employeeRepo.Create(employee)
+	after, err := auditSnapshot(employee)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := s.audit(tx, employee.ID, models.AuditActionCreate, nil, after); err != nil {
+		return nil, false, err
+	}
+	return employee, false, nil
+}
+
+// rehire brings a removed employee back with new data, opening a new period
+// in their department history. The CPF, or the new RG, may have been given
+// to an active employee in the meantime, which the unique indexes report.
+func (s *employeeService) rehire(tx *gorm.DB, employee *models.Employee, name string, rg *string, departmentID uuid.UUID) (*models.Employee, error) {
+	employeeRepo := s.employeeRepo.WithTx(tx)
+
+	before, err := auditSnapshot(employee)
+	if err != nil {
+		return nil, err
+	}
+
+	employee.Name = name
+	employee.RG = rg
+	employee.DepartmentID = departmentID
+
+	err = employeeRepo.Rehire(employee)
 	if s.employeeRepo.IsCPFDuplicated(err) {
 		return nil, utils.ErrCPFDuplicated
 	}
@@ -60,7 +161,17 @@ func (s *employeeService) CreateEmployee(name string, cpf string, rg *string, de
 	if err != nil {
 		return nil, err
 	}
+	if err := assignDepartment(s.historyRepo.WithTx(tx), employee.ID, departmentID, employee.UpdatedAt); err != nil {
+		return nil, err
+	}
 
+	after, err := auditSnapshot(employee)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.audit(tx, employee.ID, models.AuditActionRehire, before, after); err != nil {
+		return nil, err
+	}
 	return employee, nil
 }
 
@@ -77,7 +188,7 @@ func (s *employeeService) GetEmployeeWithManager(id uuid.UUID) (*EmployeeWithMan
 	}
 
 	var managerName string
-	if *dept.ManagerID != uuid.Nil {
+	if dept.ManagerID != nil {
 		manager, err := s.employeeRepo.FindByID(*dept.ManagerID)
 		if err == nil {
 			managerName = manager.Name
@@ -90,27 +201,188 @@ func (s *employeeService) GetEmployeeWithManager(id uuid.UUID) (*EmployeeWithMan
 	}, nil
 }
 
-// UpdateEmployee updates name, RG and department of an employee
+// UpdateEmployee updates name, RG and department of an employee. An employee
+// who manages a department cannot be moved out of it, since a manager must
+// belong to the department they run.
 func (s *employeeService) UpdateEmployee(id uuid.UUID, name *string, rg *string, departmentID uuid.UUID) (*models.Employee, error) {
-	employee, err := s.employeeRepo.FindByID(id)
+	var employee *models.Employee
+	err := s.deptRepo.Transaction(func(tx *gorm.DB) error {
+		deptRepo := s.deptRepo.WithTx(tx)
+		employeeRepo := s.employeeRepo.WithTx(tx)
+
+		var err error
+		employee, err = employeeRepo.FindByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		before, err := auditSnapshot(employee)
+		if err != nil {
+			return err
+		}
+
+		// Validates department, keeping it from being removed meanwhile
+		if _, err := deptRepo.FindByIDForShare(departmentID); err != nil {
+			return utils.ErrDepartmentNotFound
+		}
+
+		transferred := employee.DepartmentID != departmentID
+		if transferred {
+			isManager, err := deptRepo.IsManager(id)
+			if err != nil {
+				return err
+			}
+			if isManager {
+				return utils.ErrManagerNotBelongToDepartment
+			}
+		}
+
+		if name != nil {
+			employee.Name = *name
+		}
+		employee.RG = rg
+		employee.DepartmentID = departmentID
+
+		err = employeeRepo.Update(employee)
+		if s.employeeRepo.IsRGDuplicated(err) {
+			return utils.ErrRGDuplicated
+		}
+		if err != nil {
+			return err
+		}
+		if transferred {
+			if err := assignDepartment(s.historyRepo.WithTx(tx), employee.ID, departmentID, employee.UpdatedAt); err != nil {
+				return err
+			}
+		}
+
+		after, err := auditSnapshot(employee)
+		if err != nil {
+			return err
+		}
+		return s.audit(tx, employee.ID, models.AuditActionUpdate, before, after)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Validates department
-	_, err = s.deptRepo.FindByID(departmentID)
+	return employee, nil
+}
+
+// DeleteEmployee removes an employee (soft delete)
+func (s *employeeService) DeleteEmployee(id uuid.UUID) error {
+	return s.deptRepo.Transaction(func(tx *gorm.DB) error {
+		employeeRepo := s.employeeRepo.WithTx(tx)
+
+		employee, err := employeeRepo.FindByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+
+		// Does not allow deletion if they are a manager of any department
+		isManager, err := s.deptRepo.WithTx(tx).IsManager(id)
+		if err != nil {
+			return err
+		}
+		if isManager {
+			return utils.ErrManagerCannotBeDeleted
+		}
+
+		if err := employeeRepo.Delete(employee.ID); err != nil {
+			return err
+		}
+		if err := s.historyRepo.WithTx(tx).CloseCurrent(employee.ID, time.Now()); err != nil {
+			return err
+		}
+
+		before, err := auditSnapshot(employee)
+		if err != nil {
+			return err
+		}
+		return s.audit(tx, employee.ID, models.AuditActionDelete, before, nil)
+	})
+}
+
+// ListDeletedEmployees lists removed employees, most recently removed first.
+func (s *employeeService) ListDeletedEmployees(page, pageSize int) (*models.DeletedEmployeeListResponse, error) {
+	employees, total, err := s.employeeRepo.ListDeleted(page, pageSize)
 	if err != nil {
-		return nil, utils.ErrDepartmentNotFound
+		return nil, err
 	}
 
-	employee.Name = *name
-	employee.RG = rg
-	employee.DepartmentID = departmentID
-
-	err = s.employeeRepo.Update(employee)
-	if s.employeeRepo.IsRGDuplicated(err) {
-		return nil, utils.ErrRGDuplicated
+	items := make([]*models.DeletedEmployee, len(employees))
+	for i, e := range employees {
+		items[i] = &models.DeletedEmployee{Employee: e, DeletedAt: e.DeletedAt.Time}
 	}
+	return models.NewPage(items, page, pageSize, total), nil
+}
+
+// RestoreEmployee undoes the removal of an employee. Their CPF and RG may
+// have been given to someone else since, and their department may have been
+// removed too; both are checked again before the employee comes back. The
+// employee returns to the department they left. Anonymized employees cannot
+// be restored.
+func (s *employeeService) RestoreEmployee(id uuid.UUID) (*models.Employee, error) {
+	var employee *models.Employee
+	err := s.deptRepo.Transaction(func(tx *gorm.DB) error {
+		employeeRepo := s.employeeRepo.WithTx(tx)
+
+		var err error
+		employee, err = employeeRepo.FindByIDWithDeletedForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if !employee.DeletedAt.Valid {
+			return utils.ErrNotDeleted
+		}
+		if employee.AnonymizedAt != nil {
+			return utils.ErrEmployeeAnonymized
+		}
+		before, err := auditSnapshot(employee)
+		if err != nil {
+			return err
+		}
+
+		if _, err := s.deptRepo.WithTx(tx).FindByIDForShare(employee.DepartmentID); err != nil {
+			return utils.ErrDepartmentNotFound
+		}
+		if inUse, err := employeeRepo.IsCPFInUse(employee.CPF); err != nil {
+			return err
+		} else if inUse {
+			return utils.ErrCPFDuplicated
+		}
+		if employee.RG != nil {
+			if inUse, err := employeeRepo.IsRGInUse(*employee.RG); err != nil {
+				return err
+			} else if inUse {
+				return utils.ErrRGDuplicated
+			}
+		}
+
+		// The checks above do not lock anything, so a concurrent request may
+		// still take the CPF or RG first; the unique indexes catch it
+		err = employeeRepo.Restore(id)
+		if s.employeeRepo.IsCPFDuplicated(err) {
+			return utils.ErrCPFDuplicated
+		}
+		if s.employeeRepo.IsRGDuplicated(err) {
+			return utils.ErrRGDuplicated
+		}
+		if err != nil {
+			return err
+		}
+		if employee, err = employeeRepo.FindByID(id); err != nil {
+			return err
+		}
+		if err := assignDepartment(s.historyRepo.WithTx(tx), id, employee.DepartmentID, employee.UpdatedAt); err != nil {
+			return err
+		}
+
+		after, err := auditSnapshot(employee)
+		if err != nil {
+			return err
+		}
+		return s.audit(tx, id, models.AuditActionRestore, before, after)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -118,26 +390,103 @@ func (s *employeeService) UpdateEmployee(id uuid.UUID, name *string, rg *string,
 	return employee, nil
 }
 
-// DeleteEmployee removes an employee (soft delete)
-func (s *employeeService) DeleteEmployee(id uuid.UUID) error {
-	employee, err := s.employeeRepo.FindByID(id)
+// GetDepartmentHistory returns the departments the employee belonged to over
+// time, oldest first. Removed employees keep their history.
+func (s *employeeService) GetDepartmentHistory(id uuid.UUID) ([]*models.DepartmentAssignment, error) {
+	history, err := s.historyRepo.ListByEmployee(id)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if len(history) == 0 {
+		return nil, utils.ErrEmployeeNotFound
 	}
+	return history, nil
+}
 
-	// Does not allow deletion if they are a manager of any department
-	isManager, err := s.deptRepo.IsManager(id)
-	if err != nil {
-		return err
+// ListEmployees lists employees with filters and pagination
+func (s *employeeService) ListEmployees(filter models.EmployeeFilter, sort models.Sort, page, pageSize int) (*models.EmployeeListResponse, error) {
+	if filter.CPF != nil {
+		normalized := utils.NormalizeCPF(*filter.CPF)
+		filter.CPF = &normalized
 	}
-	if isManager {
-		return utils.ErrManagerCannotBeDeleted
+
+	employees, total, err := s.employeeRepo.List(filter, sort, page, pageSize)
+	if err != nil {
+		return nil, err
 	}
+	return models.NewPage(employees, page, pageSize, total), nil
+}
 
-	return s.employeeRepo.Delete(employee.ID)
+// ExportEmployees streams every employee matching the filters of
+// ListEmployees to fn, one at a time.
+func (s *employeeService) ExportEmployees(filter models.EmployeeFilter, sort models.Sort, fn func(*models.EmployeeExportRow) error) error {
+	if filter.CPF != nil {
+		normalized := utils.NormalizeCPF(*filter.CPF)
+		filter.CPF = &normalized
+	}
+	return s.employeeRepo.Export(filter, sort, fn)
 }
 
-// ListEmployees lists employees with filters and pagination
-func (s *employeeService) ListEmployees(name, cpf, rg *string, deptID *uuid.UUID, page, pageSize int) ([]*models.Employee, error) {
-	return s.employeeRepo.List(name, cpf, rg, deptID, page, pageSize)
+// ListEmployeesByCursor lists employees with keyset pagination. Exactly one of
+// after and before is expected; an empty cursor starts from the first (after)
+// or last (before) row.
+func (s *employeeService) ListEmployeesByCursor(filter models.EmployeeFilter, sort models.Sort, after, before *string, pageSize int) (*models.EmployeeCursorPage, error) {
+	if filter.CPF != nil {
+		normalized := utils.NormalizeCPF(*filter.CPF)
+		filter.CPF = &normalized
+	}
+
+	backward := before != nil
+	raw := after
+	if backward {
+		raw = before
+	}
+
+	var cursor *models.Cursor
+	if raw != nil && *raw != "" {
+		decoded, err := models.DecodeCursor(*raw)
+		if err != nil || !decoded.Matches(sort) {
+			return nil, utils.ErrInvalidCursor
+		}
+		cursor = decoded
+	}
+
+	// One extra row tells whether there is another page in this direction
+	employees, cursors, err := s.employeeRepo.ListByCursor(filter, sort, cursor, backward, pageSize+1)
+	if err != nil {
+		return nil, err
+	}
+	more := len(employees) > pageSize
+	if more {
+		employees, cursors = employees[:pageSize], cursors[:pageSize]
+	}
+	if backward {
+		slices.Reverse(employees)
+		slices.Reverse(cursors)
+	}
+
+	page := &models.EmployeeCursorPage{Items: employees, PageSize: pageSize}
+	if page.Items == nil {
+		page.Items = []*models.Employee{}
+	}
+	if len(cursors) > 0 {
+		first, last := cursors[0].Encode(), cursors[len(cursors)-1].Encode()
+		// Coming from a cursor means there is at least that row on its side
+		if backward {
+			if more {
+				page.PrevCursor = &first
+			}
+			if cursor != nil {
+				page.NextCursor = &last
+			}
+		} else {
+			if more {
+				page.NextCursor = &last
+			}
+			if cursor != nil {
+				page.PrevCursor = &first
+			}
+		}
+	}
+	return page, nil
 }
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/repository"
+	"ManageEmployeesandDepartments/internal/utils"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PersonalDataService answers LGPD data-subject access requests.
+type PersonalDataService interface {
+	ExportPersonalData(id uuid.UUID) (*models.PersonalDataExport, error)
+}
+
+type personalDataService struct {
+	deptRepo     repository.DepartmentRepository
+	employeeRepo repository.EmployeeRepository
+	historyRepo  repository.EmployeeHistoryRepository
+	versionRepo  repository.DepartmentHistoryRepository
+	auditRepo    repository.AuditRepository
+}
+
+func NewPersonalDataService(deptRepo repository.DepartmentRepository, employeeRepo repository.EmployeeRepository, historyRepo repository.EmployeeHistoryRepository, versionRepo repository.DepartmentHistoryRepository, auditRepo repository.AuditRepository) PersonalDataService {
+	return &personalDataService{
+		deptRepo:     deptRepo,
+		employeeRepo: employeeRepo,
+		historyRepo:  historyRepo,
+		versionRepo:  versionRepo,
+		auditRepo:    auditRepo,
+	}
+}
+
+// ExportPersonalData collects what is stored about an employee, removed or
+// not: their record, current manager, department history, the departments
+// they managed and the audit entries about them.
+func (s *personalDataService) ExportPersonalData(id uuid.UUID) (*models.PersonalDataExport, error) {
+	employee, err := s.employeeRepo.FindByIDWithDeleted(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, utils.ErrEmployeeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	export := &models.PersonalDataExport{
+		GeneratedAt: time.Now().UTC(),
+		Employee:    employee,
+	}
+	if employee.DeletedAt.Valid {
+		export.DeletedAt = &employee.DeletedAt.Time
+	} else if export.Manager, err = s.currentManager(employee); err != nil {
+		return nil, err
+	}
+
+	if export.DepartmentHistory, err = s.historyRepo.ListByEmployee(id); err != nil {
+		return nil, err
+	}
+	versions, err := s.versionRepo.ListByManager(id)
+	if err != nil {
+		return nil, err
+	}
+	export.ManagedDepartments = managementPeriods(versions)
+	if export.AuditEntries, err = s.auditRepo.ListByEntity(id); err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// currentManager returns the manager of the employee's department, or nil
+// when it has none or the employee manages it.
+func (s *personalDataService) currentManager(employee *models.Employee) (*models.PersonRef, error) {
+	dept, err := s.deptRepo.FindByID(employee.DepartmentID)
+	if err != nil {
+		return nil, err
+	}
+	if dept.ManagerID == nil || *dept.ManagerID == employee.ID {
+		return nil, nil
+	}
+	manager, err := s.employeeRepo.FindByID(*dept.ManagerID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PersonRef{ID: manager.ID, Name: manager.Name}, nil
+}
+
+// managementPeriods joins consecutive versions of the same department into
+// one period, named as in its latest version. versions must be ordered by
+// department and time.
+func managementPeriods(versions []*models.DepartmentVersion) []*models.ManagementPeriod {
+	periods := []*models.ManagementPeriod{}
+	var last *models.ManagementPeriod
+	for _, v := range versions {
+		if last != nil && last.DepartmentID == v.DepartmentID && last.To != nil && last.To.Equal(v.ValidFrom) {
+			last.DepartmentName = v.Name
+			last.To = v.ValidTo
+			continue
+		}
+		last = &models.ManagementPeriod{
+			DepartmentID:   v.DepartmentID,
+			DepartmentName: v.Name,
+			From:           v.ValidFrom,
+			To:             v.ValidTo,
+		}
+		periods = append(periods, last)
+	}
+	return periods
+}
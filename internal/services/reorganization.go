@@ -0,0 +1,582 @@
+package services
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/repository"
+	"ManageEmployeesandDepartments/internal/utils"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Reorganize applies a plan of operations to the department tree in one
+// transaction. The operations run in order against a copy of the tree, each
+// checked against the state the previous ones left; the resulting tree is
+// then checked as a whole, so a plan may pass through states that would be
+// rejected one PUT at a time (swapping two managers, say). Nothing is
+// written when any check fails, or in a dry run. The report lists the
+// problems found, or every change the plan makes.
+func (s *departmentService) Reorganize(ops []models.ReorganizationOperation, dryRun bool) (*models.ReorganizationReport, error) {
+	report := &models.ReorganizationReport{
+		DryRun:   dryRun,
+		Problems: []*models.ReorganizationProblem{},
+		Changes:  []*models.ReorganizationChange{},
+	}
+
+	err := s.deptRepo.Transaction(func(tx *gorm.DB) error {
+		// A dry run writes nothing, so it reads without locking
+		plan, err := newReorganization(s.deptRepo.WithTx(tx), s.employeeRepo.WithTx(tx), s.moves, !dryRun)
+		if err != nil {
+			return err
+		}
+
+		for i, op := range ops {
+			entityID, field, err := plan.apply(op)
+			if err != nil {
+				if utils.MapErrorToCustom(err).Code >= 500 {
+					return err
+				}
+				problem := reorganizationProblem(&i, entityID, err)
+				if field != "" {
+					problem.Field = field
+				}
+				report.Problems = append(report.Problems, problem)
+			}
+		}
+		// The resulting tree means little when an operation was skipped
+		if len(report.Problems) == 0 {
+			for _, issue := range plan.check() {
+				report.Problems = append(report.Problems, reorganizationProblem(nil, &issue.entityID, issue.err))
+			}
+		}
+		if report.Valid = len(report.Problems) == 0; !report.Valid {
+			return nil
+		}
+
+		if report.Changes, err = plan.changes(); err != nil {
+			return err
+		}
+		if dryRun {
+			return nil
+		}
+		if err := s.applyReorganization(tx, plan); err != nil {
+			return err
+		}
+		report.Applied = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// MergeDepartment merges department id into targetID, as the merge
+// operation of a reorganization: its employees and sub-departments move to
+// the target and it is removed. managerID picks the surviving manager (see
+// MergeDepartmentDTO), who must belong to the merged department. Merging a
+// subtree above the move confirmation threshold takes the token of the
+// preview of moving it under the target. It returns the target as it stands
+// after the merge.
+func (s *departmentService) MergeDepartment(id, targetID uuid.UUID, managerID *uuid.UUID, confirmation string) (*models.Department, error) {
+	var target *models.Department
+	err := s.deptRepo.Transaction(func(tx *gorm.DB) error {
+		plan, err := newReorganization(s.deptRepo.WithTx(tx), s.employeeRepo.WithTx(tx), s.moves, true)
+		if err != nil {
+			return err
+		}
+		source, ok := plan.department(id)
+		if !ok {
+			return gorm.ErrRecordNotFound
+		}
+		if target, ok = plan.department(targetID); !ok {
+			return fmt.Errorf("%w: target department", utils.ErrDepartmentNotFound)
+		}
+
+		survivor := managerID
+		if survivor == nil {
+			if survivor = target.ManagerID; survivor == nil {
+				survivor = source.ManagerID
+			}
+		}
+		if survivor == nil {
+			none := uuid.Nil
+			survivor = &none
+		}
+		ops := []models.ReorganizationOperation{
+			{Type: models.ReorganizeMerge, DepartmentID: &id, TargetDepartmentID: &targetID, ConfirmationToken: confirmation},
+			{Type: models.ReorganizeChangeManager, DepartmentID: &targetID, ManagerID: survivor},
+		}
+		for _, op := range ops {
+			if _, _, err := plan.apply(op); err != nil {
+				return err
+			}
+		}
+		if issues := plan.check(); len(issues) > 0 {
+			return issues[0].err
+		}
+
+		return s.applyReorganization(tx, plan)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
+
+// applyReorganization writes the changes of a checked plan within tx:
+// employees first, then departments, then the departments merged away.
+func (s *departmentService) applyReorganization(tx *gorm.DB, plan *reorganization) error {
+	deptRepo := s.deptRepo.WithTx(tx)
+	employeeRepo := s.employeeRepo.WithTx(tx)
+
+	for _, id := range plan.employeeOrder {
+		employee, original := plan.employees[id], plan.originalEmployees[id]
+		if employee.DepartmentID == original.DepartmentID {
+			continue
+		}
+		before, err := auditSnapshot(&original)
+		if err != nil {
+			return err
+		}
+		if err := employeeRepo.Update(employee); err != nil {
+			return err
+		}
+		if err := assignDepartment(s.historyRepo.WithTx(tx), employee.ID, employee.DepartmentID, employee.UpdatedAt); err != nil {
+			return err
+		}
+		after, err := auditSnapshot(employee)
+		if err != nil {
+			return err
+		}
+		if err := s.audit(tx, models.AuditEntityEmployee, employee.ID, models.AuditActionUpdate, before, after); err != nil {
+			return err
+		}
+	}
+
+	for _, id := range plan.deptOrder {
+		dept, original := plan.depts[id], plan.originalDepts[id]
+		previous, current := models.NewDepartmentVersion(&original, time.Time{}), models.NewDepartmentVersion(dept, time.Time{})
+		if plan.removed[id] || sameDepartmentVersion(previous, current) {
+			continue
+		}
+		before, err := auditSnapshot(&original)
+		if err != nil {
+			return err
+		}
+		if err := deptRepo.Update(dept); err != nil {
+			return err
+		}
+		if err := recordDepartmentVersion(s.versionRepo.WithTx(tx), dept, dept.UpdatedAt); err != nil {
+			return err
+		}
+		after, err := auditSnapshot(dept)
+		if err != nil {
+			return err
+		}
+		if err := s.audit(tx, models.AuditEntityDepartment, dept.ID, models.AuditActionUpdate, before, after); err != nil {
+			return err
+		}
+	}
+
+	for _, id := range plan.deptOrder {
+		if !plan.removed[id] {
+			continue
+		}
+		original := plan.originalDepts[id]
+		// The staff was moved out above; anyone still here joined after
+		// the plan was read and would be left without a department
+		count, err := employeeRepo.CountByDepartmentID(id)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return utils.ErrDepartmentHasEmployees
+		}
+		if err := deptRepo.Update(plan.depts[id]); err != nil {
+			return err
+		}
+		if err := deptRepo.Delete(id); err != nil {
+			return err
+		}
+		if err := s.versionRepo.WithTx(tx).CloseCurrent(id, time.Now()); err != nil {
+			return err
+		}
+		before, err := auditSnapshot(&original)
+		if err != nil {
+			return err
+		}
+		if err := s.audit(tx, models.AuditEntityDepartment, id, models.AuditActionDelete, before, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reorganization is the department tree as a plan changes it. It holds
+// every active department and the employees the plan touches, along with
+// the rows as they were before the plan. A plan that will be applied locks
+// the rows it reads.
+type reorganization struct {
+	deptRepo     repository.DepartmentRepository
+	employeeRepo repository.EmployeeRepository
+	moves        MoveConfirmation
+	lock         bool
+
+	depts         map[uuid.UUID]*models.Department
+	originalDepts map[uuid.UUID]models.Department
+	deptOrder     []uuid.UUID
+	removed       map[uuid.UUID]bool // Merged into another department
+
+	employees         map[uuid.UUID]*models.Employee
+	originalEmployees map[uuid.UUID]models.Employee
+	employeeOrder     []uuid.UUID
+	staffLoaded       map[uuid.UUID]bool // Departments whose employees are all loaded
+}
+
+func newReorganization(deptRepo repository.DepartmentRepository, employeeRepo repository.EmployeeRepository, moves MoveConfirmation, lock bool) (*reorganization, error) {
+	findAll := deptRepo.FindAll
+	if lock {
+		findAll = deptRepo.FindAllForUpdate
+	}
+	depts, err := findAll()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &reorganization{
+		deptRepo:          deptRepo,
+		employeeRepo:      employeeRepo,
+		moves:             moves,
+		lock:              lock,
+		depts:             make(map[uuid.UUID]*models.Department, len(depts)),
+		originalDepts:     make(map[uuid.UUID]models.Department, len(depts)),
+		removed:           map[uuid.UUID]bool{},
+		employees:         map[uuid.UUID]*models.Employee{},
+		originalEmployees: map[uuid.UUID]models.Employee{},
+		staffLoaded:       map[uuid.UUID]bool{},
+	}
+	for _, d := range depts {
+		r.depts[d.ID], r.originalDepts[d.ID] = d, *d
+		r.deptOrder = append(r.deptOrder, d.ID)
+	}
+	return r, nil
+}
+
+// apply runs one operation. When it cannot run, it returns the department
+// or employee at fault and, when the error does not tell, the field naming
+// it.
+func (r *reorganization) apply(op models.ReorganizationOperation) (*uuid.UUID, string, error) {
+	dept, ok := r.department(*op.DepartmentID)
+	if !ok {
+		return op.DepartmentID, "", utils.ErrDepartmentNotFound
+	}
+
+	switch op.Type {
+	case models.ReorganizeRename:
+		if op.Name == nil || strings.TrimSpace(*op.Name) == "" {
+			return op.DepartmentID, "name", fmt.Errorf("%w: name is required", utils.ErrInvalid)
+		}
+		dept.Name = strings.TrimSpace(*op.Name)
+
+	case models.ReorganizeReparent:
+		if op.ParentDepartmentID == nil {
+			return op.DepartmentID, "parent_department_id", fmt.Errorf("%w: parent_department_id is required", utils.ErrInvalid)
+		}
+		var parentID *uuid.UUID
+		if *op.ParentDepartmentID != uuid.Nil {
+			if _, ok := r.department(*op.ParentDepartmentID); !ok {
+				return op.ParentDepartmentID, "", utils.ErrParentDepartmentNotFound
+			}
+			parentID = op.ParentDepartmentID
+		}
+		if !equalIDs(dept.ParentDepartmentID, parentID) {
+			if err := r.confirmMove(dept, parentID, op.ConfirmationToken); err != nil {
+				return op.DepartmentID, "", err
+			}
+		}
+		dept.ParentDepartmentID = parentID
+
+	case models.ReorganizeChangeManager:
+		if op.ManagerID == nil {
+			return op.DepartmentID, "manager_id", fmt.Errorf("%w: manager_id is required", utils.ErrInvalid)
+		}
+		if *op.ManagerID == uuid.Nil {
+			dept.ManagerID = nil
+			break
+		}
+		if _, err := r.employee(*op.ManagerID); err != nil {
+			return op.ManagerID, "", notFoundAs(err, utils.ErrManagerNotFound)
+		}
+		dept.ManagerID = op.ManagerID
+
+	case models.ReorganizeMerge:
+		if op.TargetDepartmentID == nil {
+			return op.DepartmentID, "target_department_id", fmt.Errorf("%w: target_department_id is required", utils.ErrInvalid)
+		}
+		if *op.TargetDepartmentID == dept.ID {
+			return op.DepartmentID, "target_department_id", fmt.Errorf("%w: a department cannot be merged into itself", utils.ErrInvalid)
+		}
+		if _, ok := r.department(*op.TargetDepartmentID); !ok {
+			return op.TargetDepartmentID, "target_department_id", utils.ErrDepartmentNotFound
+		}
+		// Merging takes the whole subtree of dept under the target
+		if err := r.confirmMove(dept, op.TargetDepartmentID, op.ConfirmationToken); err != nil {
+			return op.DepartmentID, "", err
+		}
+		if err := r.merge(dept, *op.TargetDepartmentID); err != nil {
+			return op.DepartmentID, "", err
+		}
+
+	case models.ReorganizeMoveEmployee:
+		if op.EmployeeID == nil {
+			return op.DepartmentID, "employee_id", fmt.Errorf("%w: employee_id is required", utils.ErrInvalid)
+		}
+		employee, err := r.employee(*op.EmployeeID)
+		if err != nil {
+			return op.EmployeeID, "employee_id", notFoundAs(err, utils.ErrEmployeeNotFound)
+		}
+		employee.DepartmentID = dept.ID
+	}
+	return nil, "", nil
+}
+
+// merge moves the employees and sub-departments of dept to target and
+// removes dept, so that no employee or department is left behind. A target
+// below dept takes its place in the tree first.
+func (r *reorganization) merge(dept *models.Department, target uuid.UUID) error {
+	if !r.staffLoaded[dept.ID] {
+		// Locking the staff, with every department already locked, keeps
+		// concurrent edits from being overwritten and anyone from joining
+		// dept before it is removed
+		find := r.employeeRepo.FindByDepartmentIDs
+		if r.lock {
+			find = r.employeeRepo.FindByDepartmentIDsForUpdate
+		}
+		staff, err := find([]uuid.UUID{dept.ID})
+		if err != nil {
+			return err
+		}
+		for _, e := range staff {
+			r.track(e)
+		}
+		r.staffLoaded[dept.ID] = true
+	}
+
+	for _, id := range r.employeeOrder {
+		if e := r.employees[id]; e.DepartmentID == dept.ID {
+			e.DepartmentID = target
+		}
+	}
+	if r.isBelow(target, dept.ID) {
+		r.depts[target].ParentDepartmentID = dept.ParentDepartmentID
+	}
+	for _, id := range r.deptOrder {
+		if sub := r.depts[id]; !r.removed[id] && sub.ParentDepartmentID != nil && *sub.ParentDepartmentID == dept.ID {
+			sub.ParentDepartmentID = &target
+		}
+	}
+	dept.ManagerID = nil
+	r.removed[dept.ID] = true
+	return nil
+}
+
+// confirmMove checks the confirmation of moving dept, with its subtree as
+// the plan left it, under parentID, as UpdateDepartment does. The token is
+// checked against the department as it was before the plan, which is what
+// PreviewMove saw.
+func (r *reorganization) confirmMove(dept *models.Department, parentID *uuid.UUID, token string) error {
+	if r.moves.Threshold <= 0 {
+		return nil
+	}
+	var subtree []uuid.UUID
+	for _, id := range r.deptOrder {
+		if !r.removed[id] && (id == dept.ID || r.isBelow(id, dept.ID)) {
+			subtree = append(subtree, id)
+		}
+	}
+	headcount, err := countEmployees(r.employeeRepo, subtree)
+	if err != nil {
+		return err
+	}
+	original := r.originalDepts[dept.ID]
+	return r.moves.confirm(token, &original, parentID, headcount)
+}
+
+// reorganizationIssue is a rule the tree left by a plan breaks.
+type reorganizationIssue struct {
+	entityID uuid.UUID // Department or employee at fault
+	err      error
+}
+
+// check validates the tree the plan leaves: no cycles, every manager in the
+// department they run, and every employee the plan touches in a department
+// that still exists.
+func (r *reorganization) check() []reorganizationIssue {
+	var issues []reorganizationIssue
+
+	for _, id := range r.deptOrder {
+		if r.removed[id] {
+			continue
+		}
+		if r.inCycle(id) {
+			issues = append(issues, reorganizationIssue{id, utils.ErrCycleDetected})
+		}
+	}
+
+	for _, id := range r.deptOrder {
+		dept := r.depts[id]
+		if r.removed[id] || dept.ManagerID == nil {
+			continue
+		}
+		// Managers the plan does not touch stay where they are
+		if manager, ok := r.employees[*dept.ManagerID]; ok && manager.DepartmentID != id {
+			issues = append(issues, reorganizationIssue{id, utils.ErrManagerNotBelongToDepartment})
+		}
+	}
+
+	for _, id := range r.employeeOrder {
+		if _, ok := r.department(r.employees[id].DepartmentID); !ok {
+			issues = append(issues, reorganizationIssue{id, fmt.Errorf("%w: the employee would be left without a department", utils.ErrDepartmentNotFound)})
+		}
+	}
+	return issues
+}
+
+// inCycle reports whether walking up from department id leads back to it.
+func (r *reorganization) inCycle(id uuid.UUID) bool {
+	return r.isBelow(id, id)
+}
+
+// isBelow reports whether walking up from department id reaches ancestor.
+func (r *reorganization) isBelow(id, ancestor uuid.UUID) bool {
+	cur := r.depts[id]
+	for range len(r.depts) {
+		if cur.ParentDepartmentID == nil {
+			return false
+		}
+		if *cur.ParentDepartmentID == ancestor {
+			return true
+		}
+		parent, ok := r.depts[*cur.ParentDepartmentID]
+		if !ok {
+			return false
+		}
+		cur = parent
+	}
+	return false
+}
+
+// changes lists what the plan changes, employees first, as the audit log
+// will record it.
+func (r *reorganization) changes() ([]*models.ReorganizationChange, error) {
+	changes := []*models.ReorganizationChange{}
+
+	for _, id := range r.employeeOrder {
+		original := r.originalEmployees[id]
+		change, err := reorganizationChange(models.AuditEntityEmployee, id, r.employees[id].Name, &original, r.employees[id])
+		if err != nil {
+			return nil, err
+		}
+		if change != nil {
+			changes = append(changes, change)
+		}
+	}
+
+	for _, id := range r.deptOrder {
+		original := r.originalDepts[id]
+		var after any = r.depts[id]
+		if r.removed[id] {
+			after = nil
+		}
+		change, err := reorganizationChange(models.AuditEntityDepartment, id, original.Name, &original, after)
+		if err != nil {
+			return nil, err
+		}
+		if change != nil {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+// reorganizationChange compares an entity before and after the plan; a nil
+// after means it is removed. It returns nil when nothing changed.
+func reorganizationChange(entity string, id uuid.UUID, name string, before, after any) (*models.ReorganizationChange, error) {
+	beforeFields, err := auditSnapshot(before)
+	if err != nil {
+		return nil, err
+	}
+	change := &models.ReorganizationChange{Entity: entity, EntityID: id, Name: name, Action: models.AuditActionDelete}
+	var afterFields map[string]any
+	if after != nil {
+		if afterFields, err = auditSnapshot(after); err != nil {
+			return nil, err
+		}
+		change.Action = models.AuditActionUpdate
+	}
+
+	if change.Changes = auditDiff(beforeFields, afterFields); len(change.Changes) == 0 {
+		return nil, nil
+	}
+	return change, nil
+}
+
+// department returns a department the plan has not merged away.
+func (r *reorganization) department(id uuid.UUID) (*models.Department, bool) {
+	dept, ok := r.depts[id]
+	return dept, ok && !r.removed[id]
+}
+
+// employee returns the employee as the plan left them, loading (and, when
+// the plan locks, locking) their row the first time.
+func (r *reorganization) employee(id uuid.UUID) (*models.Employee, error) {
+	if e, ok := r.employees[id]; ok {
+		return e, nil
+	}
+	find := r.employeeRepo.FindByID
+	if r.lock {
+		find = r.employeeRepo.FindByIDForUpdate
+	}
+	e, err := find(id)
+	if err != nil {
+		return nil, err
+	}
+	r.track(e)
+	return e, nil
+}
+
+// track starts following an employee loaded from the database, unless the
+// plan already follows them.
+func (r *reorganization) track(e *models.Employee) {
+	if _, ok := r.employees[e.ID]; ok {
+		return
+	}
+	r.employees[e.ID], r.originalEmployees[e.ID] = e, *e
+	r.employeeOrder = append(r.employeeOrder, e.ID)
+}
+
+// notFoundAs reports a missing row as domainErr, keeping other errors.
+func notFoundAs(err, domainErr error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domainErr
+	}
+	return err
+}
+
+func reorganizationProblem(operation *int, entityID *uuid.UUID, err error) *models.ReorganizationProblem {
+	mapped := utils.MapErrorToCustom(err)
+	return &models.ReorganizationProblem{
+		Operation: operation,
+		EntityID:  entityID,
+		Code:      mapped.ErrorCode,
+		Field:     mapped.Field,
+		Message:   err.Error(),
+	}
+}
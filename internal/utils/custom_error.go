@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+
+	"gorm.io/gorm"
 )
 
 var (
@@ -13,20 +15,39 @@ var (
 	ErrDepartmentHasEmployees       = errors.New("department has linked employees")
 	ErrDepartmentHasSubDepartments  = errors.New("department has linked sub-departments")
 	ErrManagerNotBelongToDepartment = errors.New("the manager must belong to the department they will manage")
+	ErrManagerOfAnotherDepartment   = errors.New("employee already manages another department")
 	ErrNotFound                     = errors.New("resource not found")
 	ErrInvalid                      = errors.New("provided data is invalid")
+	ErrInvalidCPF                   = errors.New("invalid CPF")
+	ErrInvalidSort                  = errors.New("sorting by this field is not supported")
+	ErrInvalidCursor                = errors.New("invalid cursor or cursor taken from a different sort")
+	ErrInvalidScope                 = errors.New("unknown or missing API key scope")
 	ErrDepartmentNotFound           = errors.New("department not found")
 	ErrCPFDuplicated                = errors.New("CPF already registered")
 	ErrRGDuplicated                 = errors.New("RG already registered")
 	ErrManagerNotFound              = errors.New("manager not found")
 	ErrManagerCannotBeDeleted       = errors.New("employee is a manager and cannot be removed")
+	ErrUnauthorized                 = errors.New("missing or invalid credentials")
+	ErrForbidden                    = errors.New("not allowed to access this resource")
+	ErrNotDeleted                   = errors.New("record is not deleted")
+	ErrEmployeeAnonymized           = errors.New("employee has been anonymized")
+	ErrDepartmentAmbiguous          = errors.New("more than one department has this name path")
+	ErrConfirmationRequired         = errors.New("moving this many employees requires a confirmation token")
+	ErrInvalidConfirmation          = errors.New("confirmation token is invalid, expired or for another move")
 )
 
 // CustomError represents a standardized error structure for the API (HTTP Response).
 type CustomError struct {
-	Code    int    `json:"-"` // HTTP Code (not serialized in JSON)
-	Message string `json:"message"`
-	Details string `json:"details,omitempty"` // Technical details (the original error .Error())
+	Code      int    `json:"-"`    // HTTP Code (not serialized in JSON)
+	ErrorCode string `json:"code"` // Stable machine-readable code (e.g. CPF_DUPLICATED)
+	Message   string `json:"message"`
+	Details   string `json:"details,omitempty"` // Technical details (the original error .Error())
+	Field     string `json:"field,omitempty"`   // Offending input field, for validation errors
+}
+
+// ErrorResponse is the envelope of every error returned by the API.
+type ErrorResponse struct {
+	Error *CustomError `json:"error"`
 }
 
 func (e *CustomError) Error() string {
@@ -38,33 +59,131 @@ func (e *CustomError) Error() string {
 
 func NewCustomError(code int, message string, details string) *CustomError {
 	return &CustomError{
-		Code:    code,
-		Message: message,
-		Details: details,
+		Code:      code,
+		ErrorCode: defaultErrorCode(code),
+		Message:   message,
+		Details:   details,
 	}
 }
 
-// MapErrorToCustom converts a business rule error (standard Go error) to a CustomError
-func MapErrorToCustom(err error) *CustomError {
-	switch {
-	case errors.Is(err, ErrParentDepartmentNotFound),
-		errors.Is(err, ErrNotFound):
-		return NewCustomError(http.StatusNotFound, "Resource not found.", err.Error())
-	}
+// domainError describes how a business rule error is exposed over HTTP.
+type domainError struct {
+	err    error
+	status int
+	code   string
+	field  string
+}
 
-	switch {
-	case errors.Is(err, ErrCycleDetected),
-		errors.Is(err, ErrDepartmentHasEmployees),
-		errors.Is(err, ErrDepartmentHasSubDepartments),
-		errors.Is(err, ErrManagerNotBelongToDepartment),
-		errors.Is(err, ErrInvalid):
-		return NewCustomError(http.StatusBadRequest, "Business rule failure or invalid data.", err.Error())
-	}
+// domainErrors maps every known error to its HTTP status and stable code.
+// The first match wins, so more specific errors come first.
+var domainErrors = []domainError{
+	{err: ErrUnauthorized, status: http.StatusUnauthorized, code: "UNAUTHORIZED"},
+	{err: ErrForbidden, status: http.StatusForbidden, code: "FORBIDDEN"},
+
+	{err: ErrEmployeeNotFound, status: http.StatusNotFound, code: "EMPLOYEE_NOT_FOUND"},
+	{err: ErrNotFound, status: http.StatusNotFound, code: "NOT_FOUND"},
+	{err: gorm.ErrRecordNotFound, status: http.StatusNotFound, code: "NOT_FOUND"},
+
+	{err: ErrInvalidCPF, status: http.StatusBadRequest, code: "INVALID_CPF", field: "cpf"},
+	{err: ErrInvalidSort, status: http.StatusBadRequest, code: "INVALID_SORT", field: "sort"},
+	{err: ErrInvalidCursor, status: http.StatusBadRequest, code: "INVALID_CURSOR"},
+	{err: ErrInvalidScope, status: http.StatusBadRequest, code: "INVALID_SCOPE", field: "scopes"},
+	{err: ErrInvalid, status: http.StatusBadRequest, code: "INVALID_DATA"},
+
+	{err: ErrCPFDuplicated, status: http.StatusConflict, code: "CPF_DUPLICATED", field: "cpf"},
+	{err: ErrRGDuplicated, status: http.StatusConflict, code: "RG_DUPLICATED", field: "rg"},
+	{err: gorm.ErrDuplicatedKey, status: http.StatusConflict, code: "DUPLICATED"},
+	{err: ErrNotDeleted, status: http.StatusConflict, code: "NOT_DELETED"},
+	{err: ErrEmployeeAnonymized, status: http.StatusConflict, code: "EMPLOYEE_ANONYMIZED"},
+
+	{err: ErrParentDepartmentNotFound, status: http.StatusUnprocessableEntity, code: "PARENT_DEPARTMENT_NOT_FOUND", field: "parent_department_id"},
+	{err: ErrDepartmentNotFound, status: http.StatusUnprocessableEntity, code: "DEPARTMENT_NOT_FOUND", field: "department_id"},
+	{err: ErrDepartmentAmbiguous, status: http.StatusUnprocessableEntity, code: "DEPARTMENT_AMBIGUOUS", field: "department"},
+	{err: ErrManagerNotFound, status: http.StatusUnprocessableEntity, code: "MANAGER_NOT_FOUND", field: "manager_id"},
+	{err: ErrCycleDetected, status: http.StatusUnprocessableEntity, code: "HIERARCHY_CYCLE", field: "parent_department_id"},
+	{err: ErrDepartmentHasEmployees, status: http.StatusUnprocessableEntity, code: "DEPARTMENT_HAS_EMPLOYEES"},
+	{err: ErrDepartmentHasSubDepartments, status: http.StatusUnprocessableEntity, code: "DEPARTMENT_HAS_SUB_DEPARTMENTS"},
+	{err: ErrManagerNotBelongToDepartment, status: http.StatusUnprocessableEntity, code: "MANAGER_NOT_IN_DEPARTMENT"},
+	{err: ErrManagerOfAnotherDepartment, status: http.StatusUnprocessableEntity, code: "MANAGER_OF_ANOTHER_DEPARTMENT", field: "manager_id"},
+	{err: ErrManagerCannotBeDeleted, status: http.StatusUnprocessableEntity, code: "MANAGER_CANNOT_BE_DELETED"},
+
+	{err: ErrConfirmationRequired, status: http.StatusPreconditionRequired, code: "CONFIRMATION_REQUIRED", field: "confirmation_token"},
+	{err: ErrInvalidConfirmation, status: http.StatusPreconditionRequired, code: "CONFIRMATION_INVALID", field: "confirmation_token"},
+}
 
+// MapErrorToCustom converts a business rule error (standard Go error) to a CustomError
+func MapErrorToCustom(err error) *CustomError {
 	var customErr *CustomError
 	if errors.As(err, &customErr) {
 		return customErr
 	}
 
+	for _, d := range domainErrors {
+		if errors.Is(err, d.err) {
+			return &CustomError{
+				Code:      d.status,
+				ErrorCode: d.code,
+				Message:   defaultMessage(d.status),
+				Details:   err.Error(),
+				Field:     d.field,
+			}
+		}
+	}
+
 	return NewCustomError(http.StatusInternalServerError, "Internal server error.", err.Error())
 }
+
+// WithStatus overrides the HTTP status err maps to, keeping its code. It is
+// meant for endpoints where a referenced resource is the addressed one, e.g.
+// a missing manager on GET /gerentes/:id is a 404 rather than a 422.
+func WithStatus(err error, status int) *CustomError {
+	mapped := *MapErrorToCustom(err)
+	mapped.Code = status
+	mapped.Message = defaultMessage(status)
+	return &mapped
+}
+
+func defaultMessage(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "Invalid request."
+	case http.StatusUnauthorized:
+		return "Authentication required."
+	case http.StatusForbidden:
+		return "Access denied."
+	case http.StatusNotFound:
+		return "Resource not found."
+	case http.StatusConflict:
+		return "Resource already exists."
+	case http.StatusUnprocessableEntity:
+		return "Business rule failure or invalid data."
+	case http.StatusInternalServerError:
+		return "Internal server error."
+	default:
+		return http.StatusText(status)
+	}
+}
+
+func defaultErrorCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "INVALID_REQUEST"
+	case http.StatusUnauthorized:
+		return "UNAUTHORIZED"
+	case http.StatusForbidden:
+		return "FORBIDDEN"
+	case http.StatusNotFound:
+		return "NOT_FOUND"
+	case http.StatusMethodNotAllowed:
+		return "METHOD_NOT_ALLOWED"
+	case http.StatusConflict:
+		return "CONFLICT"
+	case http.StatusUnprocessableEntity:
+		return "BUSINESS_RULE_VIOLATION"
+	default:
+		if status >= http.StatusInternalServerError {
+			return "INTERNAL_ERROR"
+		}
+		return "ERROR"
+	}
+}
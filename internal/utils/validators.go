@@ -62,6 +62,12 @@ func IsCPFValido(cpf string) bool {
 	return true
 }
 
+// NormalizeCPF remove a formatação do CPF, mantendo apenas os dígitos
+// (ex: "123.456.789-09" -> "12345678909").
+func NormalizeCPF(cpf string) string {
+	return removeNaoDigitos(cpf)
+}
+
 func removeNaoDigitos(s string) string {
 	var result string
 	for _, r := range s {
@@ -0,0 +1,17 @@
+-- API keys for service-to-service integrations (payroll, badges...)
+-- Only the SHA-256 of the key is stored; `prefix` identifies it in listings.
+CREATE TABLE api_keys (
+                          id UUID PRIMARY KEY,
+                          name VARCHAR(255) NOT NULL,
+                          prefix VARCHAR(16) NOT NULL,
+                          key_hash CHAR(64) NOT NULL,
+                          scopes TEXT NOT NULL, -- Space-separated, e.g. 'employees:read departments:read'
+                          expires_at TIMESTAMPTZ,
+                          last_used_at TIMESTAMPTZ,
+                          revoked_at TIMESTAMPTZ,
+
+                          created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                          updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+                          CONSTRAINT uq_api_key_hash UNIQUE(key_hash)
+);
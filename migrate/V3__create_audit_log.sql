@@ -0,0 +1,16 @@
+-- Audit log of every employee and department mutation.
+-- Rows are written in the same transaction as the change and never updated.
+CREATE TABLE audit_log (
+                           id UUID PRIMARY KEY,
+                           actor VARCHAR(255) NOT NULL, -- Token subject, or 'api-key:<id>'
+                           entity VARCHAR(32) NOT NULL, -- 'employee' or 'department'
+                           entity_id UUID NOT NULL,
+                           action VARCHAR(16) NOT NULL, -- 'create', 'update' or 'delete'
+                           changes JSONB NOT NULL DEFAULT '{}', -- {"field": {"before": ..., "after": ...}}
+
+                           created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_log_entity_id ON audit_log(entity_id);
+CREATE INDEX idx_audit_log_actor ON audit_log(actor);
+CREATE INDEX idx_audit_log_created_at ON audit_log(created_at);
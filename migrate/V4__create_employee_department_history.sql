@@ -0,0 +1,32 @@
+-- Department assignments of each employee over time, for point-in-time
+-- questions such as payroll cost allocation. Periods are half-open
+-- [effective_from, effective_to); the current one has effective_to NULL.
+CREATE TABLE employee_department_history (
+                                             id UUID PRIMARY KEY,
+                                             employee_id UUID NOT NULL,
+                                             department_id UUID NOT NULL,
+                                             effective_from TIMESTAMPTZ NOT NULL,
+                                             effective_to TIMESTAMPTZ,
+
+                                             created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+                                             CONSTRAINT fk_history_employee
+                                                 FOREIGN KEY(employee_id)
+                                                     REFERENCES employees(id),
+                                             CONSTRAINT fk_history_dept
+                                                 FOREIGN KEY(department_id)
+                                                     REFERENCES departments(id),
+                                             CONSTRAINT ck_history_period
+                                                 CHECK (effective_to IS NULL OR effective_to >= effective_from)
+);
+
+CREATE INDEX idx_history_employee ON employee_department_history(employee_id, effective_from);
+
+-- At most one current assignment per employee
+CREATE UNIQUE INDEX uq_history_current ON employee_department_history(employee_id) WHERE effective_to IS NULL;
+
+-- Existing employees start with their current department since hiring; the
+-- earlier transfers were not recorded.
+INSERT INTO employee_department_history (id, employee_id, department_id, effective_from, effective_to)
+SELECT uuid_generate_v4(), id, department_id, created_at, deleted_at
+FROM employees;
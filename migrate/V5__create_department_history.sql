@@ -0,0 +1,32 @@
+-- Versions of each department row, to rebuild the org chart as it was on any
+-- past date. Periods are half-open [valid_from, valid_to); the current
+-- version has valid_to NULL and a removed department has none.
+CREATE TABLE department_history (
+                                    id UUID PRIMARY KEY,
+                                    department_id UUID NOT NULL,
+                                    name VARCHAR(255) NOT NULL,
+                                    manager_id UUID,
+                                    parent_department_id UUID,
+                                    valid_from TIMESTAMPTZ NOT NULL,
+                                    valid_to TIMESTAMPTZ,
+
+                                    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+                                    CONSTRAINT fk_dept_history_dept
+                                        FOREIGN KEY(department_id)
+                                            REFERENCES departments(id),
+                                    CONSTRAINT ck_dept_history_period
+                                        CHECK (valid_to IS NULL OR valid_to >= valid_from)
+);
+
+CREATE INDEX idx_dept_history_dept ON department_history(department_id, valid_from);
+CREATE INDEX idx_dept_history_parent ON department_history(parent_department_id);
+
+-- At most one current version per department
+CREATE UNIQUE INDEX uq_dept_history_current ON department_history(department_id) WHERE valid_to IS NULL;
+
+-- Existing departments start with their current state since creation; the
+-- earlier changes were not recorded.
+INSERT INTO department_history (id, department_id, name, manager_id, parent_department_id, valid_from, valid_to)
+SELECT uuid_generate_v4(), id, name, manager_id, parent_department_id, created_at, deleted_at
+FROM departments;
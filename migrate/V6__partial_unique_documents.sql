@@ -0,0 +1,12 @@
+-- Employees are soft deleted, so CPF and RG only need to be unique among
+-- the active ones. A removed employee who is hired again gets their old
+-- record back (see the rehire flow in CreateEmployee); the partial indexes
+-- keep the database from rejecting documents held only by removed rows.
+-- The index names are the former constraint names, which the application
+-- matches to report CPF_DUPLICATED and RG_DUPLICATED.
+ALTER TABLE employees
+    DROP CONSTRAINT uq_cpf,
+    DROP CONSTRAINT uq_rg;
+
+CREATE UNIQUE INDEX uq_cpf ON employees(cpf) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX uq_rg ON employees(rg) WHERE deleted_at IS NULL;
@@ -0,0 +1,4 @@
+-- Set when an employee's personal data is erased under LGPD. The row stays,
+-- with a tombstone in place of the CPF, so history, departments and audit
+-- entries keep pointing at it.
+ALTER TABLE employees ADD COLUMN anonymized_at TIMESTAMPTZ;
@@ -0,0 +1,62 @@
+package handlers_test
+
+import (
+	"ManageEmployeesandDepartments/internal/auth"
+	"ManageEmployeesandDepartments/internal/handlers"
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/goleak"
+)
+
+func TestEmployeeHandler_Anonymize(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	testCases := []struct {
+		name           string
+		idParam        string
+		query          string
+		anonymizeError error
+		expectedStatus int
+		expectedDryRun bool
+	}{
+		{name: "anonimiza", idParam: uuid.New().String(), expectedStatus: http.StatusOK},
+		{name: "simulação", idParam: uuid.New().String(), query: "?dry_run=true", expectedStatus: http.StatusOK, expectedDryRun: true},
+		{name: "dry_run inválido", idParam: uuid.New().String(), query: "?dry_run=talvez", expectedStatus: http.StatusBadRequest},
+		{name: "ID inválido", idParam: "invalid-uuid", expectedStatus: http.StatusBadRequest},
+		{name: "colaborador não encontrado", idParam: uuid.New().String(), anonymizeError: utils.ErrEmployeeNotFound, expectedStatus: http.StatusNotFound},
+		{name: "colaborador ativo", idParam: uuid.New().String(), anonymizeError: utils.ErrNotDeleted, expectedStatus: http.StatusConflict},
+		{name: "já anonimizado", idParam: uuid.New().String(), anonymizeError: utils.ErrEmployeeAnonymized, expectedStatus: http.StatusConflict},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := &MockEmployeeService{anonymizeError: tc.anonymizeError}
+			if tc.anonymizeError == nil {
+				mockService.anonymizeResult = &models.AnonymizationReport{DryRun: tc.expectedDryRun}
+			}
+
+			handler := handlers.NewEmployeeHandler(mockService)
+			router := setupRouter()
+			router.POST("/colaboradores/:id/anonimizar", func(c *gin.Context) {
+				auth.SetPrincipal(c, &auth.Principal{Subject: "dpo@empresa.com"})
+			}, handler.Anonymize)
+
+			req, _ := http.NewRequest("POST", "/colaboradores/"+tc.idParam+"/anonimizar"+tc.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code == http.StatusOK && (mockService.dryRun != tc.expectedDryRun || mockService.actor != "dpo@empresa.com") {
+				t.Errorf("Expected dry_run %v by the caller, got %v by %q", tc.expectedDryRun, mockService.dryRun, mockService.actor)
+			}
+		})
+	}
+}
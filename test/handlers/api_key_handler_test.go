@@ -0,0 +1,151 @@
+package handlers_test
+
+import (
+	"ManageEmployeesandDepartments/internal/handlers"
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/utils"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/goleak"
+	"gorm.io/gorm"
+)
+
+// MockAPIKeyService simulates the API key service
+type MockAPIKeyService struct {
+	createResult *models.APIKey
+	createKey    string
+	createError  error
+	listResult   []*models.APIKey
+	revokeError  error
+}
+
+func (m *MockAPIKeyService) CreateAPIKey(name string, scopes []string, expiresAt *time.Time) (*models.APIKey, string, error) {
+	return m.createResult, m.createKey, m.createError
+}
+
+func (m *MockAPIKeyService) ListAPIKeys() ([]*models.APIKey, error) {
+	return m.listResult, nil
+}
+
+func (m *MockAPIKeyService) RevokeAPIKey(id uuid.UUID) error {
+	return m.revokeError
+}
+
+func (m *MockAPIKeyService) AuthenticateAPIKey(key string) (*models.APIKey, error) {
+	return nil, utils.ErrUnauthorized
+}
+
+func TestAPIKeyHandler_Create(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	testCases := []struct {
+		name           string
+		body           string
+		mockSetup      func(*MockAPIKeyService)
+		expectedStatus int
+	}{
+		{
+			name: "sucesso retorna a chave uma única vez",
+			body: `{"name":"folha","scopes":["employees:read"]}`,
+			mockSetup: func(ms *MockAPIKeyService) {
+				ms.createResult = &models.APIKey{ID: uuid.New(), Name: "folha", Prefix: "emk_abcdefgh", KeyHash: "segredo", Scopes: models.Scopes{models.ScopeEmployeesRead}}
+				ms.createKey = "emk_abcdefgh-resto"
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "sem escopos",
+			body:           `{"name":"folha","scopes":[]}`,
+			mockSetup:      func(ms *MockAPIKeyService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "escopo desconhecido",
+			body:           `{"name":"folha","scopes":["tudo"]}`,
+			mockSetup:      func(ms *MockAPIKeyService) { ms.createError = utils.ErrInvalidScope },
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := &MockAPIKeyService{}
+			tc.mockSetup(mockService)
+
+			handler := handlers.NewAPIKeyHandler(mockService)
+			router := setupRouter()
+			router.POST("/chaves-api", handler.Create)
+
+			req, _ := http.NewRequest("POST", "/chaves-api", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusCreated {
+				return
+			}
+
+			var body map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if body["key"] != mockService.createKey || body["prefix"] != "emk_abcdefgh" {
+				t.Errorf("Expected key and prefix in response, got %v", body)
+			}
+			if _, leaked := body["key_hash"]; leaked || bytes.Contains(w.Body.Bytes(), []byte("segredo")) {
+				t.Errorf("Key hash must not be returned: %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAPIKeyHandler_ListAndRevoke(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	mockService := &MockAPIKeyService{}
+	handler := handlers.NewAPIKeyHandler(mockService)
+	router := setupRouter()
+	router.GET("/chaves-api", handler.List)
+	router.DELETE("/chaves-api/:id", handler.Revoke)
+
+	req, _ := http.NewRequest("GET", "/chaves-api", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "[]" {
+		t.Errorf("Expected empty list, got %d %s", w.Code, w.Body.String())
+	}
+
+	testCases := []struct {
+		name           string
+		idParam        string
+		revokeError    error
+		expectedStatus int
+	}{
+		{name: "sucesso", idParam: uuid.New().String(), expectedStatus: http.StatusNoContent},
+		{name: "ID inválido", idParam: "invalid-uuid", expectedStatus: http.StatusBadRequest},
+		{name: "chave não encontrada", idParam: uuid.New().String(), revokeError: gorm.ErrRecordNotFound, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService.revokeError = tc.revokeError
+
+			req, _ := http.NewRequest("DELETE", "/chaves-api/"+tc.idParam, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tc.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
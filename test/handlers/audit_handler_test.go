@@ -0,0 +1,105 @@
+package handlers_test
+
+import (
+	"ManageEmployeesandDepartments/internal/auth"
+	"ManageEmployeesandDepartments/internal/handlers"
+	"ManageEmployeesandDepartments/internal/models"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/goleak"
+)
+
+// MockAuditService simulates the audit service
+type MockAuditService struct {
+	filter models.AuditFilter
+}
+
+func (m *MockAuditService) ListAuditEntries(filter models.AuditFilter, page, pageSize int) (*models.AuditListResponse, error) {
+	m.filter = filter
+	return models.NewPage[*models.AuditEntry](nil, page, pageSize, 0), nil
+}
+
+func TestAuditHandler_List(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	entityID := uuid.New()
+
+	testCases := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		check          func(*testing.T, models.AuditFilter)
+	}{
+		{
+			name:           "todos os filtros",
+			query:          "?entity_id=" + entityID.String() + "&actor=rh-1&from=2025-03-01T00:00:00Z&to=2025-04-01T00:00:00-03:00",
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, f models.AuditFilter) {
+				if f.EntityID == nil || *f.EntityID != entityID || f.Actor == nil || *f.Actor != "rh-1" {
+					t.Errorf("Unexpected filter: %+v", f)
+				}
+				if f.From == nil || !f.From.Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)) {
+					t.Errorf("Unexpected from: %v", f.From)
+				}
+				if f.To == nil || !f.To.Equal(time.Date(2025, 4, 1, 3, 0, 0, 0, time.UTC)) {
+					t.Errorf("Unexpected to: %v", f.To)
+				}
+			},
+		},
+		{name: "sem filtros", expectedStatus: http.StatusOK},
+		{name: "entity_id inválido", query: "?entity_id=abc", expectedStatus: http.StatusBadRequest},
+		{name: "data inválida", query: "?from=01/03/2025", expectedStatus: http.StatusBadRequest},
+		{name: "período invertido", query: "?from=2025-04-01T00:00:00Z&to=2025-03-01T00:00:00Z", expectedStatus: http.StatusBadRequest},
+		{name: "page_size acima do máximo", query: "?page_size=101", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := &MockAuditService{}
+			handler := handlers.NewAuditHandler(mockService)
+			router := setupRouter()
+			router.GET("/auditoria", handler.List)
+
+			req, _ := http.NewRequest("GET", "/auditoria"+tc.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.expectedStatus, w.Code, w.Body.String())
+			}
+			if tc.check != nil {
+				tc.check(t, mockService.filter)
+			}
+		})
+	}
+}
+
+func TestHandlers_RegistramAtorNaAuditoria(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	mockService := &MockEmployeeService{createResult: &models.Employee{ID: uuid.New()}}
+	handler := handlers.NewEmployeeHandler(mockService)
+	router := setupRouter()
+	router.POST("/colaboradores", func(c *gin.Context) {
+		auth.SetPrincipal(c, &auth.Principal{Subject: "rh-admin-1", Roles: []auth.Role{auth.RoleHRAdmin}})
+	}, handler.Create)
+
+	body := `{"name":"Maria","cpf":"12345678909","department_id":"` + uuid.New().String() + `"}`
+	req, _ := http.NewRequest("POST", "/colaboradores", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if mockService.actor != "rh-admin-1" {
+		t.Errorf("Expected actor rh-admin-1, got %q", mockService.actor)
+	}
+}
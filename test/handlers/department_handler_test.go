@@ -1,15 +1,19 @@
 package handlers_test
 
 import (
+	"ManageEmployeesandDepartments/internal/auth"
 	"ManageEmployeesandDepartments/internal/handlers"
 	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/services"
 	"ManageEmployeesandDepartments/internal/utils"
 	"bytes"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"go.uber.org/goleak"
 	"gorm.io/gorm"
@@ -24,36 +28,100 @@ type MockDepartmentService struct {
 	updateResult                  *models.Department
 	updateError                   error
 	deleteError                   error
-	listResult                    []*models.Department
+	listResult                    *models.DepartmentListResponse
 	listError                     error
-	getSubordinateEmployeesResult []*models.Employee
+	getSubordinateEmployeesResult *models.SubordinatesResponse
 	getSubordinateEmployeesError  error
+	actor                         string
+	asOf                          *time.Time
+	listDeletedResult             *models.DeletedDepartmentListResponse
+	restoreResult                 *models.Department
+	restoreError                  error
+	exportRows                    []*models.DepartmentExportRow
+	exportError                   error
+	exportFilter                  models.DepartmentFilter
+	reorganizeOps                 []models.ReorganizationOperation
+	reorganizeResult              *models.ReorganizationReport
+	reorganizeError               error
+	dryRun                        bool
+	mergeTargetID                 uuid.UUID
+	mergeManagerID                *uuid.UUID
+	mergeResult                   *models.Department
+	mergeError                    error
+	confirmation                  string
+	previewParentID               uuid.UUID
+	previewResult                 *models.MovePreview
+	previewError                  error
+}
+
+func (m *MockDepartmentService) WithActor(actor string) services.DepartmentService {
+	m.actor = actor
+	return m
 }
 
 func (m *MockDepartmentService) CreateDepartment(name string, managerID uuid.UUID, parentID *uuid.UUID) (*models.Department, error) {
 	return m.createResult, m.createError
 }
 
-func (m *MockDepartmentService) GetDepartmentWithTree(id uuid.UUID) (*models.Department, error) {
+func (m *MockDepartmentService) GetDepartmentWithTree(id uuid.UUID, maxDepth int, asOf *time.Time) (*models.Department, error) {
+	m.asOf = asOf
 	return m.getResult, m.getError
 }
 
-func (m *MockDepartmentService) UpdateDepartment(id uuid.UUID, name *string, managerID *uuid.UUID, parentID *uuid.UUID) (*models.Department, error) {
+func (m *MockDepartmentService) UpdateDepartment(id uuid.UUID, name *string, managerID *uuid.UUID, parentID *uuid.UUID, confirmation string) (*models.Department, error) {
+	m.confirmation = confirmation
 	return m.updateResult, m.updateError
 }
 
+func (m *MockDepartmentService) PreviewMove(id, parentID uuid.UUID) (*models.MovePreview, error) {
+	m.previewParentID = parentID
+	return m.previewResult, m.previewError
+}
+
 func (m *MockDepartmentService) DeleteDepartment(id uuid.UUID) error {
 	return m.deleteError
 }
 
-func (m *MockDepartmentService) ListDepartments(name, managerName *string, parentID *uuid.UUID, page, pageSize int) ([]*models.Department, error) {
+func (m *MockDepartmentService) ListDeletedDepartments(page, pageSize int) (*models.DeletedDepartmentListResponse, error) {
+	return m.listDeletedResult, m.listError
+}
+
+func (m *MockDepartmentService) RestoreDepartment(id uuid.UUID) (*models.Department, error) {
+	return m.restoreResult, m.restoreError
+}
+
+func (m *MockDepartmentService) ExportDepartments(filter models.DepartmentFilter, sort models.Sort, fn func(*models.DepartmentExportRow) error) error {
+	m.exportFilter = filter
+	for _, row := range m.exportRows {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return m.exportError
+}
+
+func (m *MockDepartmentService) Reorganize(ops []models.ReorganizationOperation, dryRun bool) (*models.ReorganizationReport, error) {
+	m.reorganizeOps, m.dryRun = ops, dryRun
+	return m.reorganizeResult, m.reorganizeError
+}
+
+func (m *MockDepartmentService) MergeDepartment(id, targetID uuid.UUID, managerID *uuid.UUID, confirmation string) (*models.Department, error) {
+	m.mergeTargetID, m.mergeManagerID, m.confirmation = targetID, managerID, confirmation
+	return m.mergeResult, m.mergeError
+}
+
+func (m *MockDepartmentService) ListDepartments(name, managerName *string, parentID *uuid.UUID, sort models.Sort, page, pageSize int) (*models.DepartmentListResponse, error) {
 	return m.listResult, m.listError
 }
 
-func (m *MockDepartmentService) GetSubordinateEmployeesRecursively(managerID uuid.UUID) ([]*models.Employee, error) {
+func (m *MockDepartmentService) GetSubordinateEmployeesRecursively(managerID uuid.UUID, filter models.SubordinatesFilter) (*models.SubordinatesResponse, error) {
 	return m.getSubordinateEmployeesResult, m.getSubordinateEmployeesError
 }
 
+func (m *MockDepartmentService) ManagedSubtreeIDs(managerID uuid.UUID) ([]uuid.UUID, error) {
+	return nil, nil
+}
+
 func TestDepartamentoHandler_Create(t *testing.T) {
 	defer goleak.VerifyNone(t)
 
@@ -117,7 +185,7 @@ func TestDepartamentoHandler_Create(t *testing.T) {
 			mockService := &MockDepartmentService{}
 			tc.mockSetup(mockService)
 
-			handler := handlers.NewDepartmentHandler(mockService)
+			handler := handlers.NewDepartamentoHandler(mockService)
 			router := setupRouter()
 			router.POST("/departamentos", handler.Create)
 
@@ -187,6 +255,34 @@ func TestDepartamentoHandler_GetByID(t *testing.T) {
 			},
 			expectedStatus: http.StatusNotFound,
 		},
+		{
+			name:    "sucesso com max_depth",
+			idParam: uuid.New().String() + "?max_depth=2",
+			mockSetup: func(ms *MockDepartmentService) {
+				ms.getResult = &models.Department{ID: uuid.New(), Name: "TI"}
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "erro max_depth inválido",
+			idParam:        uuid.New().String() + "?max_depth=0",
+			mockSetup:      func(ms *MockDepartmentService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "sucesso com as_of",
+			idParam: uuid.New().String() + "?as_of=2025-01-01",
+			mockSetup: func(ms *MockDepartmentService) {
+				ms.getResult = &models.Department{ID: uuid.New(), Name: "TI"}
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "erro as_of inválido",
+			idParam:        uuid.New().String() + "?as_of=ontem",
+			mockSetup:      func(ms *MockDepartmentService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -195,7 +291,7 @@ func TestDepartamentoHandler_GetByID(t *testing.T) {
 			mockService := &MockDepartmentService{}
 			tc.mockSetup(mockService)
 
-			handler := handlers.NewDepartmentHandler(mockService)
+			handler := handlers.NewDepartamentoHandler(mockService)
 			router := setupRouter()
 			router.GET("/departamentos/:id", handler.GetByID)
 
@@ -212,6 +308,121 @@ func TestDepartamentoHandler_GetByID(t *testing.T) {
 	}
 }
 
+func TestDepartamentoHandler_GetByID_AsOf(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	mockService := &MockDepartmentService{getResult: &models.Department{ID: uuid.New(), Name: "TI"}}
+	handler := handlers.NewDepartamentoHandler(mockService)
+	router := setupRouter()
+	router.GET("/departamentos/:id", handler.GetByID)
+
+	testCases := []struct {
+		query    string
+		expected *time.Time
+	}{
+		{query: "", expected: nil},
+		{query: "?as_of=2025-01-01", expected: ptrTime(time.Date(2025, 1, 1, 23, 59, 59, 999999000, time.UTC))},
+		{query: "?as_of=2025-01-01T09:00:00-03:00", expected: ptrTime(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))},
+	}
+
+	for _, tc := range testCases {
+		req, _ := http.NewRequest("GET", "/departamentos/"+uuid.New().String()+tc.query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("%q: expected status 200, got %d", tc.query, w.Code)
+		}
+		if (tc.expected == nil) != (mockService.asOf == nil) || (tc.expected != nil && !tc.expected.Equal(*mockService.asOf)) {
+			t.Errorf("%q: expected as_of %v, got %v", tc.query, tc.expected, mockService.asOf)
+		}
+	}
+}
+
+func TestDepartamentoHandler_GerentesForaDoEscopo(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	visivel := uuid.New()
+	rg := "12.345.678-9"
+	tree := func() *models.Department {
+		fora := &models.Employee{ID: uuid.New(), Name: "Ana", CPF: "123.456.789-09", RG: &rg, DepartmentID: uuid.New()}
+		dentro := &models.Employee{ID: uuid.New(), Name: "Bruno", CPF: "987.654.321-00", DepartmentID: visivel}
+		return &models.Department{
+			ID: uuid.New(), Name: "Diretoria", ManagerID: &fora.ID, Manager: fora,
+			SubDepartments: []*models.Department{
+				{ID: visivel, Name: "TI", ManagerID: &dentro.ID, Manager: dentro},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name   string
+		method string
+		path   string
+		body   func(*testing.T, []byte) (outside, inside map[string]any)
+	}{
+		{
+			name: "árvore", method: "GET", path: "/departamentos/" + uuid.New().String(),
+			body: func(t *testing.T, raw []byte) (map[string]any, map[string]any) {
+				var resp struct {
+					Manager        map[string]any
+					SubDepartments []struct{ Manager map[string]any } `json:"sub_departments"`
+				}
+				if err := json.Unmarshal(raw, &resp); err != nil || len(resp.SubDepartments) != 1 {
+					t.Fatalf("unexpected body %s", raw)
+				}
+				return resp.Manager, resp.SubDepartments[0].Manager
+			},
+		},
+		{
+			name: "listagem", method: "POST", path: "/departamentos/listar",
+			body: func(t *testing.T, raw []byte) (map[string]any, map[string]any) {
+				var resp struct {
+					Items []struct{ Manager map[string]any }
+				}
+				if err := json.Unmarshal(raw, &resp); err != nil || len(resp.Items) != 2 {
+					t.Fatalf("unexpected body %s", raw)
+				}
+				return resp.Items[0].Manager, resp.Items[1].Manager
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := tree()
+			mockService := &MockDepartmentService{
+				getResult:  root,
+				listResult: models.NewPage([]*models.Department{root, root.SubDepartments[0]}, 1, 10, 2),
+			}
+			handler := handlers.NewDepartamentoHandler(mockService)
+			router := setupRouter()
+			router.Use(func(c *gin.Context) { auth.SetScope(c, []uuid.UUID{visivel}) })
+			router.GET("/departamentos/:id", handler.GetByID)
+			router.POST("/departamentos/listar", handler.List)
+
+			req, _ := http.NewRequest(tc.method, tc.path, bytes.NewBufferString("{}"))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+			outside, inside := tc.body(t, w.Body.Bytes())
+			if len(outside) != 2 || outside["name"] != "Ana" || outside["cpf"] != nil || outside["rg"] != nil {
+				t.Errorf("expected only the ID and name of the manager outside the scope, got %v", outside)
+			}
+			if inside["cpf"] != "987.654.321-00" {
+				t.Errorf("expected the full manager inside the scope, got %v", inside)
+			}
+		})
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
+
 func TestDepartamentoHandler_Update(t *testing.T) {
 	defer goleak.VerifyNone(t)
 
@@ -282,7 +493,7 @@ func TestDepartamentoHandler_Update(t *testing.T) {
 			mockService := &MockDepartmentService{}
 			tc.mockSetup(mockService)
 
-			handler := handlers.NewDepartmentHandler(mockService)
+			handler := handlers.NewDepartamentoHandler(mockService)
 			router := setupRouter()
 			router.PUT("/departamentos/:id", handler.Update)
 
@@ -361,7 +572,7 @@ func TestDepartamentoHandler_Delete(t *testing.T) {
 			mockService := &MockDepartmentService{}
 			tc.mockSetup(mockService)
 
-			handler := handlers.NewDepartmentHandler(mockService)
+			handler := handlers.NewDepartamentoHandler(mockService)
 			router := setupRouter()
 			router.DELETE("/departamentos/:id", handler.Delete)
 
@@ -386,6 +597,9 @@ func TestDepartamentoHandler_List(t *testing.T) {
 		requestBody    models.ListDepartmentsDTO
 		mockSetup      func(*MockDepartmentService)
 		expectedStatus int
+		expectedItems  int
+		expectedTotal  int64
+		expectedPages  int
 	}{
 		{
 			name: "sucesso ao listar departamentos",
@@ -398,10 +612,13 @@ func TestDepartamentoHandler_List(t *testing.T) {
 					{ID: uuid.New(), Name: "TI"},
 					{ID: uuid.New(), Name: "RH"},
 				}
-				ms.listResult = departamentos
+				ms.listResult = models.NewPage(departamentos, 1, 10, 12)
 				ms.listError = nil
 			},
 			expectedStatus: http.StatusOK,
+			expectedItems:  2,
+			expectedTotal:  12,
+			expectedPages:  2,
 		},
 		{
 			name:        "sucesso com lista vazia",
@@ -412,6 +629,34 @@ func TestDepartamentoHandler_List(t *testing.T) {
 			},
 			expectedStatus: http.StatusOK,
 		},
+		{
+			name: "campo de ordenação não permitido",
+			requestBody: models.ListDepartmentsDTO{
+				Sort: "budget",
+			},
+			mockSetup: func(ms *MockDepartmentService) {
+				ms.listError = utils.ErrInvalidSort
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "direção de ordenação inválida",
+			requestBody: models.ListDepartmentsDTO{
+				Sort:  "name",
+				Order: "up",
+			},
+			mockSetup:      func(ms *MockDepartmentService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "page_size acima do máximo",
+			requestBody: models.ListDepartmentsDTO{
+				Page:     1,
+				PageSize: models.MaxPageSize + 1,
+			},
+			mockSetup:      func(ms *MockDepartmentService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -420,7 +665,7 @@ func TestDepartamentoHandler_List(t *testing.T) {
 			mockService := &MockDepartmentService{}
 			tc.mockSetup(mockService)
 
-			handler := handlers.NewDepartmentHandler(mockService)
+			handler := handlers.NewDepartamentoHandler(mockService)
 			router := setupRouter()
 			router.POST("/departamentos/listar", handler.List)
 
@@ -440,11 +685,17 @@ func TestDepartamentoHandler_List(t *testing.T) {
 			}
 
 			if tc.expectedStatus == http.StatusOK {
-				var response []models.Department
+				var response models.DepartmentListResponse
 				err := json.Unmarshal(w.Body.Bytes(), &response)
 				if err != nil {
 					t.Errorf("Failed to unmarshal response: %v", err)
 				}
+				if response.Items == nil || len(response.Items) != tc.expectedItems {
+					t.Errorf("Expected %d items, got %v", tc.expectedItems, response.Items)
+				}
+				if response.Total != tc.expectedTotal || response.TotalPages != tc.expectedPages {
+					t.Errorf("Expected total %d in %d pages, got %d in %d", tc.expectedTotal, tc.expectedPages, response.Total, response.TotalPages)
+				}
 			}
 		})
 	}
@@ -460,7 +711,7 @@ func BenchmarkDepartamentoHandler_Create(b *testing.B) {
 		createError: nil,
 	}
 
-	handler := handlers.NewDepartmentHandler(mockService)
+	handler := handlers.NewDepartamentoHandler(mockService)
 	router := setupRouter()
 	router.POST("/departamentos", handler.Create)
 
@@ -0,0 +1,123 @@
+package handlers_test
+
+import (
+	"ManageEmployeesandDepartments/internal/handlers"
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/utils"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/goleak"
+	"gorm.io/gorm"
+)
+
+func TestDepartamentoHandler_PreviewMove(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	parentID := uuid.New()
+	testCases := []struct {
+		name           string
+		idParam        string
+		query          string
+		previewError   error
+		expectedStatus int
+		expectedParent uuid.UUID
+	}{
+		{name: "prévia para novo superior", idParam: uuid.New().String(), query: "?parent_department_id=" + parentID.String(), expectedStatus: http.StatusOK, expectedParent: parentID},
+		{name: "prévia para raiz", idParam: uuid.New().String(), query: "?parent_department_id=" + uuid.Nil.String(), expectedStatus: http.StatusOK},
+		{name: "ID inválido", idParam: "invalid-uuid", query: "?parent_department_id=" + parentID.String(), expectedStatus: http.StatusBadRequest},
+		{name: "sem superior", idParam: uuid.New().String(), expectedStatus: http.StatusBadRequest},
+		{name: "superior inválido", idParam: uuid.New().String(), query: "?parent_department_id=TI", expectedStatus: http.StatusBadRequest},
+		{name: "departamento não encontrado", idParam: uuid.New().String(), query: "?parent_department_id=" + parentID.String(), previewError: gorm.ErrRecordNotFound, expectedStatus: http.StatusNotFound},
+		{name: "ciclo", idParam: uuid.New().String(), query: "?parent_department_id=" + parentID.String(), previewError: utils.ErrCycleDetected, expectedStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := &MockDepartmentService{previewError: tc.previewError}
+			if tc.previewError == nil {
+				mockService.previewResult = &models.MovePreview{Headcount: 12, ConfirmationRequired: true, ConfirmationToken: "token"}
+			}
+
+			handler := handlers.NewDepartamentoHandler(mockService)
+			router := setupRouter()
+			router.GET("/departamentos/:id/previa-movimentacao", handler.PreviewMove)
+
+			req, _ := http.NewRequest("GET", "/departamentos/"+tc.idParam+"/previa-movimentacao"+tc.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			if mockService.previewParentID != tc.expectedParent {
+				t.Errorf("Expected the preview under %s, got %s", tc.expectedParent, mockService.previewParentID)
+			}
+			var preview models.MovePreview
+			if err := json.Unmarshal(w.Body.Bytes(), &preview); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if preview.Headcount != 12 || preview.ConfirmationToken != "token" {
+				t.Errorf("Unexpected preview: %+v", preview)
+			}
+		})
+	}
+}
+
+func TestDepartamentoHandler_Update_Confirmacao(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	testCases := []struct {
+		name           string
+		body           string
+		updateError    error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "token repassado ao serviço", body: `{"parent_department_id":"` + uuid.New().String() + `","confirmation_token":"token"}`, expectedStatus: http.StatusOK},
+		{name: "confirmação exigida", body: `{"parent_department_id":"` + uuid.New().String() + `"}`, updateError: utils.ErrConfirmationRequired, expectedStatus: http.StatusPreconditionRequired, expectedCode: "CONFIRMATION_REQUIRED"},
+		{name: "token inválido", body: `{"parent_department_id":"` + uuid.New().String() + `","confirmation_token":"token"}`, updateError: utils.ErrInvalidConfirmation, expectedStatus: http.StatusPreconditionRequired, expectedCode: "CONFIRMATION_INVALID"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := &MockDepartmentService{updateError: tc.updateError}
+			if tc.updateError == nil {
+				mockService.updateResult = &models.Department{ID: uuid.New(), Name: "TI"}
+			}
+
+			handler := handlers.NewDepartamentoHandler(mockService)
+			router := setupRouter()
+			router.PUT("/departamentos/:id", handler.Update)
+
+			req, _ := http.NewRequest("PUT", "/departamentos/"+uuid.New().String(), strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.expectedStatus, w.Code, w.Body.String())
+			}
+			if tc.expectedCode != "" {
+				var errResp utils.ErrorResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &errResp); err != nil {
+					t.Fatalf("Failed to decode error: %v", err)
+				}
+				if errResp.Error.ErrorCode != tc.expectedCode || errResp.Error.Field != "confirmation_token" {
+					t.Errorf("Expected code %s on confirmation_token, got %+v", tc.expectedCode, errResp.Error)
+				}
+				return
+			}
+			if mockService.confirmation != "token" {
+				t.Errorf("Expected the token to reach the service, got %q", mockService.confirmation)
+			}
+		})
+	}
+}
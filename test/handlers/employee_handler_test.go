@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"ManageEmployeesandDepartments/internal/auth"
 	"ManageEmployeesandDepartments/internal/handlers"
 	"ManageEmployeesandDepartments/internal/models"
 	"ManageEmployeesandDepartments/internal/services"
@@ -25,8 +26,30 @@ type MockEmployeeService struct {
 	updateResult *models.Employee
 	updateError  error
 	deleteError  error
-	listResult   []*models.Employee
+	listResult   *models.EmployeeListResponse
+	cursorResult *models.EmployeeCursorPage
 	listError    error
+	actor        string
+	historyResult []*models.DepartmentAssignment
+	historyError  error
+	listDeletedResult *models.DeletedEmployeeListResponse
+	restoreResult     *models.Employee
+	restoreError      error
+	anonymizeResult   *models.AnonymizationReport
+	anonymizeError    error
+	dryRun            bool
+	importRows        []*models.EmployeeImportRow
+	importOptions     models.ImportOptions
+	importError       error
+	exportRows        []*models.EmployeeExportRow
+	exportError       error
+	exportFilter      models.EmployeeFilter
+	exportSort        models.Sort
+}
+
+func (m *MockEmployeeService) WithActor(actor string) services.EmployeeService {
+	m.actor = actor
+	return m
 }
 
 func (m *MockEmployeeService) CreateEmployee(name string, cpf string, rg *string, departmentID uuid.UUID) (*models.Employee, error) {
@@ -45,10 +68,49 @@ func (m *MockEmployeeService) DeleteEmployee(id uuid.UUID) error {
 	return m.deleteError
 }
 
-func (m *MockEmployeeService) ListEmployees(name *string, cpf *string, rg *string, deptoID *uuid.UUID, pagina, tamanhoPagina int) ([]*models.Employee, error) {
+func (m *MockEmployeeService) GetDepartmentHistory(id uuid.UUID) ([]*models.DepartmentAssignment, error) {
+	return m.historyResult, m.historyError
+}
+
+func (m *MockEmployeeService) ListDeletedEmployees(page, pageSize int) (*models.DeletedEmployeeListResponse, error) {
+	return m.listDeletedResult, m.listError
+}
+
+func (m *MockEmployeeService) RestoreEmployee(id uuid.UUID) (*models.Employee, error) {
+	return m.restoreResult, m.restoreError
+}
+
+func (m *MockEmployeeService) AnonymizeEmployee(id uuid.UUID, dryRun bool) (*models.AnonymizationReport, error) {
+	m.dryRun = dryRun
+	return m.anonymizeResult, m.anonymizeError
+}
+
+func (m *MockEmployeeService) ImportEmployees(rows []*models.EmployeeImportRow, opts models.ImportOptions) (*models.ImportReport, error) {
+	m.importRows, m.importOptions = rows, opts
+	if m.importError != nil {
+		return nil, m.importError
+	}
+	return &models.ImportReport{Mode: opts.Mode, DryRun: opts.DryRun, Total: len(rows)}, nil
+}
+
+func (m *MockEmployeeService) ListEmployees(filter models.EmployeeFilter, sort models.Sort, pagina, tamanhoPagina int) (*models.EmployeeListResponse, error) {
 	return m.listResult, m.listError
 }
 
+func (m *MockEmployeeService) ExportEmployees(filter models.EmployeeFilter, sort models.Sort, fn func(*models.EmployeeExportRow) error) error {
+	m.exportFilter, m.exportSort = filter, sort
+	for _, row := range m.exportRows {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return m.exportError
+}
+
+func (m *MockEmployeeService) ListEmployeesByCursor(filter models.EmployeeFilter, sort models.Sort, after, before *string, tamanhoPagina int) (*models.EmployeeCursorPage, error) {
+	return m.cursorResult, m.listError
+}
+
 func setupRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	return gin.New()
@@ -119,6 +181,20 @@ func TestColaboradorHandler_Create(t *testing.T) {
 			expectedStatus:     http.StatusConflict,
 			expectedErrorField: "error",
 		},
+		{
+			name: "erro CPF inválido",
+			requestBody: models.CreateEmployeeDTO{
+				Name:         "João Silva",
+				CPF:          "123.456.789-00",
+				DepartmentID: uuid.New(),
+			},
+			mockSetup: func(ms *MockEmployeeService) {
+				ms.createResult = nil
+				ms.createError = utils.ErrInvalidCPF
+			},
+			expectedStatus:     http.StatusBadRequest,
+			expectedErrorField: "error",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -318,3 +394,125 @@ func BenchmarkColaboradorHandler_Create(b *testing.B) {
 		router.ServeHTTP(w, req)
 	}
 }
+
+func TestColaboradorHandler_List(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	next := "cursor"
+
+	testCases := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func(*MockEmployeeService)
+		expectedStatus int
+		expectedFields []string
+	}{
+		{
+			name:        "paginação por offset",
+			requestBody: `{"page": 1, "page_size": 10}`,
+			mockSetup: func(ms *MockEmployeeService) {
+				ms.listResult = models.NewPage([]*models.Employee{{ID: uuid.New(), Name: "Ana"}}, 1, 10, 1)
+			},
+			expectedStatus: http.StatusOK,
+			expectedFields: []string{"items", "total", "total_pages"},
+		},
+		{
+			name:        "paginação por cursor",
+			requestBody: `{"after": "", "page_size": 1}`,
+			mockSetup: func(ms *MockEmployeeService) {
+				ms.cursorResult = &models.EmployeeCursorPage{
+					Items:      []*models.Employee{{ID: uuid.New(), Name: "Ana"}},
+					PageSize:   1,
+					NextCursor: &next,
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectedFields: []string{"items", "next_cursor", "prev_cursor"},
+		},
+		{
+			name:           "after e before juntos",
+			requestBody:    `{"after": "", "before": ""}`,
+			mockSetup:      func(ms *MockEmployeeService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"error"},
+		},
+		{
+			name:        "cursor inválido",
+			requestBody: `{"after": "abc"}`,
+			mockSetup: func(ms *MockEmployeeService) {
+				ms.listError = utils.ErrInvalidCursor
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"error"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := &MockEmployeeService{}
+			tc.mockSetup(mockService)
+
+			handler := handlers.NewEmployeeHandler(mockService)
+			router := setupRouter()
+			router.POST("/colaboradores/listar", handler.List)
+
+			req, _ := http.NewRequest("POST", "/colaboradores/listar", bytes.NewBufferString(tc.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			var response map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			for _, field := range tc.expectedFields {
+				if _, exists := response[field]; !exists {
+					t.Errorf("Expected field %s not found in response", field)
+				}
+			}
+		})
+	}
+}
+
+func TestColaboradorHandler_GetByID_EscopoDoGerente(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	visivel := uuid.New()
+
+	testCases := []struct {
+		name           string
+		departmentID   uuid.UUID
+		expectedStatus int
+	}{
+		{name: "colaborador da subárvore", departmentID: visivel, expectedStatus: http.StatusOK},
+		{name: "colaborador fora da subárvore", departmentID: uuid.New(), expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := &MockEmployeeService{
+				getResult: &services.EmployeeWithManagerResponse{
+					Employee: &models.Employee{ID: uuid.New(), DepartmentID: tc.departmentID},
+				},
+			}
+
+			handler := handlers.NewEmployeeHandler(mockService)
+			router := setupRouter()
+			router.Use(func(c *gin.Context) { auth.SetScope(c, []uuid.UUID{visivel}) })
+			router.GET("/colaboradores/:id", handler.GetByID)
+
+			req, _ := http.NewRequest("GET", "/colaboradores/"+uuid.New().String(), nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package handlers_test
+
+import (
+	"ManageEmployeesandDepartments/internal/auth"
+	"ManageEmployeesandDepartments/internal/handlers"
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/utils"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/goleak"
+)
+
+func TestEmployeeHandler_History(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	ti, rh := uuid.New(), uuid.New()
+	moved := time.Date(2024, 6, 1, 14, 30, 0, 0, time.UTC)
+	history := func() []*models.DepartmentAssignment {
+		return []*models.DepartmentAssignment{
+			{ID: uuid.New(), DepartmentID: ti, DepartmentName: "TI", EffectiveFrom: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), EffectiveTo: &moved},
+			{ID: uuid.New(), DepartmentID: rh, DepartmentName: "RH", EffectiveFrom: moved},
+		}
+	}
+
+	testCases := []struct {
+		name           string
+		idParam        string
+		query          string
+		scope          []uuid.UUID
+		historyError   error
+		expectedStatus int
+		expectedDepts  []uuid.UUID
+	}{
+		{name: "histórico completo", idParam: uuid.New().String(), expectedStatus: http.StatusOK, expectedDepts: []uuid.UUID{ti, rh}},
+		{name: "data anterior à transferência", idParam: uuid.New().String(), query: "?as_of=2024-05-31", expectedStatus: http.StatusOK, expectedDepts: []uuid.UUID{ti}},
+		{name: "dia da transferência considera o fim do dia", idParam: uuid.New().String(), query: "?as_of=2024-06-01", expectedStatus: http.StatusOK, expectedDepts: []uuid.UUID{rh}},
+		{name: "instante antes da transferência", idParam: uuid.New().String(), query: "?as_of=2024-06-01T11:00:00-03:00", expectedStatus: http.StatusOK, expectedDepts: []uuid.UUID{ti}},
+		{name: "antes da admissão", idParam: uuid.New().String(), query: "?as_of=2023-12-31", expectedStatus: http.StatusOK, expectedDepts: []uuid.UUID{}},
+		{name: "as_of inválido", idParam: uuid.New().String(), query: "?as_of=01/06/2024", expectedStatus: http.StatusBadRequest},
+		{name: "ID inválido", idParam: "invalid-uuid", expectedStatus: http.StatusBadRequest},
+		{name: "colaborador não encontrado", idParam: uuid.New().String(), historyError: utils.ErrEmployeeNotFound, expectedStatus: http.StatusNotFound},
+		{name: "gerente com o colaborador na subárvore", idParam: uuid.New().String(), scope: []uuid.UUID{rh}, expectedStatus: http.StatusOK, expectedDepts: []uuid.UUID{ti, rh}},
+		{name: "gerente fora da subárvore atual", idParam: uuid.New().String(), scope: []uuid.UUID{ti}, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := &MockEmployeeService{historyError: tc.historyError}
+			if tc.historyError == nil {
+				mockService.historyResult = history()
+			}
+
+			handler := handlers.NewEmployeeHandler(mockService)
+			router := setupRouter()
+			router.GET("/colaboradores/:id/historico", func(c *gin.Context) {
+				if tc.scope != nil {
+					auth.SetScope(c, tc.scope)
+				}
+			}, handler.History)
+
+			req, _ := http.NewRequest("GET", "/colaboradores/"+tc.idParam+"/historico"+tc.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.expectedStatus, w.Code, w.Body.String())
+			}
+			if tc.expectedDepts == nil {
+				return
+			}
+
+			var got []models.DepartmentAssignment
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(got) != len(tc.expectedDepts) {
+				t.Fatalf("Expected %d assignments, got %d", len(tc.expectedDepts), len(got))
+			}
+			for i := range got {
+				if got[i].DepartmentID != tc.expectedDepts[i] {
+					t.Errorf("Assignment %d: expected %s, got %s", i, tc.expectedDepts[i], got[i].DepartmentID)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package handlers_test
+
+import (
+	"ManageEmployeesandDepartments/internal/handlers"
+	"ManageEmployeesandDepartments/internal/models"
+	"archive/zip"
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+	"go.uber.org/goleak"
+)
+
+// multipartFile builds a multipart body with content sent as the "file" field.
+func multipartFile(t *testing.T, filename string, content []byte) (*bytes.Buffer, string) {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	part.Write(content)
+	writer.Close()
+	return body, writer.FormDataContentType()
+}
+
+func xlsxFile(t *testing.T, rows [][]any) []byte {
+	t.Helper()
+	book := excelize.NewFile()
+	defer book.Close()
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := book.SetSheetRow("Sheet1", cell, &row); err != nil {
+			t.Fatalf("Failed to write row: %v", err)
+		}
+	}
+	buf, err := book.WriteToBuffer()
+	if err != nil {
+		t.Fatalf("Failed to write workbook: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// paddedXLSX adds an entry of size zero bytes to a workbook. Zeros compress
+// to almost nothing, so the file stays small while unzipping to size.
+func paddedXLSX(t *testing.T, book []byte, size int) []byte {
+	t.Helper()
+	reader, err := zip.NewReader(bytes.NewReader(book), int64(len(book)))
+	if err != nil {
+		t.Fatalf("Failed to read workbook: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	writer := zip.NewWriter(buf)
+	for _, f := range reader.File {
+		if err := writer.Copy(f); err != nil {
+			t.Fatalf("Failed to copy %s: %v", f.Name, err)
+		}
+	}
+	padding, err := writer.Create("xl/media/padding.bin")
+	if err != nil {
+		t.Fatalf("Failed to create padding: %v", err)
+	}
+	padding.Write(make([]byte, size))
+	writer.Close()
+	return buf.Bytes()
+}
+
+func TestEmployeeHandler_Import(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	csvComma := []byte("name,cpf,rg,department\nMaria,123.456.789-09,123456789,Empresa/TI\n\nJoão,98765432100,,Empresa/RH\n")
+	// Excel em português salva com ponto e vírgula e BOM
+	csvSemicolon := []byte("\xef\xbb\xbfNome;CPF;Departamento\nMaria;123.456.789-09;Empresa/TI\nJoão;98765432100;Empresa/RH\n")
+
+	testCases := []struct {
+		name           string
+		filename       string
+		content        func(t *testing.T) []byte
+		query          string
+		expectedStatus int
+		expectedRows   []models.EmployeeImportRow
+		expectedOpts   models.ImportOptions
+	}{
+		{
+			name:           "CSV separado por vírgula",
+			filename:       "colaboradores.csv",
+			content:        func(*testing.T) []byte { return csvComma },
+			expectedStatus: http.StatusOK,
+			expectedRows: []models.EmployeeImportRow{
+				{Line: 2, Name: "Maria", CPF: "123.456.789-09", RG: "123456789", Department: "Empresa/TI"},
+				{Line: 4, Name: "João", CPF: "98765432100", Department: "Empresa/RH"},
+			},
+			expectedOpts: models.ImportOptions{Mode: models.ImportModeAtomic},
+		},
+		{
+			name:           "CSV do Excel com ponto e vírgula",
+			filename:       "colaboradores.CSV",
+			content:        func(*testing.T) []byte { return csvSemicolon },
+			query:          "?mode=best_effort&dry_run=true",
+			expectedStatus: http.StatusOK,
+			expectedRows: []models.EmployeeImportRow{
+				{Line: 2, Name: "Maria", CPF: "123.456.789-09", Department: "Empresa/TI"},
+				{Line: 3, Name: "João", CPF: "98765432100", Department: "Empresa/RH"},
+			},
+			expectedOpts: models.ImportOptions{Mode: models.ImportModeBestEffort, DryRun: true},
+		},
+		{
+			name:     "planilha XLSX",
+			filename: "colaboradores.xlsx",
+			content: func(t *testing.T) []byte {
+				return xlsxFile(t, [][]any{
+					{"department_id", "name", "cpf", "rg"},
+					{"Empresa/TI", "Maria", "12345678909", 123456789},
+				})
+			},
+			expectedStatus: http.StatusOK,
+			expectedRows: []models.EmployeeImportRow{
+				{Line: 2, Name: "Maria", CPF: "12345678909", RG: "123456789", Department: "Empresa/TI"},
+			},
+			expectedOpts: models.ImportOptions{Mode: models.ImportModeAtomic},
+		},
+		{
+			name:     "planilha XLSX grande demais descompactada",
+			filename: "colaboradores.xlsx",
+			content: func(t *testing.T) []byte {
+				book := xlsxFile(t, [][]any{{"name", "cpf", "department"}, {"Maria", "12345678909", "Empresa/TI"}})
+				return paddedXLSX(t, book, 64<<20)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "modo inválido",
+			filename:       "colaboradores.csv",
+			content:        func(*testing.T) []byte { return csvComma },
+			query:          "?mode=parcial",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "dry_run inválido",
+			filename:       "colaboradores.csv",
+			content:        func(*testing.T) []byte { return csvComma },
+			query:          "?dry_run=talvez",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "extensão não suportada",
+			filename:       "colaboradores.txt",
+			content:        func(*testing.T) []byte { return csvComma },
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "coluna obrigatória ausente",
+			filename:       "colaboradores.csv",
+			content:        func(*testing.T) []byte { return []byte("name,rg,department\nMaria,1,TI\n") },
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "arquivo vazio",
+			filename:       "colaboradores.csv",
+			content:        func(*testing.T) []byte { return []byte("\n\n") },
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := &MockEmployeeService{}
+			handler := handlers.NewEmployeeHandler(mockService)
+			router := setupRouter()
+			router.POST("/colaboradores/importar", handler.Import)
+
+			body, contentType := multipartFile(t, tc.filename, tc.content(t))
+			req, _ := http.NewRequest("POST", "/colaboradores/importar"+tc.query, body)
+			req.Header.Set("Content-Type", contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				if mockService.importRows != nil {
+					t.Error("Expected the service not to be called")
+				}
+				return
+			}
+
+			if mockService.importOptions != tc.expectedOpts {
+				t.Errorf("Expected options %+v, got %+v", tc.expectedOpts, mockService.importOptions)
+			}
+			if len(mockService.importRows) != len(tc.expectedRows) {
+				t.Fatalf("Expected %d rows, got %d", len(tc.expectedRows), len(mockService.importRows))
+			}
+			for i, row := range mockService.importRows {
+				if *row != tc.expectedRows[i] {
+					t.Errorf("Expected row %+v, got %+v", tc.expectedRows[i], *row)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,311 @@
+package handlers_test
+
+import (
+	"ManageEmployeesandDepartments/internal/auth"
+	"ManageEmployeesandDepartments/internal/handlers"
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/utils"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+	"go.uber.org/goleak"
+)
+
+func TestEmployeeHandler_Export(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	created := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	rg, manager := "123456789", "Maria"
+	rows := []*models.EmployeeExportRow{
+		{ID: uuid.New(), Name: "Maria", CPF: "12345678909", RG: &rg, DepartmentID: uuid.New(), DepartmentName: "TI", ManagerName: &manager, CreatedAt: created, UpdatedAt: created},
+		{ID: uuid.New(), Name: "João", CPF: "98765432100", DepartmentID: uuid.New(), DepartmentName: "RH", CreatedAt: created, UpdatedAt: created},
+	}
+	scope := []uuid.UUID{uuid.New()}
+
+	testCases := []struct {
+		name           string
+		query          string
+		accept         string
+		exportError    error
+		expectedStatus int
+		expectedType   string
+		expectedCode   string
+	}{
+		{name: "CSV por padrão", expectedStatus: http.StatusOK, expectedType: "text/csv"},
+		{name: "CSV pelo parâmetro", query: "?format=csv", accept: "application/json", expectedStatus: http.StatusOK, expectedType: "text/csv"},
+		{name: "JSON Lines pelo Accept", accept: "application/x-ndjson", expectedStatus: http.StatusOK, expectedType: "application/x-ndjson"},
+		{name: "XLSX pelo Accept", accept: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", expectedStatus: http.StatusOK, expectedType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+		{name: "Accept sem formato suportado", accept: "application/json", expectedStatus: http.StatusNotAcceptable, expectedCode: "NOT_ACCEPTABLE"},
+		{name: "formato inválido", query: "?format=pdf", expectedStatus: http.StatusBadRequest, expectedCode: "INVALID_REQUEST"},
+		{name: "ordem inválida", query: "?order=up", expectedStatus: http.StatusBadRequest, expectedCode: "INVALID_REQUEST"},
+		{name: "departamento inválido", query: "?department_id=ti", expectedStatus: http.StatusBadRequest, expectedCode: "INVALID_REQUEST"},
+		{name: "ordenação desconhecida", query: "?sort=salary", exportError: utils.ErrInvalidSort, expectedStatus: http.StatusBadRequest, expectedCode: "INVALID_SORT"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := &MockEmployeeService{exportRows: rows}
+			if tc.exportError != nil {
+				mockService.exportRows, mockService.exportError = nil, tc.exportError
+			}
+
+			handler := handlers.NewEmployeeHandler(mockService)
+			router := setupRouter()
+			router.GET("/colaboradores/exportar", func(c *gin.Context) { auth.SetScope(c, scope) }, handler.Export)
+
+			req, _ := http.NewRequest("GET", "/colaboradores/exportar"+tc.query, nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.expectedStatus, w.Code, w.Body.String())
+			}
+			if tc.expectedCode != "" {
+				var errResp utils.ErrorResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &errResp); err != nil {
+					t.Fatalf("Failed to decode error: %v", err)
+				}
+				if errResp.Error.ErrorCode != tc.expectedCode {
+					t.Errorf("Expected code %s, got %s", tc.expectedCode, errResp.Error.ErrorCode)
+				}
+				if w.Header().Get("Content-Disposition") != "" {
+					t.Error("Expected no attachment on errors")
+				}
+				return
+			}
+
+			if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, tc.expectedType) {
+				t.Errorf("Expected content type %s, got %s", tc.expectedType, got)
+			}
+			if !strings.Contains(w.Header().Get("Content-Disposition"), "colaboradores.") {
+				t.Errorf("Expected an attachment, got %q", w.Header().Get("Content-Disposition"))
+			}
+			if len(mockService.exportFilter.DepartmentIDs) != 1 || mockService.exportFilter.DepartmentIDs[0] != scope[0] {
+				t.Errorf("Expected the export to be limited to the caller's scope, got %v", mockService.exportFilter.DepartmentIDs)
+			}
+
+			var table [][]string
+			switch tc.expectedType {
+			case "text/csv":
+				body := w.Body.Bytes()
+				if !bytes.HasPrefix(body, []byte("\xef\xbb\xbf")) {
+					t.Error("Expected a byte order mark")
+				}
+				var err error
+				if table, err = csv.NewReader(bytes.NewReader(body[3:])).ReadAll(); err != nil {
+					t.Fatalf("Failed to read CSV: %v", err)
+				}
+			case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+				book, err := excelize.OpenReader(w.Body)
+				if err != nil {
+					t.Fatalf("Failed to open XLSX: %v", err)
+				}
+				defer book.Close()
+				if table, err = book.GetRows(book.GetSheetName(0)); err != nil {
+					t.Fatalf("Failed to read XLSX: %v", err)
+				}
+			case "application/x-ndjson":
+				scanner := bufio.NewScanner(w.Body)
+				for scanner.Scan() {
+					var row models.EmployeeExportRow
+					if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+						t.Fatalf("Failed to decode line %q: %v", scanner.Text(), err)
+					}
+					table = append(table, []string{row.Name, row.DepartmentName})
+				}
+				if len(table) != 2 || table[0][0] != "Maria" || table[1][1] != "RH" {
+					t.Errorf("Unexpected lines: %v", table)
+				}
+				return
+			}
+
+			if len(table) != 3 || strings.Join(table[0], ",") != "id,name,cpf,rg,department_id,department_name,manager_name,created_at,updated_at" {
+				t.Fatalf("Expected a header and two rows, got %v", table)
+			}
+			if table[1][1] != "Maria" || table[1][3] != rg || table[1][5] != "TI" || table[1][6] != "Maria" || table[1][7] != "2024-03-01T10:00:00Z" {
+				t.Errorf("Unexpected row: %v", table[1])
+			}
+			if table[2][3] != "" || table[2][6] != "" {
+				t.Errorf("Expected empty RG and manager, got %v", table[2])
+			}
+		})
+	}
+}
+
+func TestEmployeeHandler_Export_XLSXLimitado(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	row := &models.EmployeeExportRow{ID: uuid.New(), Name: "Maria", CPF: "12345678909", DepartmentID: uuid.New(), DepartmentName: "TI"}
+	rows := make([]*models.EmployeeExportRow, handlers.MaxXLSXExportRows+1)
+	for i := range rows {
+		rows[i] = row
+	}
+
+	testCases := []struct {
+		format         string
+		expectedStatus int
+	}{
+		{format: "xlsx", expectedStatus: http.StatusUnprocessableEntity},
+		{format: "csv", expectedStatus: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			handler := handlers.NewEmployeeHandler(&MockEmployeeService{exportRows: rows})
+			router := setupRouter()
+			router.GET("/colaboradores/exportar", handler.Export)
+
+			req, _ := http.NewRequest("GET", "/colaboradores/exportar?format="+tc.format, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", tc.expectedStatus, w.Code)
+			}
+			if tc.expectedStatus == http.StatusOK {
+				return
+			}
+			var errResp utils.ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &errResp); err != nil {
+				t.Fatalf("Failed to decode error: %v", err)
+			}
+			if errResp.Error.ErrorCode != "EXPORT_TOO_LARGE" || !strings.Contains(errResp.Error.Details, "format=csv") {
+				t.Errorf("Expected the XLSX export to be rejected pointing to CSV, got %+v", errResp.Error)
+			}
+			if w.Header().Get("Content-Disposition") != "" {
+				t.Error("Expected no attachment on errors")
+			}
+		})
+	}
+}
+
+func TestEmployeeHandler_Export_FormulasEscapadas(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	name, dept := `=HYPERLINK("http://x","y")`, "-RH"
+	rows := []*models.EmployeeExportRow{{ID: uuid.New(), Name: name, CPF: "12345678909", DepartmentID: uuid.New(), DepartmentName: dept}}
+
+	for _, tc := range []struct {
+		format       string
+		expectedName string
+		expectedDept string
+	}{
+		{format: "csv", expectedName: "'" + name, expectedDept: "'" + dept},
+		{format: "xlsx", expectedName: name, expectedDept: dept},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			handler := handlers.NewEmployeeHandler(&MockEmployeeService{exportRows: rows})
+			router := setupRouter()
+			router.GET("/colaboradores/exportar", handler.Export)
+
+			req, _ := http.NewRequest("GET", "/colaboradores/exportar?format="+tc.format, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+			var table [][]string
+			var err error
+			if tc.format == "csv" {
+				table, err = csv.NewReader(strings.NewReader(strings.TrimPrefix(w.Body.String(), "\ufeff"))).ReadAll()
+			} else {
+				book, openErr := excelize.OpenReader(w.Body)
+				if openErr != nil {
+					t.Fatalf("Failed to open XLSX: %v", openErr)
+				}
+				defer book.Close()
+				table, err = book.GetRows(book.GetSheetName(0))
+			}
+			if err != nil {
+				t.Fatalf("Failed to read %s: %v", tc.format, err)
+			}
+			if len(table) != 2 || table[1][1] != tc.expectedName || table[1][5] != tc.expectedDept {
+				t.Errorf("Expected %q and %q, got %v", tc.expectedName, tc.expectedDept, table)
+			}
+		})
+	}
+}
+
+func TestEmployeeHandler_Export_Filtros(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	mockService := &MockEmployeeService{}
+	handler := handlers.NewEmployeeHandler(mockService)
+	router := setupRouter()
+	router.GET("/colaboradores/exportar", handler.Export)
+
+	deptID := uuid.New()
+	req, _ := http.NewRequest("GET", "/colaboradores/exportar?name=ma&cpf=123&rg=9&department_id="+deptID.String()+"&sort=name&order=desc", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	filter := mockService.exportFilter
+	if *filter.Name != "ma" || *filter.CPF != "123" || *filter.RG != "9" || *filter.DepartmentID != deptID || filter.DepartmentIDs != nil {
+		t.Errorf("Unexpected filter: %+v", filter)
+	}
+	if mockService.exportSort != (models.Sort{Field: "name", Desc: true}) {
+		t.Errorf("Unexpected sort: %+v", mockService.exportSort)
+	}
+}
+
+func TestDepartamentoHandler_Export(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	parentID, managerID := uuid.New(), uuid.New()
+	parent, manager := "Empresa", "Maria"
+	mockService := &MockDepartmentService{exportRows: []*models.DepartmentExportRow{
+		{ID: uuid.New(), Name: "Empresa"},
+		{ID: uuid.New(), Name: "TI", ManagerID: &managerID, ManagerName: &manager, ParentDepartmentID: &parentID, ParentDepartmentName: &parent},
+	}}
+
+	scope := []uuid.UUID{uuid.New()}
+	handler := handlers.NewDepartamentoHandler(mockService)
+	router := setupRouter()
+	router.GET("/departamentos/exportar", func(c *gin.Context) { auth.SetScope(c, scope) }, handler.Export)
+
+	req, _ := http.NewRequest("GET", "/departamentos/exportar?name=t&parent_department_id="+parentID.String(), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	table, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(w.Body.String(), "\ufeff"))).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	if len(table) != 3 || table[0][0] != "id" || table[1][1] != "Empresa" || table[1][2] != "" {
+		t.Fatalf("Unexpected table: %v", table)
+	}
+	if table[2][2] != managerID.String() || table[2][3] != "Maria" || table[2][4] != parentID.String() || table[2][5] != "Empresa" {
+		t.Errorf("Unexpected row: %v", table[2])
+	}
+	filter := mockService.exportFilter
+	if *filter.Name != "t" || *filter.ParentDepartmentID != parentID || len(filter.DepartmentIDs) != 1 || filter.DepartmentIDs[0] != scope[0] {
+		t.Errorf("Expected the filters and the caller's scope, got %+v", filter)
+	}
+
+	req, _ = http.NewRequest("GET", "/departamentos/exportar?parent_department_id=x", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid parent, got %d", w.Code)
+	}
+}
@@ -18,6 +18,7 @@ func TestGerenteHandler_GetSubordinates(t *testing.T) {
 	testCases := []struct {
 		name           string
 		idParam        string
+		query          string
 		mockSetup      func(*MockDepartmentService)
 		expectedStatus int
 	}{
@@ -25,9 +26,14 @@ func TestGerenteHandler_GetSubordinates(t *testing.T) {
 			name:    "sucesso ao buscar subordinados",
 			idParam: uuid.New().String(),
 			mockSetup: func(ms *MockDepartmentService) {
-				colaboradores := []*models.Employee{
-					{ID: uuid.New(), Name: "João Silva"},
-					{ID: uuid.New(), Name: "Maria Santos"},
+				colaboradores := &models.SubordinatesResponse{
+					Items: []*models.SubordinateEmployee{
+						{Employee: &models.Employee{ID: uuid.New(), Name: "João Silva"}, DepartmentName: "TI"},
+						{Employee: &models.Employee{ID: uuid.New(), Name: "Maria Santos"}, DepartmentName: "Desenvolvimento", Depth: 1},
+					},
+					Page:     1,
+					PageSize: 10,
+					Total:    2,
 				}
 				ms.getSubordinateEmployeesResult = colaboradores
 				ms.getSubordinateEmployeesError = nil
@@ -40,6 +46,29 @@ func TestGerenteHandler_GetSubordinates(t *testing.T) {
 			mockSetup:      func(ms *MockDepartmentService) {},
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name:           "erro depth inválido",
+			idParam:        uuid.New().String(),
+			query:          "?depth=-1",
+			mockSetup:      func(ms *MockDepartmentService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "erro include_managers inválido",
+			idParam:        uuid.New().String(),
+			query:          "?include_managers=talvez",
+			mockSetup:      func(ms *MockDepartmentService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "sucesso com filtros e paginação",
+			idParam: uuid.New().String(),
+			query:   "?depth=2&include_managers=false&page=2&page_size=5",
+			mockSetup: func(ms *MockDepartmentService) {
+				ms.getSubordinateEmployeesResult = &models.SubordinatesResponse{Page: 2, PageSize: 5}
+			},
+			expectedStatus: http.StatusOK,
+		},
 		{
 			name:    "gerente não encontrado",
 			idParam: uuid.New().String(),
@@ -71,7 +100,7 @@ func TestGerenteHandler_GetSubordinates(t *testing.T) {
 			router.GET("/gerentes/:id/colaboradores", handler.GetSubordinates)
 
 			// Executar
-			req, _ := http.NewRequest("GET", "/gerentes/"+tc.idParam+"/colaboradores", nil)
+			req, _ := http.NewRequest("GET", "/gerentes/"+tc.idParam+"/colaboradores"+tc.query, nil)
 			w := httptest.NewRecorder()
 			router.ServeHTTP(w, req)
 
@@ -86,9 +115,11 @@ func TestGerenteHandler_GetSubordinates(t *testing.T) {
 // Benchmark para teste de performance
 func BenchmarkGerenteHandler_GetSubordinates(b *testing.B) {
 	mockService := &MockDepartmentService{
-		getSubordinateEmployeesResult: []*models.Employee{
-			{ID: uuid.New(), Name: "João Silva"},
-			{ID: uuid.New(), Name: "Maria Santos"},
+		getSubordinateEmployeesResult: &models.SubordinatesResponse{
+			Items: []*models.SubordinateEmployee{
+				{Employee: &models.Employee{ID: uuid.New(), Name: "João Silva"}},
+				{Employee: &models.Employee{ID: uuid.New(), Name: "Maria Santos"}},
+			},
 		},
 		getSubordinateEmployeesError: nil,
 	}
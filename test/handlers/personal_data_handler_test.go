@@ -0,0 +1,152 @@
+package handlers_test
+
+import (
+	"ManageEmployeesandDepartments/internal/handlers"
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/utils"
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/goleak"
+)
+
+// MockPersonalDataService simulates the personal data service
+type MockPersonalDataService struct {
+	exportResult *models.PersonalDataExport
+	exportError  error
+}
+
+func (m *MockPersonalDataService) ExportPersonalData(id uuid.UUID) (*models.PersonalDataExport, error) {
+	return m.exportResult, m.exportError
+}
+
+func personalDataExport(id uuid.UUID) *models.PersonalDataExport {
+	ti := uuid.New()
+	hired := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	rg := "123456789"
+	return &models.PersonalDataExport{
+		GeneratedAt: time.Now(),
+		Employee:    &models.Employee{ID: id, Name: "Maria", CPF: "12345678909", RG: &rg, DepartmentID: ti, CreatedAt: hired, UpdatedAt: hired},
+		Manager:     &models.PersonRef{ID: uuid.New(), Name: "Carlos"},
+		DepartmentHistory: []*models.DepartmentAssignment{
+			{DepartmentID: ti, DepartmentName: "TI", EffectiveFrom: hired},
+		},
+		ManagedDepartments: []*models.ManagementPeriod{},
+		AuditEntries: []*models.AuditEntry{
+			{ID: uuid.New(), Actor: "rh", Action: models.AuditActionCreate, CreatedAt: hired, Changes: models.AuditChanges{
+				"name":          {After: "Maria"},
+				"department_id": {After: ti.String()},
+			}},
+		},
+	}
+}
+
+func TestPersonalDataHandler_Export(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	testCases := []struct {
+		name           string
+		idParam        string
+		query          string
+		exportError    error
+		expectedStatus int
+		expectedType   string
+	}{
+		{name: "documento JSON", idParam: uuid.New().String(), expectedStatus: http.StatusOK, expectedType: "application/json; charset=utf-8"},
+		{name: "arquivo zip", idParam: uuid.New().String(), query: "?format=zip", expectedStatus: http.StatusOK, expectedType: "application/zip"},
+		{name: "formato inválido", idParam: uuid.New().String(), query: "?format=pdf", expectedStatus: http.StatusBadRequest},
+		{name: "ID inválido", idParam: "invalid-uuid", expectedStatus: http.StatusBadRequest},
+		{name: "colaborador não encontrado", idParam: uuid.New().String(), exportError: utils.ErrEmployeeNotFound, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := &MockPersonalDataService{exportError: tc.exportError}
+			if id, err := uuid.Parse(tc.idParam); err == nil && tc.exportError == nil {
+				mockService.exportResult = personalDataExport(id)
+			}
+
+			handler := handlers.NewPersonalDataHandler(mockService)
+			router := setupRouter()
+			router.GET("/colaboradores/:id/dados-pessoais", handler.Export)
+
+			req, _ := http.NewRequest("GET", "/colaboradores/"+tc.idParam+"/dados-pessoais"+tc.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.expectedStatus, w.Code, w.Body.String())
+			}
+			if tc.expectedType != "" && w.Header().Get("Content-Type") != tc.expectedType {
+				t.Errorf("Expected content type %s, got %s", tc.expectedType, w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestPersonalDataHandler_Export_Zip(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	id := uuid.New()
+	handler := handlers.NewPersonalDataHandler(&MockPersonalDataService{exportResult: personalDataExport(id)})
+	router := setupRouter()
+	router.GET("/colaboradores/:id/dados-pessoais", handler.Export)
+
+	req, _ := http.NewRequest("GET", "/colaboradores/"+id.String()+"/dados-pessoais?format=zip", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil {
+		t.Fatalf("Expected a zip archive: %v", err)
+	}
+	files := map[string][]byte{}
+	for _, f := range archive.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", f.Name, err)
+		}
+		files[f.Name], _ = io.ReadAll(r)
+		r.Close()
+	}
+
+	var doc models.PersonalDataExport
+	if err := json.Unmarshal(files["dados-pessoais.json"], &doc); err != nil || doc.Employee == nil || doc.Employee.ID != id {
+		t.Fatalf("Expected the JSON document in the archive, got %v", err)
+	}
+
+	read := func(name string) [][]string {
+		t.Helper()
+		rows, err := csv.NewReader(bytes.NewReader(files[name])).ReadAll()
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		return rows
+	}
+	employee := read("colaborador.csv")
+	if len(employee) != 2 || employee[1][1] != "Maria" || employee[1][2] != "12345678909" || employee[1][6] != "Carlos" {
+		t.Errorf("Unexpected colaborador.csv: %v", employee)
+	}
+	if history := read("historico_departamentos.csv"); len(history) != 2 || history[1][1] != "TI" || history[1][3] != "" {
+		t.Errorf("Unexpected historico_departamentos.csv: %v", history)
+	}
+	if managed := read("departamentos_geridos.csv"); len(managed) != 1 {
+		t.Errorf("Expected only the header in departamentos_geridos.csv, got %v", managed)
+	}
+	// Uma linha por campo alterado, em ordem alfabética
+	audit := read("auditoria.csv")
+	if len(audit) != 3 || audit[1][4] != "department_id" || audit[2][4] != "name" || audit[2][5] != "" || audit[2][6] != "Maria" {
+		t.Errorf("Unexpected auditoria.csv: %v", audit)
+	}
+}
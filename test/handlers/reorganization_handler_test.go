@@ -0,0 +1,134 @@
+package handlers_test
+
+import (
+	"ManageEmployeesandDepartments/internal/auth"
+	"ManageEmployeesandDepartments/internal/handlers"
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/utils"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/goleak"
+	"gorm.io/gorm"
+)
+
+func TestDepartamentoHandler_Reorganize(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	deptID, employeeID := uuid.New(), uuid.New()
+	valid := `{"operations":[{"type":"rename","department_id":"` + deptID.String() + `","name":"Tecnologia"},` +
+		`{"type":"move_employee","department_id":"` + deptID.String() + `","employee_id":"` + employeeID.String() + `"}]}`
+
+	testCases := []struct {
+		name           string
+		query          string
+		body           string
+		expectedStatus int
+		expectedDryRun bool
+	}{
+		{name: "plano aplicado", body: valid, expectedStatus: http.StatusOK},
+		{name: "simulação", query: "?dry_run=true", body: valid, expectedStatus: http.StatusOK, expectedDryRun: true},
+		{name: "dry_run inválido", query: "?dry_run=talvez", body: valid, expectedStatus: http.StatusBadRequest},
+		{name: "sem operações", body: `{"operations":[]}`, expectedStatus: http.StatusBadRequest},
+		{name: "tipo de operação desconhecido", body: `{"operations":[{"type":"split","department_id":"` + deptID.String() + `"}]}`, expectedStatus: http.StatusBadRequest},
+		{name: "operação sem departamento", body: `{"operations":[{"type":"rename","name":"TI"}]}`, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := &MockDepartmentService{reorganizeResult: &models.ReorganizationReport{Valid: true}}
+
+			handler := handlers.NewDepartamentoHandler(mockService)
+			router := setupRouter()
+			router.POST("/reorganizacoes", func(c *gin.Context) {
+				auth.SetPrincipal(c, &auth.Principal{Subject: "rh@empresa.com"})
+			}, handler.Reorganize)
+
+			req, _ := http.NewRequest("POST", "/reorganizacoes"+tc.query, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				if mockService.reorganizeOps != nil {
+					t.Error("Expected the service not to be called")
+				}
+				return
+			}
+			if len(mockService.reorganizeOps) != 2 || *mockService.reorganizeOps[1].EmployeeID != employeeID {
+				t.Errorf("Expected the operations to reach the service, got %+v", mockService.reorganizeOps)
+			}
+			if mockService.dryRun != tc.expectedDryRun || mockService.actor != "rh@empresa.com" {
+				t.Errorf("Expected dry_run=%v by the caller, got %v by %q", tc.expectedDryRun, mockService.dryRun, mockService.actor)
+			}
+		})
+	}
+}
+
+func TestDepartamentoHandler_Merge(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	targetID, managerID := uuid.New(), uuid.New()
+	testCases := []struct {
+		name            string
+		idParam         string
+		body            string
+		mergeError      error
+		expectedStatus  int
+		expectedManager *uuid.UUID
+	}{
+		{name: "mesclagem com gerente do destino", idParam: uuid.New().String(), body: `{"target_department_id":"` + targetID.String() + `"}`, expectedStatus: http.StatusOK},
+		{name: "mesclagem escolhendo o gerente", idParam: uuid.New().String(), body: `{"target_department_id":"` + targetID.String() + `","manager_id":"` + managerID.String() + `"}`, expectedStatus: http.StatusOK, expectedManager: &managerID},
+		{name: "ID inválido", idParam: "invalid-uuid", body: `{"target_department_id":"` + targetID.String() + `"}`, expectedStatus: http.StatusBadRequest},
+		{name: "sem destino", idParam: uuid.New().String(), body: `{}`, expectedStatus: http.StatusBadRequest},
+		{name: "origem não encontrada", idParam: uuid.New().String(), body: `{"target_department_id":"` + targetID.String() + `"}`, mergeError: gorm.ErrRecordNotFound, expectedStatus: http.StatusNotFound},
+		{name: "destino não encontrado", idParam: uuid.New().String(), body: `{"target_department_id":"` + targetID.String() + `"}`, mergeError: utils.ErrDepartmentNotFound, expectedStatus: http.StatusUnprocessableEntity},
+		{name: "mesclagem confirmada", idParam: uuid.New().String(), body: `{"target_department_id":"` + targetID.String() + `","confirmation_token":"abc.def"}`, expectedStatus: http.StatusOK},
+		{name: "mesclagem sem confirmação", idParam: uuid.New().String(), body: `{"target_department_id":"` + targetID.String() + `"}`, mergeError: utils.ErrConfirmationRequired, expectedStatus: http.StatusPreconditionRequired},
+		{name: "gerente de fora", idParam: uuid.New().String(), body: `{"target_department_id":"` + targetID.String() + `","manager_id":"` + managerID.String() + `"}`, mergeError: utils.ErrManagerNotBelongToDepartment, expectedStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := &MockDepartmentService{mergeError: tc.mergeError}
+			if tc.mergeError == nil {
+				mockService.mergeResult = &models.Department{ID: targetID, Name: "RH"}
+			}
+
+			handler := handlers.NewDepartamentoHandler(mockService)
+			router := setupRouter()
+			router.POST("/departamentos/:id/mesclar", func(c *gin.Context) {
+				auth.SetPrincipal(c, &auth.Principal{Subject: "rh@empresa.com"})
+			}, handler.Merge)
+
+			req, _ := http.NewRequest("POST", "/departamentos/"+tc.idParam+"/mesclar", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			if mockService.mergeTargetID != targetID || mockService.actor != "rh@empresa.com" {
+				t.Errorf("Expected the merge into %s by the caller, got %s by %q", targetID, mockService.mergeTargetID, mockService.actor)
+			}
+			if (tc.expectedManager == nil) != (mockService.mergeManagerID == nil) ||
+				(tc.expectedManager != nil && *mockService.mergeManagerID != *tc.expectedManager) {
+				t.Errorf("Expected manager %v, got %v", tc.expectedManager, mockService.mergeManagerID)
+			}
+			if strings.Contains(tc.body, "confirmation_token") != (mockService.confirmation == "abc.def") {
+				t.Errorf("Expected the confirmation token to be passed on, got %q", mockService.confirmation)
+			}
+		})
+	}
+}
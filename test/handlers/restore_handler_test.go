@@ -0,0 +1,163 @@
+package handlers_test
+
+import (
+	"ManageEmployeesandDepartments/internal/auth"
+	"ManageEmployeesandDepartments/internal/handlers"
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/utils"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/goleak"
+	"gorm.io/gorm"
+)
+
+func TestEmployeeHandler_Restore(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	testCases := []struct {
+		name           string
+		idParam        string
+		restoreError   error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "colaborador restaurado", idParam: uuid.New().String(), expectedStatus: http.StatusOK},
+		{name: "ID inválido", idParam: "invalid-uuid", expectedStatus: http.StatusBadRequest, expectedCode: "INVALID_ID"},
+		{name: "colaborador não encontrado", idParam: uuid.New().String(), restoreError: utils.ErrEmployeeNotFound, expectedStatus: http.StatusNotFound, expectedCode: "EMPLOYEE_NOT_FOUND"},
+		{name: "colaborador não removido", idParam: uuid.New().String(), restoreError: utils.ErrNotDeleted, expectedStatus: http.StatusConflict, expectedCode: "NOT_DELETED"},
+		{name: "CPF em uso", idParam: uuid.New().String(), restoreError: utils.ErrCPFDuplicated, expectedStatus: http.StatusConflict, expectedCode: "CPF_DUPLICATED"},
+		{name: "departamento removido", idParam: uuid.New().String(), restoreError: utils.ErrDepartmentNotFound, expectedStatus: http.StatusUnprocessableEntity, expectedCode: "DEPARTMENT_NOT_FOUND"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := &MockEmployeeService{restoreError: tc.restoreError}
+			if tc.restoreError == nil {
+				mockService.restoreResult = &models.Employee{ID: uuid.New(), Name: "Maria"}
+			}
+
+			handler := handlers.NewEmployeeHandler(mockService)
+			router := setupRouter()
+			router.POST("/colaboradores/:id/restaurar", func(c *gin.Context) {
+				auth.SetPrincipal(c, &auth.Principal{Subject: "rh@empresa.com"})
+			}, handler.Restore)
+
+			req, _ := http.NewRequest("POST", "/colaboradores/"+tc.idParam+"/restaurar", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.expectedStatus, w.Code, w.Body.String())
+			}
+			if tc.expectedCode != "" {
+				var errResp utils.ErrorResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &errResp); err != nil {
+					t.Fatalf("Failed to decode error: %v", err)
+				}
+				if errResp.Error.ErrorCode != tc.expectedCode {
+					t.Errorf("Expected code %s, got %s", tc.expectedCode, errResp.Error.ErrorCode)
+				}
+				return
+			}
+			if mockService.actor != "rh@empresa.com" {
+				t.Errorf("Expected the restore to be attributed to the caller, got %q", mockService.actor)
+			}
+		})
+	}
+}
+
+func TestEmployeeHandler_ListDeleted(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	deletedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name           string
+		query          string
+		expectedStatus int
+	}{
+		{name: "primeira página", expectedStatus: http.StatusOK},
+		{name: "página informada", query: "?page=2&page_size=5", expectedStatus: http.StatusOK},
+		{name: "página inválida", query: "?page=0", expectedStatus: http.StatusBadRequest},
+		{name: "tamanho acima do máximo", query: "?page_size=101", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := &MockEmployeeService{listDeletedResult: models.NewPage([]*models.DeletedEmployee{
+				{Employee: &models.Employee{ID: uuid.New(), Name: "Maria"}, DeletedAt: deletedAt},
+			}, 1, 10, 1)}
+
+			handler := handlers.NewEmployeeHandler(mockService)
+			router := setupRouter()
+			router.GET("/colaboradores/excluidos", handler.ListDeleted)
+
+			req, _ := http.NewRequest("GET", "/colaboradores/excluidos"+tc.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			var page struct {
+				Items []struct {
+					Name      string    `json:"name"`
+					DeletedAt time.Time `json:"deleted_at"`
+				} `json:"items"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(page.Items) != 1 || page.Items[0].Name != "Maria" || !page.Items[0].DeletedAt.Equal(deletedAt) {
+				t.Errorf("Unexpected items: %+v", page.Items)
+			}
+		})
+	}
+}
+
+func TestDepartamentoHandler_Restore(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	testCases := []struct {
+		name           string
+		idParam        string
+		restoreError   error
+		expectedStatus int
+	}{
+		{name: "departamento restaurado", idParam: uuid.New().String(), expectedStatus: http.StatusOK},
+		{name: "ID inválido", idParam: "invalid-uuid", expectedStatus: http.StatusBadRequest},
+		{name: "departamento não encontrado", idParam: uuid.New().String(), restoreError: gorm.ErrRecordNotFound, expectedStatus: http.StatusNotFound},
+		{name: "departamento não removido", idParam: uuid.New().String(), restoreError: utils.ErrNotDeleted, expectedStatus: http.StatusConflict},
+		{name: "departamento superior removido", idParam: uuid.New().String(), restoreError: utils.ErrParentDepartmentNotFound, expectedStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := &MockDepartmentService{restoreError: tc.restoreError}
+			if tc.restoreError == nil {
+				mockService.restoreResult = &models.Department{ID: uuid.New(), Name: "TI"}
+			}
+
+			handler := handlers.NewDepartamentoHandler(mockService)
+			router := setupRouter()
+			router.POST("/departamentos/:id/restaurar", handler.Restore)
+
+			req, _ := http.NewRequest("POST", "/departamentos/"+tc.idParam+"/restaurar", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package middleware_test
+
+import (
+	"ManageEmployeesandDepartments/internal/auth"
+	"ManageEmployeesandDepartments/internal/middleware"
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/utils"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const testSecret = "segredo-de-teste"
+
+func signHS256(t *testing.T, claims auth.Claims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return token
+}
+
+func newClaims(sub string, roles ...auth.Role) auth.Claims {
+	return auth.Claims{
+		Roles: roles,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   sub,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+}
+
+// setupAuthRouter expõe uma rota de leitura e uma de escrita como em routes.SetupRoutes
+func setupAuthRouter(t *testing.T, cfg auth.JWTConfig, subtree middleware.SubtreeResolver) *gin.Engine {
+	t.Helper()
+	verifier, err := auth.NewVerifier(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+
+	r := setupRouter()
+	authn := middleware.NewAuth(verifier, subtree, nil)
+	read := middleware.RequireRoles(auth.RoleHRAdmin, auth.RoleViewer, auth.RoleManager)
+	write := middleware.RequireRoles(auth.RoleHRAdmin)
+
+	v1 := r.Group("/api/v1", authn.Authenticate())
+	v1.GET("/recurso", read, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"scope": auth.ScopeFrom(c), "restricted": auth.ScopeFrom(c) != nil})
+	})
+	v1.DELETE("/recurso", write, func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return r
+}
+
+func TestAuthenticate_HS256(t *testing.T) {
+	gerenteID := uuid.New()
+	subarvore := []uuid.UUID{uuid.New(), uuid.New()}
+	resolver := func(managerID uuid.UUID) ([]uuid.UUID, error) {
+		if managerID != gerenteID {
+			return nil, errors.New("unexpected manager")
+		}
+		return subarvore, nil
+	}
+	router := setupAuthRouter(t, auth.JWTConfig{Algorithm: "HS256", Secret: testSecret}, resolver)
+
+	expired := newClaims("", auth.RoleHRAdmin)
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+	noExpiry := newClaims("", auth.RoleHRAdmin)
+	noExpiry.ExpiresAt = nil
+	forged, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, newClaims("", auth.RoleHRAdmin)).SignedString([]byte("outro-segredo"))
+
+	testCases := []struct {
+		name           string
+		method         string
+		header         string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "sem token", method: "GET", expectedStatus: http.StatusUnauthorized},
+		{name: "esquema diferente de Bearer", method: "GET", header: "Basic abc", expectedStatus: http.StatusUnauthorized},
+		{name: "assinatura inválida", method: "GET", header: "Bearer " + forged, expectedStatus: http.StatusUnauthorized},
+		{name: "token expirado", method: "GET", header: "Bearer " + signHS256(t, expired), expectedStatus: http.StatusUnauthorized},
+		{name: "token sem expiração", method: "GET", header: "Bearer " + signHS256(t, noExpiry), expectedStatus: http.StatusUnauthorized},
+		{name: "gerente sem colaborador no sub", method: "GET", header: "Bearer " + signHS256(t, newClaims("", auth.RoleManager)), expectedStatus: http.StatusUnauthorized},
+		{name: "sem perfil", method: "GET", header: "Bearer " + signHS256(t, newClaims("")), expectedStatus: http.StatusForbidden},
+		{name: "viewer lê sem restrição", method: "GET", header: "Bearer " + signHS256(t, newClaims("", auth.RoleViewer)), expectedStatus: http.StatusOK, expectedBody: `{"restricted":false,"scope":null}`},
+		{name: "viewer não remove", method: "DELETE", header: "Bearer " + signHS256(t, newClaims("", auth.RoleViewer)), expectedStatus: http.StatusForbidden},
+		{name: "gerente não remove", method: "DELETE", header: "Bearer " + signHS256(t, newClaims(gerenteID.String(), auth.RoleManager)), expectedStatus: http.StatusForbidden},
+		{name: "hr_admin remove", method: "DELETE", header: "Bearer " + signHS256(t, newClaims("", auth.RoleHRAdmin)), expectedStatus: http.StatusNoContent},
+		{
+			name:           "gerente lê apenas a própria subárvore",
+			method:         "GET",
+			header:         "Bearer " + signHS256(t, newClaims(gerenteID.String(), auth.RoleManager)),
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"restricted":true,"scope":["` + subarvore[0].String() + `","` + subarvore[1].String() + `"]}`,
+		},
+		{
+			name:           "gerente que também é viewer lê sem restrição",
+			method:         "GET",
+			header:         "Bearer " + signHS256(t, newClaims(gerenteID.String(), auth.RoleManager, auth.RoleViewer)),
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"restricted":false,"scope":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tc.method, "/api/v1/recurso", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.expectedStatus, w.Code, w.Body.String())
+			}
+			if tc.expectedBody != "" && w.Body.String() != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+			if w.Code == http.StatusUnauthorized {
+				if w.Header().Get("WWW-Authenticate") == "" {
+					t.Errorf("Expected WWW-Authenticate header")
+				}
+				if body := decodeEnvelope(t, w); body.ErrorCode != "UNAUTHORIZED" {
+					t.Errorf("Expected code UNAUTHORIZED, got %q", body.ErrorCode)
+				}
+			}
+			if w.Code == http.StatusForbidden {
+				if body := decodeEnvelope(t, w); body.ErrorCode != "FORBIDDEN" {
+					t.Errorf("Expected code FORBIDDEN, got %q", body.ErrorCode)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthenticate_RS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("Failed to marshal public key: %v", err)
+	}
+	publicPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	cfg := auth.JWTConfig{Algorithm: "RS256", PublicKey: publicPEM, Issuer: "rh", Audience: "api"}
+	router := setupAuthRouter(t, cfg, nil)
+
+	valid := newClaims("", auth.RoleViewer)
+	valid.Issuer = "rh"
+	valid.Audience = jwt.ClaimStrings{"api"}
+	signed, _ := jwt.NewWithClaims(jwt.SigningMethodRS256, valid).SignedString(key)
+
+	wrongIssuer := valid
+	wrongIssuer.Issuer = "outro"
+	signedWrongIssuer, _ := jwt.NewWithClaims(jwt.SigningMethodRS256, wrongIssuer).SignedString(key)
+
+	// Confusão de algoritmo: HS256 assinado com a chave pública como segredo
+	confused, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, valid).SignedString([]byte(publicPEM))
+
+	testCases := []struct {
+		name           string
+		token          string
+		expectedStatus int
+	}{
+		{name: "token válido", token: signed, expectedStatus: http.StatusOK},
+		{name: "emissor incorreto", token: signedWrongIssuer, expectedStatus: http.StatusUnauthorized},
+		{name: "HS256 com a chave pública", token: confused, expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/api/v1/recurso", nil)
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tc.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthenticate_APIKey(t *testing.T) {
+	verifier, err := auth.NewVerifier(auth.JWTConfig{Algorithm: "HS256", Secret: testSecret})
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	chaves := map[string]*models.APIKey{
+		"emk_leitura": {ID: uuid.New(), Scopes: models.Scopes{models.ScopeEmployeesRead}},
+		"emk_escrita": {ID: uuid.New(), Scopes: models.Scopes{models.ScopeEmployeesRead, models.ScopeEmployeesWrite}},
+	}
+	resolver := func(key string) (*models.APIKey, error) {
+		if k, ok := chaves[key]; ok {
+			return k, nil
+		}
+		return nil, utils.ErrUnauthorized
+	}
+
+	r := setupRouter()
+	authn := middleware.NewAuth(verifier, nil, resolver)
+	v1 := r.Group("/api/v1", authn.Authenticate())
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	v1.GET("/recurso", middleware.RequireAccess(models.ScopeEmployeesRead, auth.RoleViewer), ok)
+	v1.DELETE("/recurso", middleware.RequireAccess(models.ScopeEmployeesWrite, auth.RoleHRAdmin), ok)
+	v1.GET("/admin", middleware.RequireRoles(auth.RoleHRAdmin), ok)
+
+	testCases := []struct {
+		name           string
+		method         string
+		path           string
+		key            string
+		bearer         string
+		expectedStatus int
+	}{
+		{name: "chave desconhecida", method: "GET", path: "/api/v1/recurso", key: "emk_outra", expectedStatus: http.StatusUnauthorized},
+		{name: "chave com escopo de leitura lê", method: "GET", path: "/api/v1/recurso", key: "emk_leitura", expectedStatus: http.StatusNoContent},
+		{name: "chave sem escopo de escrita não remove", method: "DELETE", path: "/api/v1/recurso", key: "emk_leitura", expectedStatus: http.StatusForbidden},
+		{name: "chave com escopo de escrita remove", method: "DELETE", path: "/api/v1/recurso", key: "emk_escrita", expectedStatus: http.StatusNoContent},
+		{name: "chave nunca acessa rota de perfil", method: "GET", path: "/api/v1/admin", key: "emk_escrita", expectedStatus: http.StatusForbidden},
+		{name: "chave prevalece sobre o token", method: "GET", path: "/api/v1/admin", key: "emk_escrita", bearer: signHS256(t, newClaims("", auth.RoleHRAdmin)), expectedStatus: http.StatusForbidden},
+		{name: "token continua aceito", method: "GET", path: "/api/v1/admin", bearer: signHS256(t, newClaims("", auth.RoleHRAdmin)), expectedStatus: http.StatusNoContent},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tc.method, tc.path, nil)
+			if tc.key != "" {
+				req.Header.Set(middleware.APIKeyHeader, tc.key)
+			}
+			if tc.bearer != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.bearer)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tc.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestNewVerifier_Config(t *testing.T) {
+	testCases := []struct {
+		name string
+		cfg  auth.JWTConfig
+	}{
+		{name: "HS256 sem segredo", cfg: auth.JWTConfig{Algorithm: "HS256"}},
+		{name: "RS256 sem chave", cfg: auth.JWTConfig{Algorithm: "RS256"}},
+		{name: "RS256 com chave inválida", cfg: auth.JWTConfig{Algorithm: "RS256", PublicKey: "não é PEM"}},
+		{name: "algoritmo não suportado", cfg: auth.JWTConfig{Algorithm: "none", Secret: testSecret}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := auth.NewVerifier(tc.cfg); err == nil {
+				t.Errorf("Expected configuration error")
+			}
+		})
+	}
+}
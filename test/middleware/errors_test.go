@@ -0,0 +1,157 @@
+package middleware_test
+
+import (
+	"ManageEmployeesandDepartments/internal/middleware"
+	"ManageEmployeesandDepartments/internal/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func setupRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.Recovery(), middleware.ErrorHandler())
+	r.NoRoute(middleware.NoRoute)
+	return r
+}
+
+func decodeEnvelope(t *testing.T, w *httptest.ResponseRecorder) utils.CustomError {
+	t.Helper()
+	var response struct {
+		Error *utils.CustomError `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Error == nil {
+		t.Fatalf("Expected error envelope, got %s", w.Body.String())
+	}
+	return *response.Error
+}
+
+func TestErrorHandler(t *testing.T) {
+	testCases := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedCode   string
+		expectedField  string
+		expectDetails  bool
+	}{
+		{
+			name:           "registro não encontrado",
+			err:            gorm.ErrRecordNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "NOT_FOUND",
+			expectDetails:  true,
+		},
+		{
+			name:           "CPF duplicado",
+			err:            fmt.Errorf("creating employee: %w", utils.ErrCPFDuplicated),
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "CPF_DUPLICATED",
+			expectedField:  "cpf",
+			expectDetails:  true,
+		},
+		{
+			name:           "ciclo hierárquico",
+			err:            utils.ErrCycleDetected,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "HIERARCHY_CYCLE",
+			expectedField:  "parent_department_id",
+			expectDetails:  true,
+		},
+		{
+			name:           "erro inesperado não expõe detalhes",
+			err:            errors.New("pq: connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "INTERNAL_ERROR",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := setupRouter()
+			router.GET("/recurso", func(c *gin.Context) {
+				_ = c.Error(tc.err)
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/recurso", nil)
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tc.expectedStatus, w.Code)
+			}
+			body := decodeEnvelope(t, w)
+			if body.ErrorCode != tc.expectedCode {
+				t.Errorf("Expected code %q, got %q", tc.expectedCode, body.ErrorCode)
+			}
+			if body.Field != tc.expectedField {
+				t.Errorf("Expected field %q, got %q", tc.expectedField, body.Field)
+			}
+			if (body.Details != "") != tc.expectDetails {
+				t.Errorf("Unexpected details %q", body.Details)
+			}
+		})
+	}
+}
+
+func TestErrorHandler_KeepsWrittenResponse(t *testing.T) {
+	router := setupRouter()
+	router.GET("/recurso", func(c *gin.Context) {
+		_ = c.Error(errors.New("logged only"))
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/recurso", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status %d, got %d", http.StatusNoContent, w.Code)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("Expected empty body, got %s", w.Body.String())
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	router := setupRouter()
+	router.GET("/panico", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/panico", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+	if body := decodeEnvelope(t, w); body.ErrorCode != "INTERNAL_ERROR" {
+		t.Errorf("Expected code INTERNAL_ERROR, got %q", body.ErrorCode)
+	}
+}
+
+func TestNoRoute(t *testing.T) {
+	router := setupRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/inexistente", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+	if body := decodeEnvelope(t, w); body.ErrorCode != "NOT_FOUND" {
+		t.Errorf("Expected code NOT_FOUND, got %q", body.ErrorCode)
+	}
+}
@@ -0,0 +1,100 @@
+package repository_test
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/goleak"
+)
+
+func TestEmployeeRepository_Anonymize(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	db, cleanup := setupDepartamentoTestDB(t)
+	defer cleanup()
+	deptRepo := repository.NewDepartmentRepository(db)
+	repo := repository.NewEmployeeRepository(db)
+
+	ti := &models.Department{Name: "TI"}
+	if err := deptRepo.Create(ti); err != nil {
+		t.Fatalf("Failed to create department: %v", err)
+	}
+	rg := "123456789"
+	employee := &models.Employee{Name: "Maria", CPF: "12345678909", RG: &rg, DepartmentID: ti.ID}
+	if err := repo.Create(employee); err != nil {
+		t.Fatalf("Failed to create employee: %v", err)
+	}
+	if err := repo.Delete(employee.ID); err != nil {
+		t.Fatalf("Failed to delete employee: %v", err)
+	}
+
+	at := time.Now().UTC()
+	employee.Name = models.AnonymizedName
+	employee.CPF = "Xabcdef0123"
+	employee.RG = nil
+	employee.AnonymizedAt = &at
+	if err := repo.Anonymize(employee); err != nil {
+		t.Fatalf("Anonymize failed: %v", err)
+	}
+
+	got, err := repo.FindByIDWithDeletedForUpdate(employee.ID)
+	if err != nil {
+		t.Fatalf("Failed to load employee: %v", err)
+	}
+	if got.Name != models.AnonymizedName || got.CPF != "Xabcdef0123" || got.RG != nil || got.AnonymizedAt == nil {
+		t.Errorf("Expected the personal data to be replaced, got %+v", got)
+	}
+	if !got.DeletedAt.Valid || got.DepartmentID != ti.ID {
+		t.Errorf("Expected the employee to stay removed in the same department, got %+v", got)
+	}
+}
+
+func TestAuditRepository_ReescreveAlteracoes(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	db, cleanup := setupDepartamentoTestDB(t)
+	defer cleanup()
+	repo := repository.NewAuditRepository(db)
+
+	colaborador := uuid.New()
+	base := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	entries := []*models.AuditEntry{
+		{Actor: "rh", Entity: models.AuditEntityEmployee, EntityID: colaborador, Action: models.AuditActionUpdate, CreatedAt: base.Add(time.Hour),
+			Changes: models.AuditChanges{"name": {Before: "Maria", After: "Maria Silva"}}},
+		{Actor: "rh", Entity: models.AuditEntityEmployee, EntityID: colaborador, Action: models.AuditActionCreate, CreatedAt: base,
+			Changes: models.AuditChanges{"name": {After: "Maria"}}},
+		{Actor: "rh", Entity: models.AuditEntityEmployee, EntityID: uuid.New(), Action: models.AuditActionCreate, CreatedAt: base},
+	}
+	for _, e := range entries {
+		if err := repo.Create(e); err != nil {
+			t.Fatalf("Failed to create entry: %v", err)
+		}
+	}
+
+	got, err := repo.ListByEntity(colaborador)
+	if err != nil {
+		t.Fatalf("ListByEntity failed: %v", err)
+	}
+	if len(got) != 2 || got[0].ID != entries[1].ID || got[1].ID != entries[0].ID {
+		t.Fatalf("Expected the employee's entries oldest first, got %+v", got)
+	}
+
+	got[1].Changes["name"] = models.AuditChange{Before: models.RedactedValue, After: models.RedactedValue}
+	if err := repo.UpdateChanges(got[1]); err != nil {
+		t.Fatalf("UpdateChanges failed: %v", err)
+	}
+
+	reloaded, err := repo.ListByEntity(colaborador)
+	if err != nil {
+		t.Fatalf("ListByEntity failed: %v", err)
+	}
+	if change := reloaded[1].Changes["name"]; change.Before != models.RedactedValue || change.After != models.RedactedValue {
+		t.Errorf("Expected the changes to be rewritten, got %+v", change)
+	}
+	if change := reloaded[0].Changes["name"]; change.After != "Maria" {
+		t.Errorf("Expected other entries to be left alone, got %+v", change)
+	}
+}
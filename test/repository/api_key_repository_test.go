@@ -0,0 +1,72 @@
+package repository_test
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/repository"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/goleak"
+	"gorm.io/gorm"
+)
+
+func TestAPIKeyRepository_CicloDeVida(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	db, cleanup := setupDepartamentoTestDB(t)
+	defer cleanup()
+	repo := repository.NewAPIKeyRepository(db)
+
+	antiga := &models.APIKey{Name: "folha", Prefix: "emk_aaaaaaaa", KeyHash: "hash-1", Scopes: models.Scopes{models.ScopeEmployeesRead}}
+	nova := &models.APIKey{Name: "bi", Prefix: "emk_bbbbbbbb", KeyHash: "hash-2", Scopes: models.Scopes{models.ScopeDepartmentsRead, models.ScopeDepartmentsWrite}}
+	for _, k := range []*models.APIKey{antiga, nova} {
+		if err := repo.Create(k); err != nil {
+			t.Fatalf("Failed to create key: %v", err)
+		}
+	}
+
+	if err := repo.Create(&models.APIKey{Name: "duplicada", Prefix: "emk_cccccccc", KeyHash: "hash-1", Scopes: models.Scopes{models.ScopeEmployeesRead}}); err == nil {
+		t.Errorf("Expected unique violation on key_hash")
+	}
+
+	found, err := repo.FindByHash("hash-2")
+	if err != nil {
+		t.Fatalf("FindByHash failed: %v", err)
+	}
+	if found.ID != nova.ID || len(found.Scopes) != 2 || !found.Scopes.Has(models.ScopeDepartmentsWrite) {
+		t.Errorf("Unexpected key loaded: %+v", found)
+	}
+	if _, err := repo.FindByHash("inexistente"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound, got %v", err)
+	}
+
+	usedAt := time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+	if err := repo.TouchLastUsed(nova.ID, usedAt); err != nil {
+		t.Fatalf("TouchLastUsed failed: %v", err)
+	}
+
+	first := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	if err := repo.Revoke(nova.ID, first); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if err := repo.Revoke(nova.ID, time.Now()); err != nil {
+		t.Fatalf("Second revoke failed: %v", err)
+	}
+
+	found, _ = repo.FindByID(nova.ID)
+	if found.LastUsedAt == nil || !found.LastUsedAt.Equal(usedAt) {
+		t.Errorf("Expected last_used_at %v, got %v", usedAt, found.LastUsedAt)
+	}
+	if found.RevokedAt == nil || !found.RevokedAt.Equal(first) {
+		t.Errorf("Expected the first revocation date %v to be kept, got %v", first, found.RevokedAt)
+	}
+
+	keys, err := repo.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(keys) != 2 || keys[0].ID != nova.ID || keys[1].ID != antiga.ID {
+		t.Errorf("Expected newest key first, got %+v", keys)
+	}
+}
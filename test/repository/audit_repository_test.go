@@ -0,0 +1,71 @@
+package repository_test
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/goleak"
+)
+
+func TestAuditRepository_List(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	db, cleanup := setupDepartamentoTestDB(t)
+	defer cleanup()
+	repo := repository.NewAuditRepository(db)
+
+	colaborador := uuid.New()
+	outro := uuid.New()
+	base := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	entries := []*models.AuditEntry{
+		{Actor: "rh-1", Entity: models.AuditEntityEmployee, EntityID: colaborador, Action: models.AuditActionCreate, CreatedAt: base},
+		{Actor: "rh-2", Entity: models.AuditEntityEmployee, EntityID: colaborador, Action: models.AuditActionUpdate, CreatedAt: base.Add(time.Hour),
+			Changes: models.AuditChanges{"department_id": {Before: "a", After: "b"}}},
+		{Actor: "rh-1", Entity: models.AuditEntityDepartment, EntityID: outro, Action: models.AuditActionUpdate, CreatedAt: base.Add(2 * time.Hour)},
+	}
+	for _, e := range entries {
+		if err := repo.Create(e); err != nil {
+			t.Fatalf("Failed to create entry: %v", err)
+		}
+	}
+
+	actor := "rh-1"
+	from, to := base.Add(30*time.Minute), base.Add(2*time.Hour)
+
+	testCases := []struct {
+		name     string
+		filter   models.AuditFilter
+		expected []*models.AuditEntry
+	}{
+		{name: "sem filtros, mais recentes primeiro", filter: models.AuditFilter{}, expected: []*models.AuditEntry{entries[2], entries[1], entries[0]}},
+		{name: "por entidade", filter: models.AuditFilter{EntityID: &colaborador}, expected: []*models.AuditEntry{entries[1], entries[0]}},
+		{name: "por ator", filter: models.AuditFilter{Actor: &actor}, expected: []*models.AuditEntry{entries[2], entries[0]}},
+		{name: "por período com fim exclusivo", filter: models.AuditFilter{From: &from, To: &to}, expected: []*models.AuditEntry{entries[1]}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, total, err := repo.List(tc.filter, 1, 10)
+			if err != nil {
+				t.Fatalf("List failed: %v", err)
+			}
+			if total != int64(len(tc.expected)) || len(got) != len(tc.expected) {
+				t.Fatalf("Expected %d entries, got %d (total %d)", len(tc.expected), len(got), total)
+			}
+			for i := range got {
+				if got[i].ID != tc.expected[i].ID {
+					t.Errorf("Entry %d: expected %s, got %s", i, tc.expected[i].ID, got[i].ID)
+				}
+			}
+		})
+	}
+
+	got, _, _ := repo.List(models.AuditFilter{EntityID: &colaborador}, 1, 1)
+	if c := got[0].Changes["department_id"]; c.Before != "a" || c.After != "b" {
+		t.Errorf("Expected changes to round-trip as JSON, got %+v", got[0].Changes)
+	}
+}
@@ -0,0 +1,117 @@
+package repository_test
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/repository"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/goleak"
+	"gorm.io/gorm"
+)
+
+func TestDepartmentHistoryRepository_ArvoreNaData(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	db, cleanup := setupDepartamentoTestDB(t)
+	defer cleanup()
+	repo := repository.NewDepartmentHistoryRepository(db)
+	employeeRepo := repository.NewEmployeeRepository(db)
+
+	empresa, ti, dev := uuid.New(), uuid.New(), uuid.New()
+	gerente := &models.Employee{Name: "Ana", CPF: "12345678909", DepartmentID: ti}
+	if err := employeeRepo.Create(gerente); err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	criacao := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	reorg := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	version := func(id uuid.UUID, name string, parent, manager *uuid.UUID, at time.Time) {
+		t.Helper()
+		if err := repo.CloseCurrent(id, at); err != nil {
+			t.Fatalf("CloseCurrent failed: %v", err)
+		}
+		if err := repo.Create(&models.DepartmentVersion{DepartmentID: id, Name: name, ParentDepartmentID: parent, ManagerID: manager, ValidFrom: at}); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	// Antes: Empresa > TI (gerente Ana) > Desenvolvimento
+	version(empresa, "Empresa", nil, nil, criacao)
+	version(ti, "TI", &empresa, &gerente.ID, criacao)
+	version(dev, "Desenvolvimento", &ti, nil, criacao)
+
+	// Reorganização: Desenvolvimento sobe para a Empresa como Engenharia e TI é removido
+	version(dev, "Engenharia", &empresa, nil, reorg)
+	if err := repo.CloseCurrent(ti, reorg); err != nil {
+		t.Fatalf("CloseCurrent failed: %v", err)
+	}
+	if err := employeeRepo.Delete(gerente.ID); err != nil {
+		t.Fatalf("Failed to delete manager: %v", err)
+	}
+
+	antes := reorg.Add(-time.Hour)
+	descendants, err := repo.FindDescendantsAt(empresa, antes, 0)
+	if err != nil {
+		t.Fatalf("FindDescendantsAt failed: %v", err)
+	}
+	byID := map[uuid.UUID]*models.Department{}
+	for _, d := range descendants {
+		byID[d.ID] = d
+	}
+	if len(descendants) != 2 || byID[ti] == nil || byID[dev] == nil {
+		t.Fatalf("Expected TI and Desenvolvimento before the re-org, got %+v", descendants)
+	}
+	if byID[dev].Name != "Desenvolvimento" || *byID[dev].ParentDepartmentID != ti {
+		t.Errorf("Unexpected past state of Desenvolvimento: %+v", byID[dev])
+	}
+	if byID[ti].Manager == nil || byID[ti].Manager.Name != "Ana" {
+		t.Errorf("Expected the removed manager to be loaded, got %+v", byID[ti].Manager)
+	}
+	raw, err := json.Marshal(byID[ti])
+	if err != nil {
+		t.Fatalf("Failed to marshal TI: %v", err)
+	}
+	var body struct{ Manager map[string]any }
+	if err := json.Unmarshal(raw, &body); err != nil || len(body.Manager) != 2 || body.Manager["name"] != "Ana" {
+		t.Errorf("Expected only the ID and name of the removed manager, got %s", raw)
+	}
+
+	descendants, err = repo.FindDescendantsAt(empresa, reorg, 0)
+	if err != nil {
+		t.Fatalf("FindDescendantsAt failed: %v", err)
+	}
+	if len(descendants) != 1 || descendants[0].ID != dev || descendants[0].Name != "Engenharia" {
+		t.Errorf("Expected only Engenharia after the re-org, got %+v", descendants)
+	}
+
+	if descendants, _ := repo.FindDescendantsAt(empresa, antes, 1); len(descendants) != 1 || descendants[0].ID != ti {
+		t.Errorf("Expected max depth to stop at TI, got %+v", descendants)
+	}
+
+	past, err := repo.FindAt(ti, antes)
+	if err != nil || past.Name != "TI" || past.Manager == nil {
+		t.Errorf("Expected TI with its manager before the re-org, got %+v (%v)", past, err)
+	}
+	if _, err := repo.FindAt(ti, reorg); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected TI not to exist after the re-org, got %v", err)
+	}
+	if _, err := repo.FindAt(empresa, criacao.Add(-time.Second)); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected Empresa not to exist before its creation, got %v", err)
+	}
+
+	managed, err := repo.ListByManager(gerente.ID)
+	if err != nil {
+		t.Fatalf("ListByManager failed: %v", err)
+	}
+	if len(managed) != 1 || managed[0].DepartmentID != ti || managed[0].ValidTo == nil || !managed[0].ValidTo.Equal(reorg) {
+		t.Errorf("Expected Ana to have managed TI until the re-org, got %+v", managed)
+	}
+	if removed, err := employeeRepo.FindByIDWithDeleted(gerente.ID); err != nil || !removed.DeletedAt.Valid {
+		t.Errorf("Expected to load the removed manager, got %+v (%v)", removed, err)
+	}
+}
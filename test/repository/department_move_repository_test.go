@@ -0,0 +1,48 @@
+package repository_test
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/repository"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/goleak"
+)
+
+func TestEmployeeRepository_CountByDepartmentIDs(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	db, cleanup := setupDepartamentoTestDB(t)
+	defer cleanup()
+	deptRepo := repository.NewDepartmentRepository(db)
+	repo := repository.NewEmployeeRepository(db)
+
+	ti := &models.Department{Name: "TI"}
+	suporte := &models.Department{Name: "Suporte"}
+	vazio := &models.Department{Name: "Vazio"}
+	for _, d := range []*models.Department{ti, suporte, vazio} {
+		if err := deptRepo.Create(d); err != nil {
+			t.Fatalf("Failed to create department: %v", err)
+		}
+	}
+	maria := &models.Employee{Name: "Maria", CPF: "12345678909", DepartmentID: ti.ID}
+	joao := &models.Employee{Name: "João", CPF: "98765432100", DepartmentID: ti.ID}
+	ana := &models.Employee{Name: "Ana", CPF: "52998224725", DepartmentID: suporte.ID}
+	for _, e := range []*models.Employee{maria, joao, ana} {
+		if err := repo.Create(e); err != nil {
+			t.Fatalf("Failed to create employee: %v", err)
+		}
+	}
+	if err := repo.Delete(joao.ID); err != nil {
+		t.Fatalf("Failed to delete employee: %v", err)
+	}
+
+	// Colaboradores removidos não contam
+	counts, err := repo.CountByDepartmentIDs([]uuid.UUID{ti.ID, suporte.ID, vazio.ID})
+	if err != nil {
+		t.Fatalf("CountByDepartmentIDs failed: %v", err)
+	}
+	if len(counts) != 2 || counts[ti.ID] != 1 || counts[suporte.ID] != 1 {
+		t.Errorf("Expected one employee in TI and one in Suporte, got %v", counts)
+	}
+}
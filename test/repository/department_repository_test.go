@@ -2,6 +2,9 @@ package repository_test
 
 import (
 	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/repository"
+	"ManageEmployeesandDepartments/internal/utils"
+	"errors"
 	"testing"
 
 	"github.com/google/uuid"
@@ -11,163 +14,6 @@ import (
 	"gorm.io/gorm/logger"
 )
 
-// DepartamentoRepository interface para testes
-type DepartamentoRepository interface {
-	Create(depto *models.Department) error
-	FindByID(id uuid.UUID) (*models.Department, error)
-	FindByIDComGerente(id uuid.UUID) (*models.Department, error)
-	FindSubDepartamentos(superiorID uuid.UUID) ([]*models.Department, error)
-	Update(depto *models.Department) error
-	Delete(id uuid.UUID) error
-	CountSubDepartamentos(id uuid.UUID) (int64, error)
-	IsGerente(colaboradorID uuid.UUID) (bool, error)
-	FindByManagerID(gerenteID uuid.UUID) ([]*models.Department, error)
-	IsSubordinado(superiorID, subordinadoID uuid.UUID) (bool, error)
-	FindAllSubordinadoIDs(id uuid.UUID) ([]uuid.UUID, error)
-	List(nome, gerenteNome *string, superiorID *uuid.UUID, pagina, tamanhoPagina int) ([]*models.Department, error)
-}
-
-// Implementação do repositório para SQLite
-type departamentoRepository struct {
-	db *gorm.DB
-}
-
-func NewDepartamentoRepository(db *gorm.DB) DepartamentoRepository {
-	return &departamentoRepository{db: db}
-}
-
-func (r *departamentoRepository) Create(depto *models.Department) error {
-	return r.db.Create(depto).Error
-}
-
-func (r *departamentoRepository) FindByID(id uuid.UUID) (*models.Department, error) {
-	var depto models.Department
-	if err := r.db.First(&depto, "id = ?", id).Error; err != nil {
-		return nil, err
-	}
-	return &depto, nil
-}
-
-func (r *departamentoRepository) FindByIDComGerente(id uuid.UUID) (*models.Department, error) {
-	var depto models.Department
-	if err := r.db.Preload("Manager").First(&depto, "id = ?", id).Error; err != nil {
-		return nil, err
-	}
-	return &depto, nil
-}
-
-func (r *departamentoRepository) FindSubDepartamentos(superiorID uuid.UUID) ([]*models.Department, error) {
-	var deptos []*models.Department
-	if err := r.db.Where("parent_department_id = ?", superiorID).Find(&deptos).Error; err != nil {
-		return nil, err
-	}
-	return deptos, nil
-}
-
-func (r *departamentoRepository) Update(depto *models.Department) error {
-	return r.db.Save(depto).Error
-}
-
-func (r *departamentoRepository) Delete(id uuid.UUID) error {
-	return r.db.Where("id = ?", id).Delete(&models.Department{}).Error
-}
-
-func (r *departamentoRepository) CountSubDepartamentos(id uuid.UUID) (int64, error) {
-	var count int64
-	err := r.db.Model(&models.Department{}).Where("parent_department_id = ?", id).Count(&count).Error
-	return count, err
-}
-
-func (r *departamentoRepository) IsGerente(colaboradorID uuid.UUID) (bool, error) {
-	var count int64
-	err := r.db.Model(&models.Department{}).Where("manager_id = ?", colaboradorID).Count(&count).Error
-	return count > 0, err
-}
-
-func (r *departamentoRepository) FindByManagerID(gerenteID uuid.UUID) ([]*models.Department, error) {
-	var deptos []*models.Department
-	err := r.db.Where("manager_id = ?", gerenteID).Find(&deptos).Error
-	return deptos, err
-}
-
-// SQLite versão simplificada do IsSubordinado - sem WITH RECURSIVE
-func (r *departamentoRepository) IsSubordinado(superiorID, subordinadoID uuid.UUID) (bool, error) {
-	// Busca simplificada - verifica apenas relação direta e um nível de profundidade
-	var deptos []*models.Department
-
-	// Busca todos os departamentos filhos do superior
-	err := r.db.Where("parent_department_id = ?", superiorID).Find(&deptos).Error
-	if err != nil {
-		return false, err
-	}
-
-	// Verifica relação direta
-	for _, depto := range deptos {
-		if depto.ID == subordinadoID {
-			return true, nil
-		}
-		// Verifica um nível mais profundo
-		var subDeptos []*models.Department
-		err := r.db.Where("parent_department_id = ?", depto.ID).Find(&subDeptos).Error
-		if err == nil {
-			for _, subDepto := range subDeptos {
-				if subDepto.ID == subordinadoID {
-					return true, nil
-				}
-			}
-		}
-	}
-
-	return false, nil
-}
-
-func (r *departamentoRepository) FindAllSubordinadoIDs(id uuid.UUID) ([]uuid.UUID, error) {
-	var ids []uuid.UUID
-
-	// Busca filhos diretos
-	var deptos []*models.Department
-	err := r.db.Where("parent_department_id = ?", id).Find(&deptos).Error
-	if err != nil {
-		return nil, err
-	}
-
-	ids = append(ids, id) // Inclui o próprio departamento
-
-	for _, depto := range deptos {
-		ids = append(ids, depto.ID)
-		// Recursivamente busca sub-departamentos (limitado a 2 níveis por simplicidade)
-		var subDeptos []*models.Department
-		err := r.db.Where("parent_department_id = ?", depto.ID).Find(&subDeptos).Error
-		if err == nil {
-			for _, subDepto := range subDeptos {
-				ids = append(ids, subDepto.ID)
-			}
-		}
-	}
-
-	return ids, nil
-}
-
-func (r *departamentoRepository) List(nome, gerenteNome *string, superiorID *uuid.UUID, pagina, tamanhoPagina int) ([]*models.Department, error) {
-	var deptos []*models.Department
-	query := r.db.Model(&models.Department{}).Preload("Manager")
-
-	if nome != nil {
-		// SQLite usa LIKE ao invés de ILIKE, com LOWER para case-insensitive
-		query = query.Where("LOWER(departments.name) LIKE LOWER(?)", "%"+*nome+"%")
-	}
-	if superiorID != nil {
-		query = query.Where("parent_department_id = ?", *superiorID)
-	}
-	if gerenteNome != nil {
-		query = query.Joins("INNER JOIN colaboradores g ON g.id = departamentos.manager_id AND LOWER(g.nome) LIKE LOWER(?)", "%"+*gerenteNome+"%")
-	}
-
-	offset := (pagina - 1) * tamanhoPagina
-	err := query.Limit(tamanhoPagina).Offset(offset).Find(&deptos).Error
-	return deptos, err
-}
-
 func setupDepartamentoTestDB(t *testing.T) (*gorm.DB, func()) {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
 		Logger: logger.Discard,
@@ -177,7 +23,7 @@ func setupDepartamentoTestDB(t *testing.T) (*gorm.DB, func()) {
 	}
 
 	// Auto migrate tables
-	err = db.AutoMigrate(&models.Employee{}, &models.Department{})
+	err = db.AutoMigrate(&models.Employee{}, &models.Department{}, &models.APIKey{}, &models.AuditEntry{}, &models.DepartmentAssignment{}, &models.DepartmentVersion{})
 	if err != nil {
 		t.Fatalf("Failed to migrate test database: %v", err)
 	}
@@ -199,7 +45,7 @@ func TestDepartamentoRepository_Create(t *testing.T) {
 	db, cleanup := setupDepartamentoTestDB(t)
 	defer cleanup()
 
-	repo := NewDepartamentoRepository(db)
+	repo := repository.NewDepartmentRepository(db)
 
 	testCases := []struct {
 		name         string
@@ -256,7 +102,7 @@ func TestDepartamentoRepository_FindByID(t *testing.T) {
 	db, cleanup := setupDepartamentoTestDB(t)
 	defer cleanup()
 
-	repo := NewDepartamentoRepository(db)
+	repo := repository.NewDepartmentRepository(db)
 
 	// Criar departamento
 	departamento := &models.Department{
@@ -319,7 +165,7 @@ func TestDepartamentoRepository_FindByIDComGerente(t *testing.T) {
 	db, cleanup := setupDepartamentoTestDB(t)
 	defer cleanup()
 
-	repo := NewDepartamentoRepository(db)
+	repo := repository.NewDepartmentRepository(db)
 
 	// Criar departamento primeiro
 	departamento := &models.Department{
@@ -333,9 +179,9 @@ func TestDepartamentoRepository_FindByIDComGerente(t *testing.T) {
 
 	// Criar colaborador como gerente
 	gerente := &models.Employee{
-		ID:             uuid.New(),
-		Name:           "João Silva",
-		CPF:            "12345678901",
+		ID:           uuid.New(),
+		Name:         "João Silva",
+		CPF:          "12345678901",
 		DepartmentID: departamento.ID,
 	}
 	err = db.Create(gerente).Error
@@ -350,7 +196,7 @@ func TestDepartamentoRepository_FindByIDComGerente(t *testing.T) {
 		t.Fatalf("Failed to update departamento: %v", err)
 	}
 
-	result, err := repo.FindByIDComGerente(departamento.ID)
+	result, err := repo.FindByIDWithManager(departamento.ID)
 	if err != nil {
 		t.Fatalf("Failed to find departamento with gerente: %v", err)
 	}
@@ -368,7 +214,7 @@ func TestDepartamentoRepository_Update(t *testing.T) {
 	db, cleanup := setupDepartamentoTestDB(t)
 	defer cleanup()
 
-	repo := NewDepartamentoRepository(db)
+	repo := repository.NewDepartmentRepository(db)
 
 	// Criar departamento
 	departamento := &models.Department{
@@ -405,7 +251,7 @@ func TestDepartamentoRepository_Delete(t *testing.T) {
 	db, cleanup := setupDepartamentoTestDB(t)
 	defer cleanup()
 
-	repo := NewDepartamentoRepository(db)
+	repo := repository.NewDepartmentRepository(db)
 
 	// Criar departamento
 	departamento := &models.Department{
@@ -430,13 +276,58 @@ func TestDepartamentoRepository_Delete(t *testing.T) {
 	}
 }
 
+func TestDepartamentoRepository_FindAll(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	db, cleanup := setupDepartamentoTestDB(t)
+	defer cleanup()
+
+	repo := repository.NewDepartmentRepository(db)
+
+	ti := &models.Department{Name: "TI"}
+	rh := &models.Department{Name: "RH"}
+	for _, d := range []*models.Department{ti, rh} {
+		if err := repo.Create(d); err != nil {
+			t.Fatalf("Failed to create departamento: %v", err)
+		}
+	}
+	if err := repo.Delete(rh.ID); err != nil {
+		t.Fatalf("Failed to delete departamento: %v", err)
+	}
+
+	// Departamentos removidos ficam de fora
+	depts, err := repo.FindAll()
+	if err != nil {
+		t.Fatalf("FindAll failed: %v", err)
+	}
+	if len(depts) != 1 || depts[0].ID != ti.ID {
+		t.Errorf("Expected only TI, got %+v", depts)
+	}
+
+	locked, err := repo.FindAllForUpdate()
+	if err != nil {
+		t.Fatalf("FindAllForUpdate failed: %v", err)
+	}
+	if len(locked) != 1 || locked[0].ID != ti.ID {
+		t.Errorf("Expected only TI to be locked, got %+v", locked)
+	}
+
+	// Um departamento removido não pode receber colaboradores
+	if shared, err := repo.FindByIDForShare(ti.ID); err != nil || shared.ID != ti.ID {
+		t.Errorf("Expected TI, got %+v (%v)", shared, err)
+	}
+	if _, err := repo.FindByIDForShare(rh.ID); err == nil {
+		t.Error("Expected a removed departamento not to be found")
+	}
+}
+
 func TestDepartamentoRepository_FindSubDepartamentos(t *testing.T) {
 	defer goleak.VerifyNone(t)
 
 	db, cleanup := setupDepartamentoTestDB(t)
 	defer cleanup()
 
-	repo := NewDepartamentoRepository(db)
+	repo := repository.NewDepartmentRepository(db)
 
 	// Criar departamento pai
 	empresa := &models.Department{
@@ -450,13 +341,13 @@ func TestDepartamentoRepository_FindSubDepartamentos(t *testing.T) {
 
 	// Criar departamentos filhos
 	ti := &models.Department{
-		ID:                     uuid.New(),
-		Name:                   "TI",
+		ID:                 uuid.New(),
+		Name:               "TI",
 		ParentDepartmentID: &empresa.ID,
 	}
 	rh := &models.Department{
-		ID:                     uuid.New(),
-		Name:                   "RH",
+		ID:                 uuid.New(),
+		Name:               "RH",
 		ParentDepartmentID: &empresa.ID,
 	}
 
@@ -470,7 +361,7 @@ func TestDepartamentoRepository_FindSubDepartamentos(t *testing.T) {
 	}
 
 	// Buscar sub-departamentos
-	subs, err := repo.FindSubDepartamentos(empresa.ID)
+	subs, err := repo.FindSubDepartments(empresa.ID)
 	if err != nil {
 		t.Fatalf("Failed to find sub departamentos: %v", err)
 	}
@@ -486,7 +377,7 @@ func TestDepartamentoRepository_CountSubDepartamentos(t *testing.T) {
 	db, cleanup := setupDepartamentoTestDB(t)
 	defer cleanup()
 
-	repo := NewDepartamentoRepository(db)
+	repo := repository.NewDepartmentRepository(db)
 
 	// Criar departamento pai
 	empresa := &models.Department{
@@ -501,8 +392,8 @@ func TestDepartamentoRepository_CountSubDepartamentos(t *testing.T) {
 	// Criar 3 departamentos filhos
 	for i := 0; i < 3; i++ {
 		depto := &models.Department{
-			ID:                     uuid.New(),
-			Name:                   "Depto " + string(rune(i+1+'0')),
+			ID:                 uuid.New(),
+			Name:               "Depto " + string(rune(i+1+'0')),
 			ParentDepartmentID: &empresa.ID,
 		}
 		err = repo.Create(depto)
@@ -511,7 +402,7 @@ func TestDepartamentoRepository_CountSubDepartamentos(t *testing.T) {
 		}
 	}
 
-	count, err := repo.CountSubDepartamentos(empresa.ID)
+	count, err := repo.CountSubDepartments(empresa.ID)
 	if err != nil {
 		t.Fatalf("Failed to count sub departamentos: %v", err)
 	}
@@ -527,7 +418,7 @@ func TestDepartamentoRepository_List(t *testing.T) {
 	db, cleanup := setupDepartamentoTestDB(t)
 	defer cleanup()
 
-	repo := NewDepartamentoRepository(db)
+	repo := repository.NewDepartmentRepository(db)
 
 	// Criar departamentos
 	departamentos := []*models.Department{
@@ -560,6 +451,7 @@ func TestDepartamentoRepository_List(t *testing.T) {
 		pagina        int
 		tamanhoPagina int
 		expectedCount int
+		expectedTotal int64
 		expectedError bool
 	}{
 		{
@@ -567,6 +459,7 @@ func TestDepartamentoRepository_List(t *testing.T) {
 			pagina:        1,
 			tamanhoPagina: 10,
 			expectedCount: 3,
+			expectedTotal: 3,
 			expectedError: false,
 		},
 		{
@@ -575,6 +468,7 @@ func TestDepartamentoRepository_List(t *testing.T) {
 			pagina:        1,
 			tamanhoPagina: 10,
 			expectedCount: 1,
+			expectedTotal: 1,
 			expectedError: false,
 		},
 		{
@@ -582,13 +476,22 @@ func TestDepartamentoRepository_List(t *testing.T) {
 			pagina:        1,
 			tamanhoPagina: 2,
 			expectedCount: 2,
+			expectedTotal: 3,
+			expectedError: false,
+		},
+		{
+			name:          "última página",
+			pagina:        2,
+			tamanhoPagina: 2,
+			expectedCount: 1,
+			expectedTotal: 3,
 			expectedError: false,
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result, err := repo.List(tc.nomeFilter, tc.gerenteNome, tc.superiorID, tc.pagina, tc.tamanhoPagina)
+			result, total, err := repo.List(tc.nomeFilter, tc.gerenteNome, tc.superiorID, models.Sort{}, tc.pagina, tc.tamanhoPagina)
 
 			if tc.expectedError {
 				if err == nil {
@@ -601,18 +504,241 @@ func TestDepartamentoRepository_List(t *testing.T) {
 				if len(result) != tc.expectedCount {
 					t.Errorf("Expected %d departamentos, got %d", tc.expectedCount, len(result))
 				}
+				if total != tc.expectedTotal {
+					t.Errorf("Expected total %d, got %d", tc.expectedTotal, total)
+				}
+			}
+		})
+	}
+}
+
+func TestDepartamentoRepository_List_FiltroNomeGerente(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	db, cleanup := setupDepartamentoTestDB(t)
+	defer cleanup()
+
+	repo := repository.NewDepartmentRepository(db)
+
+	empresa := &models.Department{ID: uuid.New(), Name: "Empresa"}
+	ti := &models.Department{ID: uuid.New(), Name: "TI", ParentDepartmentID: &empresa.ID}
+	rh := &models.Department{ID: uuid.New(), Name: "RH", ParentDepartmentID: &empresa.ID}
+	semGerente := &models.Department{ID: uuid.New(), Name: "TI Suporte", ParentDepartmentID: &empresa.ID}
+	for _, depto := range []*models.Department{empresa, ti, rh, semGerente} {
+		if err := repo.Create(depto); err != nil {
+			t.Fatalf("Failed to create departamento %s: %v", depto.Name, err)
+		}
+	}
+
+	gerentes := map[*models.Department]*models.Employee{
+		empresa: {ID: uuid.New(), Name: "Maria Souza", CPF: "11111111111", DepartmentID: empresa.ID},
+		ti:      {ID: uuid.New(), Name: "João Silva", CPF: "22222222222", DepartmentID: ti.ID},
+		rh:      {ID: uuid.New(), Name: "Ana Silveira", CPF: "33333333333", DepartmentID: rh.ID},
+	}
+	for depto, gerente := range gerentes {
+		if err := db.Create(gerente).Error; err != nil {
+			t.Fatalf("Failed to create gerente %s: %v", gerente.Name, err)
+		}
+		depto.ManagerID = &gerente.ID
+		if err := repo.Update(depto); err != nil {
+			t.Fatalf("Failed to update departamento %s: %v", depto.Name, err)
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		nomeFilter    *string
+		gerenteNome   *string
+		superiorID    *uuid.UUID
+		expectedNames []string
+	}{
+		{
+			name:          "nome do gerente parcial e sem diferenciar maiúsculas",
+			gerenteNome:   stringPtr("SILV"),
+			expectedNames: []string{"TI", "RH"},
+		},
+		{
+			name:          "combinado com nome do departamento",
+			nomeFilter:    stringPtr("ti"),
+			gerenteNome:   stringPtr("silv"),
+			expectedNames: []string{"TI"},
+		},
+		{
+			name:          "combinado com departamento superior",
+			gerenteNome:   stringPtr("maria"),
+			superiorID:    &empresa.ID,
+			expectedNames: []string{},
+		},
+		{
+			name:          "nenhum gerente corresponde",
+			gerenteNome:   stringPtr("pedro"),
+			expectedNames: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, total, err := repo.List(tc.nomeFilter, tc.gerenteNome, tc.superiorID, models.Sort{}, 1, 10)
+			if err != nil {
+				t.Fatalf("Failed to list departamentos: %v", err)
+			}
+			if total != int64(len(tc.expectedNames)) || len(result) != len(tc.expectedNames) {
+				t.Fatalf("Expected %d departamentos, got %d (total %d)", len(tc.expectedNames), len(result), total)
+			}
+
+			for _, depto := range result {
+				found := false
+				for _, name := range tc.expectedNames {
+					found = found || depto.Name == name
+				}
+				if !found {
+					t.Errorf("Unexpected departamento %s", depto.Name)
+				}
+				if depto.Manager == nil || depto.Manager.ID != *depto.ManagerID {
+					t.Errorf("Expected gerente preloaded for %s", depto.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestDepartamentoRepository_List_Ordenacao(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	db, cleanup := setupDepartamentoTestDB(t)
+	defer cleanup()
+
+	repo := repository.NewDepartmentRepository(db)
+
+	// Dois "Vendas" para exercitar o desempate pelo id
+	nomes := []string{"Vendas", "Compras", "Vendas", "Auditoria"}
+	departamentos := make([]*models.Department, 0, len(nomes))
+	for _, nome := range nomes {
+		depto := &models.Department{Name: nome}
+		if err := repo.Create(depto); err != nil {
+			t.Fatalf("Failed to create departamento %s: %v", nome, err)
+		}
+		departamentos = append(departamentos, depto)
+	}
+
+	gerentes := []*models.Employee{
+		{Name: "Zuleica", CPF: "11111111111", DepartmentID: departamentos[1].ID},
+		{Name: "Bruno", CPF: "22222222222", DepartmentID: departamentos[3].ID},
+	}
+	for i, gerente := range gerentes {
+		if err := db.Create(gerente).Error; err != nil {
+			t.Fatalf("Failed to create gerente: %v", err)
+		}
+		depto := departamentos[1+2*i]
+		depto.ManagerID = &gerente.ID
+		if err := repo.Update(depto); err != nil {
+			t.Fatalf("Failed to update departamento: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		sort        models.Sort
+		expectedIDs []uuid.UUID
+		expectedErr error
+	}{
+		{
+			name:        "padrão pela ordem de criação",
+			expectedIDs: []uuid.UUID{departamentos[0].ID, departamentos[1].ID, departamentos[2].ID, departamentos[3].ID},
+		},
+		{
+			name:        "nome ascendente com desempate pelo id",
+			sort:        models.Sort{Field: "name"},
+			expectedIDs: []uuid.UUID{departamentos[3].ID, departamentos[1].ID, departamentos[0].ID, departamentos[2].ID},
+		},
+		{
+			name:        "nome descendente com desempate pelo id",
+			sort:        models.Sort{Field: "name", Desc: true},
+			expectedIDs: []uuid.UUID{departamentos[2].ID, departamentos[0].ID, departamentos[1].ID, departamentos[3].ID},
+		},
+		{
+			name:        "nome do gerente descendente",
+			sort:        models.Sort{Field: "manager", Desc: true},
+			expectedIDs: []uuid.UUID{departamentos[1].ID, departamentos[3].ID},
+		},
+		{
+			name:        "campo fora da lista permitida",
+			sort:        models.Sort{Field: "manager_id; DROP TABLE departments"},
+			expectedErr: utils.ErrInvalidSort,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, _, err := repo.List(nil, nil, nil, tc.sort, 1, 10)
+			if tc.expectedErr != nil {
+				if !errors.Is(err, tc.expectedErr) {
+					t.Fatalf("Expected error %v, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to list departamentos: %v", err)
+			}
+
+			// Departamentos sem gerente ficam em posição dependente do banco
+			got := make([]uuid.UUID, 0, len(result))
+			for _, depto := range result {
+				if tc.sort.Field != "manager" || depto.ManagerID != nil {
+					got = append(got, depto.ID)
+				}
+			}
+			if len(got) != len(tc.expectedIDs) {
+				t.Fatalf("Expected %d departamentos, got %d", len(tc.expectedIDs), len(got))
+			}
+			for i := range got {
+				if got[i] != tc.expectedIDs[i] {
+					t.Errorf("Position %d: expected %v, got %v", i, tc.expectedIDs[i], got[i])
+				}
 			}
 		})
 	}
 }
 
+func TestListagens_OrdenacaoInvalidaNaoConsultaOBanco(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	db, cleanup := setupDepartamentoTestDB(t)
+	defer cleanup()
+
+	queries := 0
+	if err := db.Callback().Query().Before("gorm:query").Register("test:count_queries", func(*gorm.DB) { queries++ }); err != nil {
+		t.Fatalf("Failed to register callback: %v", err)
+	}
+	deptRepo := repository.NewDepartmentRepository(db)
+	employeeRepo := repository.NewEmployeeRepository(db)
+	invalid := models.Sort{Field: "salary"}
+
+	if _, _, err := deptRepo.List(nil, nil, nil, invalid, 1, 10); !errors.Is(err, utils.ErrInvalidSort) {
+		t.Errorf("Expected %v from departments, got %v", utils.ErrInvalidSort, err)
+	}
+	if _, _, err := employeeRepo.List(models.EmployeeFilter{}, invalid, 1, 10); !errors.Is(err, utils.ErrInvalidSort) {
+		t.Errorf("Expected %v from employees, got %v", utils.ErrInvalidSort, err)
+	}
+	if _, _, err := employeeRepo.ListByCursor(models.EmployeeFilter{}, invalid, nil, false, 10); !errors.Is(err, utils.ErrInvalidSort) {
+		t.Errorf("Expected %v from the cursor listing, got %v", utils.ErrInvalidSort, err)
+	}
+	cursor := &models.Cursor{Sort: "created_at", Key: "ontem", ID: uuid.New()}
+	if _, _, err := employeeRepo.ListByCursor(models.EmployeeFilter{}, models.Sort{Field: "created_at"}, cursor, false, 10); !errors.Is(err, utils.ErrInvalidCursor) {
+		t.Errorf("Expected %v, got %v", utils.ErrInvalidCursor, err)
+	}
+	if queries != 0 {
+		t.Errorf("Expected no query for an invalid sort or cursor, got %d", queries)
+	}
+}
+
 func TestDepartamentoRepository_IsSubordinado(t *testing.T) {
 	defer goleak.VerifyNone(t)
 
 	db, cleanup := setupDepartamentoTestDB(t)
 	defer cleanup()
 
-	repo := NewDepartamentoRepository(db)
+	repo := repository.NewDepartmentRepository(db)
 
 	// Criar hierarquia: Empresa -> TI -> Desenvolvimento
 	empresa := &models.Department{
@@ -620,13 +746,13 @@ func TestDepartamentoRepository_IsSubordinado(t *testing.T) {
 		Name: "Empresa",
 	}
 	ti := &models.Department{
-		ID:                     uuid.New(),
-		Name:                   "TI",
+		ID:                 uuid.New(),
+		Name:               "TI",
 		ParentDepartmentID: &empresa.ID,
 	}
 	desenvolvimento := &models.Department{
-		ID:                     uuid.New(),
-		Name:                   "Desenvolvimento",
+		ID:                 uuid.New(),
+		Name:               "Desenvolvimento",
 		ParentDepartmentID: &ti.ID,
 	}
 
@@ -683,7 +809,7 @@ func TestDepartamentoRepository_IsSubordinado(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result, err := repo.IsSubordinado(tc.superiorID, tc.subordinadoID)
+			result, err := repo.IsSubordinate(tc.superiorID, tc.subordinadoID)
 			if err != nil {
 				t.Errorf("Unexpected error: %v", err)
 			}
@@ -694,13 +820,199 @@ func TestDepartamentoRepository_IsSubordinado(t *testing.T) {
 	}
 }
 
+func TestDepartamentoRepository_IsSubordinado_CicloNaoTrava(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	db, cleanup := setupDepartamentoTestDB(t)
+	defer cleanup()
+
+	repo := repository.NewDepartmentRepository(db)
+
+	// Hierarquia corrompida: A -> B -> A
+	a := &models.Department{ID: uuid.New(), Name: "A"}
+	b := &models.Department{ID: uuid.New(), Name: "B", ParentDepartmentID: &a.ID}
+	if err := repo.Create(a); err != nil {
+		t.Fatalf("Failed to create A: %v", err)
+	}
+	if err := repo.Create(b); err != nil {
+		t.Fatalf("Failed to create B: %v", err)
+	}
+	a.ParentDepartmentID = &b.ID
+	if err := repo.Update(a); err != nil {
+		t.Fatalf("Failed to update A: %v", err)
+	}
+
+	outsider := uuid.New()
+	result, err := repo.IsSubordinate(a.ID, outsider)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result {
+		t.Errorf("Expected false for a department outside the hierarchy")
+	}
+
+	ids, err := repo.FindAllSubordinateIDs(a.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(ids) != 2 {
+		t.Errorf("Expected 2 IDs, got %d", len(ids))
+	}
+}
+
+func TestDepartamentoRepository_FindAllSubordinadoIDs(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	db, cleanup := setupDepartamentoTestDB(t)
+	defer cleanup()
+
+	repo := repository.NewDepartmentRepository(db)
+
+	// Empresa -> TI -> Desenvolvimento -> Backend, Empresa -> RH
+	empresa := &models.Department{ID: uuid.New(), Name: "Empresa"}
+	ti := &models.Department{ID: uuid.New(), Name: "TI", ParentDepartmentID: &empresa.ID}
+	dev := &models.Department{ID: uuid.New(), Name: "Desenvolvimento", ParentDepartmentID: &ti.ID}
+	backend := &models.Department{ID: uuid.New(), Name: "Backend", ParentDepartmentID: &dev.ID}
+	rh := &models.Department{ID: uuid.New(), Name: "RH", ParentDepartmentID: &empresa.ID}
+	for _, depto := range []*models.Department{empresa, ti, dev, backend, rh} {
+		if err := repo.Create(depto); err != nil {
+			t.Fatalf("Failed to create %s: %v", depto.Name, err)
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		id       uuid.UUID
+		expected []uuid.UUID
+	}{
+		{
+			name:     "raiz retorna a árvore inteira",
+			id:       empresa.ID,
+			expected: []uuid.UUID{empresa.ID, ti.ID, dev.ID, backend.ID, rh.ID},
+		},
+		{
+			name:     "subárvore intermediária",
+			id:       ti.ID,
+			expected: []uuid.UUID{ti.ID, dev.ID, backend.ID},
+		},
+		{
+			name:     "folha retorna apenas ela mesma",
+			id:       backend.ID,
+			expected: []uuid.UUID{backend.ID},
+		},
+		{
+			name:     "departamento inexistente",
+			id:       uuid.New(),
+			expected: []uuid.UUID{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ids, err := repo.FindAllSubordinateIDs(tc.id)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(ids) != len(tc.expected) {
+				t.Fatalf("Expected %d IDs, got %d", len(tc.expected), len(ids))
+			}
+			found := make(map[uuid.UUID]bool, len(ids))
+			for _, id := range ids {
+				found[id] = true
+			}
+			for _, id := range tc.expected {
+				if !found[id] {
+					t.Errorf("Expected ID %v in result", id)
+				}
+			}
+		})
+	}
+}
+
+func TestDepartamentoRepository_FindDescendants(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	db, cleanup := setupDepartamentoTestDB(t)
+	defer cleanup()
+
+	repo := repository.NewDepartmentRepository(db)
+
+	// Empresa -> TI -> Desenvolvimento
+	empresa := &models.Department{ID: uuid.New(), Name: "Empresa"}
+	ti := &models.Department{ID: uuid.New(), Name: "TI", ParentDepartmentID: &empresa.ID}
+	dev := &models.Department{ID: uuid.New(), Name: "Desenvolvimento", ParentDepartmentID: &ti.ID}
+	for _, depto := range []*models.Department{empresa, ti, dev} {
+		if err := repo.Create(depto); err != nil {
+			t.Fatalf("Failed to create %s: %v", depto.Name, err)
+		}
+	}
+
+	gerente := &models.Employee{ID: uuid.New(), Name: "Ana Gerente", CPF: "52998224725", DepartmentID: dev.ID}
+	if err := db.Create(gerente).Error; err != nil {
+		t.Fatalf("Failed to create gerente: %v", err)
+	}
+	dev.ManagerID = &gerente.ID
+	if err := repo.Update(dev); err != nil {
+		t.Fatalf("Failed to update desenvolvimento: %v", err)
+	}
+
+	all, err := repo.FindDescendants(empresa.ID, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("Expected 2 descendants, got %d", len(all))
+	}
+	for _, depto := range all {
+		if depto.ID == dev.ID && (depto.Manager == nil || depto.Manager.ID != gerente.ID) {
+			t.Errorf("Expected manager to be preloaded on Desenvolvimento")
+		}
+	}
+
+	direct, err := repo.FindDescendants(empresa.ID, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(direct) != 1 || direct[0].ID != ti.ID {
+		t.Errorf("Expected only TI with max depth 1, got %d departments", len(direct))
+	}
+}
+
+func TestDepartamentoRepository_LockAncestors(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	db, cleanup := setupDepartamentoTestDB(t)
+	defer cleanup()
+
+	repo := repository.NewDepartmentRepository(db)
+
+	empresa := &models.Department{ID: uuid.New(), Name: "Empresa"}
+	ti := &models.Department{ID: uuid.New(), Name: "TI", ParentDepartmentID: &empresa.ID}
+	for _, depto := range []*models.Department{empresa, ti} {
+		if err := repo.Create(depto); err != nil {
+			t.Fatalf("Failed to create %s: %v", depto.Name, err)
+		}
+	}
+
+	err := repo.Transaction(func(tx *gorm.DB) error {
+		txRepo := repo.WithTx(tx)
+		if _, err := txRepo.FindByIDForUpdate(ti.ID); err != nil {
+			return err
+		}
+		return txRepo.LockAncestors(ti.ID)
+	})
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
 func TestDepartamentoRepository_FindByManagerID(t *testing.T) {
 	defer goleak.VerifyNone(t)
 
 	db, cleanup := setupDepartamentoTestDB(t)
 	defer cleanup()
 
-	repo := NewDepartamentoRepository(db)
+	repo := repository.NewDepartmentRepository(db)
 
 	// Criar colaborador como gerente
 	gerente := &models.Employee{
@@ -749,7 +1061,7 @@ func BenchmarkDepartamentoRepository_Create(b *testing.B) {
 	db, cleanup := setupDepartamentoTestDB(&testing.T{})
 	defer cleanup()
 
-	repo := NewDepartamentoRepository(db)
+	repo := repository.NewDepartmentRepository(db)
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -765,7 +1077,7 @@ func BenchmarkDepartamentoRepository_FindByID(b *testing.B) {
 	db, cleanup := setupDepartamentoTestDB(&testing.T{})
 	defer cleanup()
 
-	repo := NewDepartamentoRepository(db)
+	repo := repository.NewDepartmentRepository(db)
 
 	// Criar departamento
 	departamento := &models.Department{
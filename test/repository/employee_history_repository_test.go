@@ -0,0 +1,74 @@
+package repository_test
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/goleak"
+)
+
+func TestEmployeeHistoryRepository_Transferencias(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	db, cleanup := setupDepartamentoTestDB(t)
+	defer cleanup()
+	deptRepo := repository.NewDepartmentRepository(db)
+	repo := repository.NewEmployeeHistoryRepository(db)
+
+	ti := &models.Department{Name: "TI"}
+	rh := &models.Department{Name: "RH"}
+	for _, d := range []*models.Department{ti, rh} {
+		if err := deptRepo.Create(d); err != nil {
+			t.Fatalf("Failed to create department: %v", err)
+		}
+	}
+
+	employeeID := uuid.New()
+	hired := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	moved := time.Date(2024, 6, 1, 14, 30, 0, 0, time.UTC)
+
+	if err := repo.CloseCurrent(employeeID, hired); err != nil {
+		t.Fatalf("Closing without a current assignment should be a no-op: %v", err)
+	}
+	if err := repo.Create(&models.DepartmentAssignment{EmployeeID: employeeID, DepartmentID: ti.ID, EffectiveFrom: hired}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if err := repo.CloseCurrent(employeeID, moved); err != nil {
+		t.Fatalf("CloseCurrent failed: %v", err)
+	}
+	if err := repo.Create(&models.DepartmentAssignment{EmployeeID: employeeID, DepartmentID: rh.ID, EffectiveFrom: moved}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	// Outro colaborador não aparece no histórico
+	if err := repo.Create(&models.DepartmentAssignment{EmployeeID: uuid.New(), DepartmentID: ti.ID, EffectiveFrom: hired}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// O nome continua disponível depois que o departamento é removido
+	if err := deptRepo.Delete(ti.ID); err != nil {
+		t.Fatalf("Failed to delete department: %v", err)
+	}
+
+	history, err := repo.ListByEmployee(employeeID)
+	if err != nil {
+		t.Fatalf("ListByEmployee failed: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 assignments, got %d", len(history))
+	}
+
+	first, current := history[0], history[1]
+	if first.DepartmentID != ti.ID || first.DepartmentName != "TI" || first.EffectiveTo == nil || !first.EffectiveTo.Equal(moved) {
+		t.Errorf("Unexpected first assignment: %+v", first)
+	}
+	if current.DepartmentID != rh.ID || current.DepartmentName != "RH" || current.EffectiveTo != nil {
+		t.Errorf("Unexpected current assignment: %+v", current)
+	}
+
+	if !first.ActiveAt(moved.Add(-time.Second)) || first.ActiveAt(moved) || !current.ActiveAt(moved) {
+		t.Errorf("Expected the transfer instant to belong to the new department only")
+	}
+}
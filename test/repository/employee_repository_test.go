@@ -2,6 +2,8 @@ package repository_test
 
 import (
 	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/repository"
+	"ManageEmployeesandDepartments/internal/utils"
 	"strings"
 	"testing"
 
@@ -500,6 +502,190 @@ func TestColaboradorRepository_List(t *testing.T) {
 	}
 }
 
+func TestEmployeeRepository_List_Ordenacao(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	db, cleanup := setupDepartamentoTestDB(t)
+	defer cleanup()
+
+	repo := repository.NewEmployeeRepository(db)
+
+	vendas := &models.Department{Name: "Vendas"}
+	compras := &models.Department{Name: "Compras"}
+	for _, depto := range []*models.Department{vendas, compras} {
+		if err := db.Create(depto).Error; err != nil {
+			t.Fatalf("Failed to create departamento: %v", err)
+		}
+	}
+
+	colaboradores := []*models.Employee{
+		{Name: "Carla", CPF: "11111111111", DepartmentID: vendas.ID},
+		{Name: "Ana", CPF: "22222222222", DepartmentID: compras.ID},
+		{Name: "Bruno", CPF: "33333333333", DepartmentID: vendas.ID},
+	}
+	for _, colab := range colaboradores {
+		if err := repo.Create(colab); err != nil {
+			t.Fatalf("Failed to create colaborador: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		nomeFilter    *string
+		sort          models.Sort
+		expectedNames []string
+	}{
+		{
+			name:          "padrão pela ordem de criação",
+			expectedNames: []string{"Carla", "Ana", "Bruno"},
+		},
+		{
+			name:          "nome descendente",
+			sort:          models.Sort{Field: "name", Desc: true},
+			expectedNames: []string{"Carla", "Bruno", "Ana"},
+		},
+		{
+			name:          "departamento com desempate pelo id",
+			sort:          models.Sort{Field: "department"},
+			expectedNames: []string{"Ana", "Carla", "Bruno"},
+		},
+		{
+			name:          "departamento combinado com filtro de nome",
+			nomeFilter:    stringPtr("R"),
+			sort:          models.Sort{Field: "department", Desc: true},
+			expectedNames: []string{"Bruno", "Carla"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, total, err := repo.List(models.EmployeeFilter{Name: tc.nomeFilter}, tc.sort, 1, 10)
+			if err != nil {
+				t.Fatalf("Failed to list colaboradores: %v", err)
+			}
+			if total != int64(len(tc.expectedNames)) || len(result) != len(tc.expectedNames) {
+				t.Fatalf("Expected %d colaboradores, got %d (total %d)", len(tc.expectedNames), len(result), total)
+			}
+			for i, colab := range result {
+				if colab.Name != tc.expectedNames[i] {
+					t.Errorf("Position %d: expected %s, got %s", i, tc.expectedNames[i], colab.Name)
+				}
+			}
+		})
+	}
+
+	if _, _, err := repo.List(models.EmployeeFilter{}, models.Sort{Field: "salary"}, 1, 10); err != utils.ErrInvalidSort {
+		t.Errorf("Expected %v, got %v", utils.ErrInvalidSort, err)
+	}
+}
+
+func TestEmployeeRepository_ListByCursor(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	db, cleanup := setupDepartamentoTestDB(t)
+	defer cleanup()
+
+	repo := repository.NewEmployeeRepository(db)
+
+	vendas := &models.Department{Name: "Vendas"}
+	compras := &models.Department{Name: "Compras"}
+	for _, depto := range []*models.Department{vendas, compras} {
+		if err := db.Create(depto).Error; err != nil {
+			t.Fatalf("Failed to create departamento: %v", err)
+		}
+	}
+
+	// "Bruno" repetido para exercitar o desempate pelo id
+	colaboradores := []*models.Employee{
+		{Name: "Carla", CPF: "11111111111", DepartmentID: vendas.ID},
+		{Name: "Bruno", CPF: "22222222222", DepartmentID: compras.ID},
+		{Name: "Ana", CPF: "33333333333", DepartmentID: vendas.ID},
+		{Name: "Bruno", CPF: "44444444444", DepartmentID: vendas.ID},
+		{Name: "Daniel", CPF: "55555555555", DepartmentID: compras.ID},
+	}
+	for _, colab := range colaboradores {
+		if err := repo.Create(colab); err != nil {
+			t.Fatalf("Failed to create colaborador: %v", err)
+		}
+	}
+
+	// walk percorre a listagem inteira de dois em dois seguindo os cursores
+	walk := func(t *testing.T, sort models.Sort, backward bool) []string {
+		var names []string
+		var cursor *models.Cursor
+		for i := 0; i < len(colaboradores); i++ {
+			page, cursors, err := repo.ListByCursor(models.EmployeeFilter{}, sort, cursor, backward, 2)
+			if err != nil {
+				t.Fatalf("Failed to list colaboradores: %v", err)
+			}
+			for _, colab := range page {
+				names = append(names, colab.Name+"/"+colab.CPF[:1])
+			}
+			if len(page) < 2 {
+				return names
+			}
+			cursor = &cursors[len(cursors)-1]
+		}
+		t.Fatalf("Walk did not end")
+		return nil
+	}
+
+	testCases := []struct {
+		name     string
+		sort     models.Sort
+		backward bool
+		expected []string
+	}{
+		{
+			name:     "ordem padrão",
+			expected: []string{"Carla/1", "Bruno/2", "Ana/3", "Bruno/4", "Daniel/5"},
+		},
+		{
+			name:     "nome ascendente",
+			sort:     models.Sort{Field: "name"},
+			expected: []string{"Ana/3", "Bruno/2", "Bruno/4", "Carla/1", "Daniel/5"},
+		},
+		{
+			name:     "nome ascendente de trás para frente",
+			sort:     models.Sort{Field: "name"},
+			backward: true,
+			expected: []string{"Daniel/5", "Carla/1", "Bruno/4", "Bruno/2", "Ana/3"},
+		},
+		{
+			name:     "nome descendente",
+			sort:     models.Sort{Field: "name", Desc: true},
+			expected: []string{"Daniel/5", "Carla/1", "Bruno/4", "Bruno/2", "Ana/3"},
+		},
+		{
+			name:     "data de criação",
+			sort:     models.Sort{Field: "created_at"},
+			expected: []string{"Carla/1", "Bruno/2", "Ana/3", "Bruno/4", "Daniel/5"},
+		},
+		{
+			name:     "departamento",
+			sort:     models.Sort{Field: "department"},
+			expected: []string{"Bruno/2", "Daniel/5", "Carla/1", "Ana/3", "Bruno/4"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := walk(t, tc.sort, tc.backward)
+			if strings.Join(got, ",") != strings.Join(tc.expected, ",") {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+
+	t.Run("cursor com data inválida", func(t *testing.T) {
+		cursor := &models.Cursor{Sort: "created_at", Key: "ontem", ID: colaboradores[0].ID}
+		_, _, err := repo.ListByCursor(models.EmployeeFilter{}, models.Sort{Field: "created_at"}, cursor, false, 2)
+		if err != utils.ErrInvalidCursor {
+			t.Errorf("Expected %v, got %v", utils.ErrInvalidCursor, err)
+		}
+	})
+}
+
 func TestColaboradorRepository_CountByDepartamentoID(t *testing.T) {
 	defer goleak.VerifyNone(t)
 
@@ -0,0 +1,147 @@
+package repository_test
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/repository"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/goleak"
+)
+
+func TestEmployeeRepository_Export(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	db, cleanup := setupDepartamentoTestDB(t)
+	defer cleanup()
+	deptRepo := repository.NewDepartmentRepository(db)
+	repo := repository.NewEmployeeRepository(db)
+
+	ti := &models.Department{Name: "TI"}
+	rh := &models.Department{Name: "RH"}
+	for _, d := range []*models.Department{ti, rh} {
+		if err := deptRepo.Create(d); err != nil {
+			t.Fatalf("Failed to create department: %v", err)
+		}
+	}
+	rg := "123456789"
+	maria := &models.Employee{Name: "Maria", CPF: "12345678909", RG: &rg, DepartmentID: ti.ID}
+	joao := &models.Employee{Name: "João", CPF: "98765432100", DepartmentID: ti.ID}
+	ana := &models.Employee{Name: "Ana", CPF: "52998224725", DepartmentID: rh.ID}
+	for _, e := range []*models.Employee{maria, joao, ana} {
+		if err := repo.Create(e); err != nil {
+			t.Fatalf("Failed to create employee: %v", err)
+		}
+	}
+	ti.ManagerID = &maria.ID
+	if err := deptRepo.Update(ti); err != nil {
+		t.Fatalf("Failed to set manager: %v", err)
+	}
+
+	var exported []models.EmployeeExportRow
+	collect := func(row *models.EmployeeExportRow) error {
+		exported = append(exported, *row)
+		return nil
+	}
+
+	if err := repo.Export(models.EmployeeFilter{}, models.NewSort("name", "asc"), collect); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if len(exported) != 3 || exported[0].Name != "Ana" || exported[1].Name != "João" || exported[2].Name != "Maria" {
+		t.Fatalf("Expected every employee by name, got %+v", exported)
+	}
+	// Nomes resolvidos; a linha reaproveitada não carrega o gerente da anterior
+	if exported[0].DepartmentName != "RH" || exported[0].ManagerName != nil {
+		t.Errorf("Expected Ana in RH without a manager, got %+v", exported[0])
+	}
+	if exported[1].DepartmentName != "TI" || exported[1].ManagerName == nil || *exported[1].ManagerName != "Maria" {
+		t.Errorf("Expected João in TI managed by Maria, got %+v", exported[1])
+	}
+	if exported[2].RG == nil || *exported[2].RG != rg || exported[2].CreatedAt.IsZero() {
+		t.Errorf("Expected Maria's RG and timestamps, got %+v", exported[2])
+	}
+
+	exported = nil
+	if err := repo.Export(models.EmployeeFilter{DepartmentID: &ti.ID}, models.NewSort("department", "desc"), collect); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if len(exported) != 2 {
+		t.Errorf("Expected only TI's employees, got %+v", exported)
+	}
+
+	if err := repo.Export(models.EmployeeFilter{}, models.NewSort("salary", ""), collect); err == nil {
+		t.Error("Expected an error for an unknown sort field")
+	}
+
+	// Um erro do consumidor interrompe a exportação
+	stop := errors.New("stop")
+	calls := 0
+	err := repo.Export(models.EmployeeFilter{}, models.Sort{}, func(*models.EmployeeExportRow) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("Expected the export to stop after the first row, got %v after %d calls", err, calls)
+	}
+}
+
+func TestDepartmentRepository_Export(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	db, cleanup := setupDepartamentoTestDB(t)
+	defer cleanup()
+	deptRepo := repository.NewDepartmentRepository(db)
+	employeeRepo := repository.NewEmployeeRepository(db)
+
+	empresa := &models.Department{Name: "Empresa"}
+	if err := deptRepo.Create(empresa); err != nil {
+		t.Fatalf("Failed to create department: %v", err)
+	}
+	ti := &models.Department{Name: "TI", ParentDepartmentID: &empresa.ID}
+	if err := deptRepo.Create(ti); err != nil {
+		t.Fatalf("Failed to create department: %v", err)
+	}
+	maria := &models.Employee{Name: "Maria", CPF: "12345678909", DepartmentID: ti.ID}
+	if err := employeeRepo.Create(maria); err != nil {
+		t.Fatalf("Failed to create employee: %v", err)
+	}
+	ti.ManagerID = &maria.ID
+	if err := deptRepo.Update(ti); err != nil {
+		t.Fatalf("Failed to set manager: %v", err)
+	}
+
+	var exported []models.DepartmentExportRow
+	collect := func(row *models.DepartmentExportRow) error {
+		exported = append(exported, *row)
+		return nil
+	}
+
+	if err := deptRepo.Export(models.DepartmentFilter{}, models.NewSort("name", "asc"), collect); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if len(exported) != 2 || exported[0].Name != "Empresa" || exported[0].ParentDepartmentName != nil || exported[0].ManagerName != nil {
+		t.Fatalf("Expected Empresa first, without parent or manager, got %+v", exported)
+	}
+	if exported[1].ParentDepartmentName == nil || *exported[1].ParentDepartmentName != "Empresa" ||
+		exported[1].ManagerName == nil || *exported[1].ManagerName != "Maria" || *exported[1].ManagerID != maria.ID {
+		t.Errorf("Expected TI under Empresa, managed by Maria, got %+v", exported[1])
+	}
+
+	exported = nil
+	managerName := "mar"
+	if err := deptRepo.Export(models.DepartmentFilter{ManagerName: &managerName, ParentDepartmentID: &empresa.ID}, models.NewSort("manager", "desc"), collect); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if len(exported) != 1 || exported[0].ID != ti.ID {
+		t.Errorf("Expected only TI, got %+v", exported)
+	}
+
+	exported = nil
+	if err := deptRepo.Export(models.DepartmentFilter{DepartmentIDs: []uuid.UUID{ti.ID}}, models.Sort{}, collect); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if len(exported) != 1 || exported[0].ID != ti.ID {
+		t.Errorf("Expected the export to be limited to TI, got %+v", exported)
+	}
+}
@@ -0,0 +1,209 @@
+package repository_test
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/repository"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/goleak"
+)
+
+func TestEmployeeRepository_Restore(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	db, cleanup := setupDepartamentoTestDB(t)
+	defer cleanup()
+	deptRepo := repository.NewDepartmentRepository(db)
+	repo := repository.NewEmployeeRepository(db)
+
+	ti := &models.Department{Name: "TI"}
+	if err := deptRepo.Create(ti); err != nil {
+		t.Fatalf("Failed to create department: %v", err)
+	}
+	rg := "123456789"
+	maria := &models.Employee{Name: "Maria", CPF: "12345678909", RG: &rg, DepartmentID: ti.ID}
+	joao := &models.Employee{Name: "João", CPF: "98765432100", DepartmentID: ti.ID}
+	for _, e := range []*models.Employee{maria, joao} {
+		if err := repo.Create(e); err != nil {
+			t.Fatalf("Failed to create employee: %v", err)
+		}
+	}
+	if err := repo.Delete(maria.ID); err != nil {
+		t.Fatalf("Failed to delete employee: %v", err)
+	}
+
+	// Documentos de colaboradores removidos não contam como em uso
+	if inUse, err := repo.IsCPFInUse(maria.CPF); err != nil || inUse {
+		t.Errorf("Expected CPF of a removed employee to be free, got %v (%v)", inUse, err)
+	}
+	if inUse, err := repo.IsRGInUse(rg); err != nil || inUse {
+		t.Errorf("Expected RG of a removed employee to be free, got %v (%v)", inUse, err)
+	}
+	if inUse, err := repo.IsCPFInUse(joao.CPF); err != nil || !inUse {
+		t.Errorf("Expected CPF of an active employee to be in use, got %v (%v)", inUse, err)
+	}
+
+	deleted, total, err := repo.ListDeleted(1, 10)
+	if err != nil {
+		t.Fatalf("ListDeleted failed: %v", err)
+	}
+	if total != 1 || len(deleted) != 1 || deleted[0].ID != maria.ID || !deleted[0].DeletedAt.Valid {
+		t.Fatalf("Expected only Maria among the removed, got %d: %+v", total, deleted)
+	}
+
+	found, err := repo.FindByIDWithDeletedForUpdate(maria.ID)
+	if err != nil || !found.DeletedAt.Valid {
+		t.Fatalf("Expected to find the removed employee, got %+v (%v)", found, err)
+	}
+	if _, err := repo.FindByIDWithDeletedForUpdate(uuid.New()); err == nil {
+		t.Error("Expected an error for an unknown employee")
+	}
+
+	if err := repo.Restore(maria.ID); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if _, err := repo.FindByID(maria.ID); err != nil {
+		t.Errorf("Expected the restored employee to be found: %v", err)
+	}
+	if inUse, err := repo.IsCPFInUse(maria.CPF); err != nil || !inUse {
+		t.Errorf("Expected CPF of the restored employee to be in use, got %v (%v)", inUse, err)
+	}
+	if _, total, _ := repo.ListDeleted(1, 10); total != 0 {
+		t.Errorf("Expected no removed employees, got %d", total)
+	}
+}
+
+func TestDepartmentRepository_Restore(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	db, cleanup := setupDepartamentoTestDB(t)
+	defer cleanup()
+	deptRepo := repository.NewDepartmentRepository(db)
+	employeeRepo := repository.NewEmployeeRepository(db)
+
+	ti := &models.Department{Name: "TI"}
+	if err := deptRepo.Create(ti); err != nil {
+		t.Fatalf("Failed to create department: %v", err)
+	}
+	manager := &models.Employee{Name: "Maria", CPF: "12345678909", DepartmentID: ti.ID}
+	if err := employeeRepo.Create(manager); err != nil {
+		t.Fatalf("Failed to create employee: %v", err)
+	}
+	ti.ManagerID = &manager.ID
+	if err := deptRepo.Update(ti); err != nil {
+		t.Fatalf("Failed to set manager: %v", err)
+	}
+	if err := deptRepo.Delete(ti.ID); err != nil {
+		t.Fatalf("Failed to delete department: %v", err)
+	}
+
+	deleted, total, err := deptRepo.ListDeleted(1, 10)
+	if err != nil || total != 1 || deleted[0].ID != ti.ID {
+		t.Fatalf("Expected TI among the removed, got %d: %+v (%v)", total, deleted, err)
+	}
+
+	if err := deptRepo.Restore(ti.ID); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	restored, err := deptRepo.FindByID(ti.ID)
+	if err != nil {
+		t.Fatalf("Expected the restored department to be found: %v", err)
+	}
+	if restored.ManagerID != nil {
+		t.Errorf("Expected the department to come back without a manager, got %v", restored.ManagerID)
+	}
+}
+
+func TestEmployeeRepository_DocumentosUnicosEntreAtivos(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	db, cleanup := setupDepartamentoTestDB(t)
+	defer cleanup()
+	deptRepo := repository.NewDepartmentRepository(db)
+	repo := repository.NewEmployeeRepository(db)
+
+	ti := &models.Department{Name: "TI"}
+	if err := deptRepo.Create(ti); err != nil {
+		t.Fatalf("Failed to create department: %v", err)
+	}
+	rg := "123456789"
+	first := &models.Employee{Name: "Maria", CPF: "12345678909", RG: &rg, DepartmentID: ti.ID}
+	if err := repo.Create(first); err != nil {
+		t.Fatalf("Failed to create employee: %v", err)
+	}
+
+	if _, err := repo.FindDeletedByCPFForUpdate(first.CPF); err == nil {
+		t.Error("Expected no removed employee with the CPF")
+	}
+	if err := repo.Create(&models.Employee{Name: "Outra", CPF: first.CPF, DepartmentID: ti.ID}); err == nil {
+		t.Error("Expected the CPF of an active employee to be rejected")
+	}
+
+	if err := repo.Delete(first.ID); err != nil {
+		t.Fatalf("Failed to delete employee: %v", err)
+	}
+	found, err := repo.FindDeletedByCPFForUpdate(first.CPF)
+	if err != nil || found.ID != first.ID {
+		t.Fatalf("Expected to find the removed employee, got %+v (%v)", found, err)
+	}
+
+	// Documentos de removidos não bloqueiam novos registros
+	second := &models.Employee{Name: "Maria", CPF: first.CPF, RG: &rg, DepartmentID: ti.ID}
+	if err := repo.Create(second); err != nil {
+		t.Fatalf("Expected the documents of a removed employee to be reusable: %v", err)
+	}
+	if err := repo.Restore(first.ID); err == nil {
+		t.Error("Expected restoring a second active holder of the CPF to be rejected")
+	}
+}
+
+func TestEmployeeRepository_Rehire(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	db, cleanup := setupDepartamentoTestDB(t)
+	defer cleanup()
+	deptRepo := repository.NewDepartmentRepository(db)
+	repo := repository.NewEmployeeRepository(db)
+
+	ti, rh := &models.Department{Name: "TI"}, &models.Department{Name: "RH"}
+	for _, d := range []*models.Department{ti, rh} {
+		if err := deptRepo.Create(d); err != nil {
+			t.Fatalf("Failed to create department: %v", err)
+		}
+	}
+	antigo, novo := "123456789", "987654321"
+	maria := &models.Employee{Name: "Maria", CPF: "12345678909", RG: &antigo, DepartmentID: ti.ID}
+	if err := repo.Create(maria); err != nil {
+		t.Fatalf("Failed to create employee: %v", err)
+	}
+	if err := repo.Delete(maria.ID); err != nil {
+		t.Fatalf("Failed to delete employee: %v", err)
+	}
+
+	// O RG antigo de Maria passou a outro colaborador ativo
+	joao := &models.Employee{Name: "João", CPF: "98765432100", RG: &antigo, DepartmentID: ti.ID}
+	if err := repo.Create(joao); err != nil {
+		t.Fatalf("Failed to create employee: %v", err)
+	}
+
+	maria.RG = &antigo
+	if err := repo.Rehire(maria); err == nil {
+		t.Error("Expected the RG of an active employee to be rejected")
+	}
+	if _, err := repo.FindByID(maria.ID); err == nil {
+		t.Error("Expected the rejected rehire to leave the employee removed")
+	}
+
+	maria.Name, maria.RG, maria.DepartmentID = "Maria Sazonal", &novo, rh.ID
+	if err := repo.Rehire(maria); err != nil {
+		t.Fatalf("Expected the rehire with a new RG to succeed: %v", err)
+	}
+	found, err := repo.FindByID(maria.ID)
+	if err != nil {
+		t.Fatalf("Expected the rehired employee to be found: %v", err)
+	}
+	if found.Name != "Maria Sazonal" || found.RG == nil || *found.RG != novo || found.DepartmentID != rh.ID {
+		t.Errorf("Expected the new data to be saved, got %+v", found)
+	}
+}
@@ -0,0 +1,131 @@
+package services_test
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/services"
+	"ManageEmployeesandDepartments/internal/utils"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestEmployeeService_AnonymizeEmployee(t *testing.T) {
+	employeeID := uuid.New()
+	removed := func() *models.Employee {
+		return &models.Employee{
+			ID:           employeeID,
+			Name:         "Maria Silva",
+			CPF:          "12345678909",
+			RG:           stringPtr("123456789"),
+			DepartmentID: uuid.New(),
+			DeletedAt:    gorm.DeletedAt{Time: time.Now(), Valid: true},
+		}
+	}
+	history := func() []*models.AuditEntry {
+		return []*models.AuditEntry{
+			{ID: uuid.New(), EntityID: employeeID, Action: models.AuditActionCreate, Changes: models.AuditChanges{
+				"name": {After: "Maria Silva"},
+				"cpf":  {After: "12345678909"},
+				"rg":   {After: "123456789"},
+			}},
+			{ID: uuid.New(), EntityID: employeeID, Action: models.AuditActionUpdate, Changes: models.AuditChanges{
+				"department_id": {Before: "a", After: "b"},
+			}},
+			{ID: uuid.New(), EntityID: uuid.New(), Action: models.AuditActionCreate, Changes: models.AuditChanges{
+				"name": {After: "Outra Pessoa"},
+			}},
+		}
+	}
+
+	t.Run("simulação não altera nada", func(t *testing.T) {
+		employeeRepo := &MockEmployeeRepository{withDeletedResult: removed()}
+		auditRepo := &MockAuditRepository{entries: history()}
+
+		service := services.NewEmployeeService(&MockDepartmentRepository{}, employeeRepo, auditRepo, &MockHistoryRepository{})
+		report, err := service.AnonymizeEmployee(employeeID, true)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if !report.DryRun || report.AnonymizedAt != nil || report.AuditEntriesRedacted != 1 {
+			t.Errorf("Unexpected report: %+v", report)
+		}
+		if strings.Join(report.Fields, ",") != "name,cpf,rg" {
+			t.Errorf("Expected name, cpf and rg to be reported, got %v", report.Fields)
+		}
+		if employeeRepo.anonymized != nil || len(auditRepo.updated) != 0 || len(auditRepo.entries) != 3 {
+			t.Error("Expected a dry run to write nothing")
+		}
+	})
+
+	t.Run("anonimiza colaborador removido", func(t *testing.T) {
+		employeeRepo := &MockEmployeeRepository{withDeletedResult: removed()}
+		auditRepo := &MockAuditRepository{entries: history()}
+
+		service := services.NewEmployeeService(&MockDepartmentRepository{}, employeeRepo, auditRepo, &MockHistoryRepository{})
+		report, err := service.WithActor("dpo@empresa.com").AnonymizeEmployee(employeeID, false)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		scrubbed := employeeRepo.anonymized
+		if scrubbed == nil || scrubbed.Name != models.AnonymizedName || scrubbed.RG != nil || scrubbed.AnonymizedAt == nil {
+			t.Fatalf("Expected the personal data to be scrubbed, got %+v", scrubbed)
+		}
+		if scrubbed.CPF != report.CPFTombstone || len(scrubbed.CPF) != 11 || utils.IsCPFValido(scrubbed.CPF) {
+			t.Errorf("Expected an 11-char tombstone that is not a CPF, got %q", scrubbed.CPF)
+		}
+		if report.DryRun || report.AnonymizedAt == nil || report.AuditEntriesRedacted != 1 {
+			t.Errorf("Unexpected report: %+v", report)
+		}
+
+		if len(auditRepo.updated) != 1 || auditRepo.updated[0].ID != auditRepo.entries[0].ID {
+			t.Fatalf("Expected only the entry with personal data to be rewritten, got %+v", auditRepo.updated)
+		}
+		if auditRepo.entries[2].Changes["name"].After != "Outra Pessoa" {
+			t.Error("Expected entries of other employees to be left alone")
+		}
+
+		erasure := auditRepo.entries[len(auditRepo.entries)-1]
+		if erasure.Action != models.AuditActionAnonymize || erasure.Actor != "dpo@empresa.com" || erasure.EntityID != employeeID {
+			t.Fatalf("Expected the erasure to be audited, got %+v", erasure)
+		}
+		for _, entry := range auditRepo.entries {
+			raw, _ := json.Marshal(entry.Changes)
+			for _, pii := range []string{"Maria Silva", "12345678909", "123456789"} {
+				if strings.Contains(string(raw), pii) {
+					t.Errorf("Expected %q to be erased from the audit log, found in %s", pii, raw)
+				}
+			}
+		}
+	})
+
+	t.Run("colaborador ativo", func(t *testing.T) {
+		employeeRepo := &MockEmployeeRepository{withDeletedResult: &models.Employee{ID: employeeID, Name: "Maria"}}
+
+		service := services.NewEmployeeService(&MockDepartmentRepository{}, employeeRepo, &MockAuditRepository{}, &MockHistoryRepository{})
+		if _, err := service.AnonymizeEmployee(employeeID, false); !errors.Is(err, utils.ErrNotDeleted) {
+			t.Errorf("Expected ErrNotDeleted, got %v", err)
+		}
+	})
+
+	t.Run("colaborador já anonimizado não volta nem é anonimizado de novo", func(t *testing.T) {
+		employee := removed()
+		at := time.Now()
+		employee.AnonymizedAt = &at
+		employeeRepo := &MockEmployeeRepository{withDeletedResult: employee}
+
+		service := services.NewEmployeeService(&MockDepartmentRepository{}, employeeRepo, &MockAuditRepository{}, &MockHistoryRepository{})
+		if _, err := service.AnonymizeEmployee(employeeID, false); !errors.Is(err, utils.ErrEmployeeAnonymized) {
+			t.Errorf("Expected ErrEmployeeAnonymized, got %v", err)
+		}
+		if _, err := service.RestoreEmployee(employeeID); !errors.Is(err, utils.ErrEmployeeAnonymized) {
+			t.Errorf("Expected restore to fail with ErrEmployeeAnonymized, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,190 @@
+package services_test
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/services"
+	"ManageEmployeesandDepartments/internal/utils"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MockAPIKeyRepository simulates the API key repository
+type MockAPIKeyRepository struct {
+	created        *models.APIKey
+	createError    error
+	findByIDError  error
+	findByHash     map[string]*models.APIKey
+	listResult     []*models.APIKey
+	revokedID      uuid.UUID
+	touchedID      uuid.UUID
+	touchLastError error
+}
+
+func (m *MockAPIKeyRepository) Create(key *models.APIKey) error {
+	m.created = key
+	return m.createError
+}
+
+func (m *MockAPIKeyRepository) FindByID(id uuid.UUID) (*models.APIKey, error) {
+	if m.findByIDError != nil {
+		return nil, m.findByIDError
+	}
+	return &models.APIKey{ID: id}, nil
+}
+
+func (m *MockAPIKeyRepository) FindByHash(hash string) (*models.APIKey, error) {
+	if key, ok := m.findByHash[hash]; ok {
+		return key, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MockAPIKeyRepository) List() ([]*models.APIKey, error) {
+	return m.listResult, nil
+}
+
+func (m *MockAPIKeyRepository) Revoke(id uuid.UUID, at time.Time) error {
+	m.revokedID = id
+	return nil
+}
+
+func (m *MockAPIKeyRepository) TouchLastUsed(id uuid.UUID, at time.Time) error {
+	m.touchedID = id
+	return m.touchLastError
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestAPIKeyService_CreateAPIKey(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	testCases := []struct {
+		name          string
+		scopes        []string
+		expiresAt     *time.Time
+		expectedError error
+	}{
+		{name: "sucesso com escopos repetidos", scopes: []string{models.ScopeEmployeesWrite, models.ScopeEmployeesRead, models.ScopeEmployeesWrite}, expiresAt: &future},
+		{name: "sem escopos", scopes: nil, expectedError: utils.ErrInvalidScope},
+		{name: "escopo desconhecido", scopes: []string{models.ScopeEmployeesRead, "employees:delete"}, expectedError: utils.ErrInvalidScope},
+		{name: "expiração no passado", scopes: []string{models.ScopeEmployeesRead}, expiresAt: &past, expectedError: utils.ErrInvalid},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &MockAPIKeyRepository{}
+			service := services.NewAPIKeyService(repo)
+
+			key, plaintext, err := service.CreateAPIKey("integração", tc.scopes, tc.expiresAt)
+			if tc.expectedError != nil {
+				if !errors.Is(err, tc.expectedError) {
+					t.Fatalf("Expected error %v, got %v", tc.expectedError, err)
+				}
+				if repo.created != nil {
+					t.Errorf("Expected no key to be stored")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !strings.HasPrefix(plaintext, "emk_") || !strings.HasPrefix(plaintext, key.Prefix) {
+				t.Errorf("Unexpected key %q with prefix %q", plaintext, key.Prefix)
+			}
+			if key.KeyHash != sha256Hex(plaintext) || strings.Contains(key.KeyHash, plaintext) {
+				t.Errorf("Expected only the SHA-256 of the key to be stored")
+			}
+			if strings.Join(key.Scopes, " ") != "employees:read employees:write" {
+				t.Errorf("Expected sorted, deduplicated scopes, got %v", key.Scopes)
+			}
+		})
+	}
+}
+
+func TestAPIKeyService_AuthenticateAPIKey(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+	recent := now.Add(-time.Second)
+
+	keys := map[string]*models.APIKey{
+		"emk_ativa":     {ID: uuid.New()},
+		"emk_recente":   {ID: uuid.New(), LastUsedAt: &recent},
+		"emk_futura":    {ID: uuid.New(), ExpiresAt: &future, LastUsedAt: &past},
+		"emk_expirada":  {ID: uuid.New(), ExpiresAt: &past},
+		"emk_revogada":  {ID: uuid.New(), RevokedAt: &past},
+		"emk_falha_uso": {ID: uuid.New()},
+	}
+
+	testCases := []struct {
+		name          string
+		key           string
+		touchError    error
+		expectTouch   bool
+		expectedError error
+	}{
+		{name: "chave ativa registra uso", key: "emk_ativa", expectTouch: true},
+		{name: "uso recente não gera nova escrita", key: "emk_recente"},
+		{name: "chave com expiração futura", key: "emk_futura", expectTouch: true},
+		{name: "falha ao registrar uso não rejeita", key: "emk_falha_uso", touchError: gorm.ErrInvalidDB, expectTouch: true},
+		{name: "chave expirada", key: "emk_expirada", expectedError: utils.ErrUnauthorized},
+		{name: "chave revogada", key: "emk_revogada", expectedError: utils.ErrUnauthorized},
+		{name: "chave desconhecida", key: "emk_desconhecida", expectedError: utils.ErrUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &MockAPIKeyRepository{findByHash: map[string]*models.APIKey{}, touchLastError: tc.touchError}
+			for plaintext, key := range keys {
+				repo.findByHash[sha256Hex(plaintext)] = key
+			}
+			service := services.NewAPIKeyService(repo)
+
+			key, err := service.AuthenticateAPIKey(tc.key)
+			if !errors.Is(err, tc.expectedError) {
+				t.Fatalf("Expected error %v, got %v", tc.expectedError, err)
+			}
+			if err != nil {
+				return
+			}
+			if key != keys[tc.key] {
+				t.Errorf("Expected the stored key to be returned")
+			}
+			if touched := repo.touchedID == key.ID; touched != tc.expectTouch {
+				t.Errorf("Expected last use recorded = %v, got %v", tc.expectTouch, touched)
+			}
+		})
+	}
+}
+
+func TestAPIKeyService_RevokeAPIKey(t *testing.T) {
+	id := uuid.New()
+
+	repo := &MockAPIKeyRepository{}
+	if err := services.NewAPIKeyService(repo).RevokeAPIKey(id); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if repo.revokedID != id {
+		t.Errorf("Expected key %s to be revoked", id)
+	}
+
+	repo = &MockAPIKeyRepository{findByIDError: gorm.ErrRecordNotFound}
+	if err := services.NewAPIKeyService(repo).RevokeAPIKey(id); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound, got %v", err)
+	}
+	if repo.revokedID != uuid.Nil {
+		t.Errorf("Expected no revocation for a missing key")
+	}
+}
@@ -0,0 +1,135 @@
+package services_test
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/services"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestEmployeeService_Auditoria(t *testing.T) {
+	employeeID := uuid.New()
+	currentDeptID := uuid.New()
+	newDeptID := uuid.New()
+
+	t.Run("atualização registra apenas os campos alterados", func(t *testing.T) {
+		employeeRepo := &MockEmployeeRepository{findByIDResult: &models.Employee{ID: employeeID, Name: "João Silva", CPF: "12345678909", DepartmentID: currentDeptID}}
+		deptRepo := &MockDepartmentRepository{findByIDResult: &models.Department{ID: newDeptID}}
+		auditRepo := &MockAuditRepository{}
+
+		service := services.NewEmployeeService(deptRepo, employeeRepo, auditRepo, &MockHistoryRepository{}).WithActor("rh-admin-1")
+		if _, err := service.UpdateEmployee(employeeID, stringPtr("João Souza"), nil, newDeptID); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(auditRepo.entries) != 1 {
+			t.Fatalf("Expected 1 audit entry, got %d", len(auditRepo.entries))
+		}
+		entry := auditRepo.entries[0]
+		if entry.Actor != "rh-admin-1" || entry.Entity != models.AuditEntityEmployee || entry.EntityID != employeeID || entry.Action != models.AuditActionUpdate {
+			t.Errorf("Unexpected entry: %+v", entry)
+		}
+		if len(entry.Changes) != 2 {
+			t.Fatalf("Expected changes on name and department_id only, got %v", entry.Changes)
+		}
+		if c := entry.Changes["department_id"]; c.Before != currentDeptID.String() || c.After != newDeptID.String() {
+			t.Errorf("Unexpected department_id change: %+v", c)
+		}
+		if c := entry.Changes["name"]; c.Before != "João Silva" || c.After != "João Souza" {
+			t.Errorf("Unexpected name change: %+v", c)
+		}
+	})
+
+	t.Run("criação sem ator registra system", func(t *testing.T) {
+		deptRepo := &MockDepartmentRepository{findByIDResult: &models.Department{ID: currentDeptID}}
+		auditRepo := &MockAuditRepository{}
+
+		service := services.NewEmployeeService(deptRepo, &MockEmployeeRepository{}, auditRepo, &MockHistoryRepository{})
+		if _, err := service.CreateEmployee("Maria", "123.456.789-09", nil, currentDeptID); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(auditRepo.entries) != 1 {
+			t.Fatalf("Expected 1 audit entry, got %d", len(auditRepo.entries))
+		}
+		entry := auditRepo.entries[0]
+		if entry.Actor != services.SystemActor || entry.Action != models.AuditActionCreate {
+			t.Errorf("Unexpected entry: %+v", entry)
+		}
+		if c := entry.Changes["cpf"]; c.Before != nil || c.After != "12345678909" {
+			t.Errorf("Expected the created value with no previous one, got %+v", c)
+		}
+	})
+
+	t.Run("remoção registra o estado anterior", func(t *testing.T) {
+		employeeRepo := &MockEmployeeRepository{findByIDResult: &models.Employee{ID: employeeID, Name: "João Silva", DepartmentID: currentDeptID}}
+		auditRepo := &MockAuditRepository{}
+
+		service := services.NewEmployeeService(&MockDepartmentRepository{}, employeeRepo, auditRepo, &MockHistoryRepository{}).WithActor("rh-admin-1")
+		if err := service.DeleteEmployee(employeeID); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(auditRepo.entries) != 1 || auditRepo.entries[0].Action != models.AuditActionDelete {
+			t.Fatalf("Expected 1 delete entry, got %+v", auditRepo.entries)
+		}
+		if c := auditRepo.entries[0].Changes["name"]; c.Before != "João Silva" || c.After != nil {
+			t.Errorf("Expected the removed value with no new one, got %+v", c)
+		}
+	})
+
+	t.Run("falha na auditoria desfaz a operação", func(t *testing.T) {
+		employeeRepo := &MockEmployeeRepository{findByIDResult: &models.Employee{ID: employeeID, DepartmentID: currentDeptID}}
+		deptRepo := &MockDepartmentRepository{findByIDResult: &models.Department{ID: currentDeptID}}
+		auditRepo := &MockAuditRepository{createError: gorm.ErrInvalidDB}
+
+		service := services.NewEmployeeService(deptRepo, employeeRepo, auditRepo, &MockHistoryRepository{})
+		if _, err := service.UpdateEmployee(employeeID, nil, nil, currentDeptID); !errors.Is(err, gorm.ErrInvalidDB) {
+			t.Errorf("Expected the audit error to fail the transaction, got %v", err)
+		}
+	})
+}
+
+func TestDepartmentService_Auditoria(t *testing.T) {
+	managerID := uuid.New()
+	oldDeptID := uuid.New()
+
+	employeeRepo := &MockEmployeeRepository{findByIDResult: &models.Employee{ID: managerID, Name: "Ana", DepartmentID: oldDeptID}}
+	auditRepo := &MockAuditRepository{}
+
+	service := services.NewDepartmentService(&MockDepartmentRepository{}, employeeRepo, auditRepo, &MockHistoryRepository{}, &MockVersionRepository{}, services.MoveConfirmation{}).WithActor("rh-admin-1")
+	dept, err := service.CreateDepartment("Financeiro", managerID, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// O gerente é transferido para o novo departamento: "quem moveu esta pessoa"
+	if len(auditRepo.entries) != 2 {
+		t.Fatalf("Expected department create and manager move entries, got %d", len(auditRepo.entries))
+	}
+	created, moved := auditRepo.entries[0], auditRepo.entries[1]
+	if created.Entity != models.AuditEntityDepartment || created.Action != models.AuditActionCreate || created.EntityID != dept.ID {
+		t.Errorf("Unexpected department entry: %+v", created)
+	}
+	if moved.Entity != models.AuditEntityEmployee || moved.EntityID != managerID || moved.Actor != "rh-admin-1" {
+		t.Errorf("Unexpected manager entry: %+v", moved)
+	}
+	if c := moved.Changes["department_id"]; c.Before != oldDeptID.String() || c.After != dept.ID.String() {
+		t.Errorf("Unexpected department_id change: %+v", c)
+	}
+}
+
+func TestAuditService_ListAuditEntries(t *testing.T) {
+	auditRepo := &MockAuditRepository{entries: []*models.AuditEntry{{ID: uuid.New()}, {ID: uuid.New()}}}
+
+	page, err := services.NewAuditService(auditRepo).ListAuditEntries(models.AuditFilter{}, 1, 10)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if page.Total != 2 || len(page.Items) != 2 || page.TotalPages != 1 {
+		t.Errorf("Unexpected page: %+v", page)
+	}
+}
@@ -0,0 +1,90 @@
+package services_test
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/services"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestDepartmentService_VersoesDoDepartamento(t *testing.T) {
+	deptID := uuid.New()
+
+	t.Run("criação abre a primeira versão", func(t *testing.T) {
+		versionRepo := &MockVersionRepository{}
+		service := services.NewDepartmentService(&MockDepartmentRepository{}, &MockEmployeeRepository{}, &MockAuditRepository{}, &MockHistoryRepository{}, versionRepo, services.MoveConfirmation{})
+
+		if _, err := service.CreateDepartment("Financeiro", uuid.Nil, nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(versionRepo.created) != 1 || versionRepo.created[0].Name != "Financeiro" {
+			t.Errorf("Expected the first version, got %+v", versionRepo.created)
+		}
+	})
+
+	t.Run("renomear encerra a versão atual e abre outra", func(t *testing.T) {
+		deptRepo := &MockDepartmentRepository{findByIDResult: &models.Department{ID: deptID, Name: "TI"}}
+		versionRepo := &MockVersionRepository{}
+		service := services.NewDepartmentService(deptRepo, &MockEmployeeRepository{}, &MockAuditRepository{}, &MockHistoryRepository{}, versionRepo, services.MoveConfirmation{})
+
+		if _, err := service.UpdateDepartment(deptID, stringPtr("Tecnologia"), nil, nil, ""); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(versionRepo.closed) != 1 || len(versionRepo.created) != 1 || versionRepo.created[0].Name != "Tecnologia" {
+			t.Errorf("Expected a new version named Tecnologia, got %v closed and %+v created", versionRepo.closed, versionRepo.created)
+		}
+	})
+
+	t.Run("atualização sem mudança não gera versão", func(t *testing.T) {
+		deptRepo := &MockDepartmentRepository{findByIDResult: &models.Department{ID: deptID, Name: "TI"}}
+		versionRepo := &MockVersionRepository{}
+		service := services.NewDepartmentService(deptRepo, &MockEmployeeRepository{}, &MockAuditRepository{}, &MockHistoryRepository{}, versionRepo, services.MoveConfirmation{})
+
+		if _, err := service.UpdateDepartment(deptID, stringPtr("TI"), nil, nil, ""); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(versionRepo.closed)+len(versionRepo.created) != 0 {
+			t.Errorf("Expected no new version, got %v closed and %+v created", versionRepo.closed, versionRepo.created)
+		}
+	})
+
+	t.Run("remoção encerra a versão atual", func(t *testing.T) {
+		deptRepo := &MockDepartmentRepository{findByIDResult: &models.Department{ID: deptID, Name: "TI"}}
+		versionRepo := &MockVersionRepository{}
+		service := services.NewDepartmentService(deptRepo, &MockEmployeeRepository{}, &MockAuditRepository{}, &MockHistoryRepository{}, versionRepo, services.MoveConfirmation{})
+
+		if err := service.DeleteDepartment(deptID); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(versionRepo.closed) != 1 || versionRepo.closed[0] != deptID || len(versionRepo.created) != 0 {
+			t.Errorf("Expected only the current version to be closed, got %v closed and %+v created", versionRepo.closed, versionRepo.created)
+		}
+	})
+}
+
+func TestDepartmentService_GetDepartmentWithTree_NaData(t *testing.T) {
+	raiz, filho := uuid.New(), uuid.New()
+	asOf := time.Date(2025, 1, 1, 23, 59, 59, 0, time.UTC)
+
+	versionRepo := &MockVersionRepository{
+		findAtResult:            &models.Department{ID: raiz, Name: "Empresa"},
+		findDescendantsAtResult: []*models.Department{{ID: filho, Name: "TI (antigo)", ParentDepartmentID: &raiz}},
+	}
+	// O estado atual não deve ser consultado
+	deptRepo := &MockDepartmentRepository{findByIDWithManagerError: gorm.ErrInvalidDB}
+	service := services.NewDepartmentService(deptRepo, &MockEmployeeRepository{}, &MockAuditRepository{}, &MockHistoryRepository{}, versionRepo, services.MoveConfirmation{})
+
+	result, err := service.GetDepartmentWithTree(raiz, 0, &asOf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !versionRepo.at.Equal(asOf) {
+		t.Errorf("Expected the tree at %v, got %v", asOf, versionRepo.at)
+	}
+	if len(result.SubDepartments) != 1 || result.SubDepartments[0].Name != "TI (antigo)" {
+		t.Errorf("Expected the historical subtree, got %+v", result.SubDepartments)
+	}
+}
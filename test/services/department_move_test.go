@@ -0,0 +1,251 @@
+package services_test
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/services"
+	"ManageEmployeesandDepartments/internal/utils"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestDepartmentService_PreviewMove(t *testing.T) {
+	empresaID, tiID, suporteID, rhID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	diretorID, mariaID, biaID, anaID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	// Empresa (diretor) > TI (Maria) > Suporte (Bia); Empresa > RH (Ana)
+	deptRepo := &MockDepartmentRepository{
+		findAllResult: []*models.Department{
+			{ID: empresaID, Name: "Empresa", ManagerID: &diretorID},
+			{ID: tiID, Name: "TI", ParentDepartmentID: &empresaID, ManagerID: &mariaID},
+			{ID: suporteID, Name: "Suporte", ParentDepartmentID: &tiID, ManagerID: &biaID},
+			{ID: rhID, Name: "RH", ParentDepartmentID: &empresaID, ManagerID: &anaID},
+		},
+		findAllSubordinateIDsResult: []uuid.UUID{tiID, suporteID},
+	}
+	employeeRepo := &MockEmployeeRepository{countByDepartmentIDs: map[uuid.UUID]int64{tiID: 2, suporteID: 3, rhID: 7}}
+	service := services.NewDepartmentService(deptRepo, employeeRepo, &MockAuditRepository{}, &MockHistoryRepository{}, &MockVersionRepository{},
+		services.MoveConfirmation{Threshold: 4, TTL: time.Minute, Secret: []byte("segredo")})
+
+	t.Run("subárvore levada para baixo de RH", func(t *testing.T) {
+		preview, err := service.PreviewMove(tiID, rhID)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if preview.Depth != 1 || preview.NewDepth != 2 {
+			t.Errorf("Expected depth 1 → 2, got %d → %d", preview.Depth, preview.NewDepth)
+		}
+		if preview.Headcount != 5 || len(preview.Departments) != 2 {
+			t.Fatalf("Expected 5 employees in 2 departments, got %d in %+v", preview.Headcount, preview.Departments)
+		}
+		if d := preview.Departments[1]; d.ID != suporteID || d.NewDepth != 3 || d.Headcount != 3 {
+			t.Errorf("Unexpected Suporte after the move: %+v", d)
+		}
+		if *preview.Departments[0].ParentDepartmentID != rhID {
+			t.Errorf("Expected TI under RH, got %v", preview.Departments[0].ParentDepartmentID)
+		}
+
+		if len(preview.ReportingChanges) != 2 {
+			t.Fatalf("Expected Maria and Bia to report to new managers, got %+v", preview.ReportingChanges)
+		}
+		bia := preview.ReportingChanges[1]
+		if bia.ManagerID != biaID || !slices.Equal(bia.Before, []uuid.UUID{mariaID, diretorID}) || !slices.Equal(bia.After, []uuid.UUID{mariaID, anaID, diretorID}) {
+			t.Errorf("Unexpected reporting change for Bia: %+v", bia)
+		}
+
+		if !preview.ConfirmationRequired || preview.ConfirmationThreshold != 4 || preview.ConfirmationToken == "" {
+			t.Errorf("Expected a move of 5 employees to need confirmation, got %+v", preview)
+		}
+		if ttl := time.Until(preview.ConfirmationExpiresAt); ttl <= 0 || ttl > time.Minute {
+			t.Errorf("Expected the token to last a minute, got %v", ttl)
+		}
+	})
+
+	t.Run("subárvore vira raiz", func(t *testing.T) {
+		preview, err := service.PreviewMove(tiID, uuid.Nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if preview.NewParentDepartmentID != nil || preview.NewDepth != 0 {
+			t.Errorf("Expected TI to become a root, got %+v", preview)
+		}
+		if len(preview.ReportingChanges) != 2 || len(preview.ReportingChanges[0].After) != 0 {
+			t.Errorf("Expected Maria to report to no one, got %+v", preview.ReportingChanges)
+		}
+	})
+
+	errorCases := []struct {
+		name          string
+		id, parentID  uuid.UUID
+		expectedError error
+	}{
+		{name: "departamento não encontrado", id: uuid.New(), parentID: rhID, expectedError: gorm.ErrRecordNotFound},
+		{name: "superior não encontrado", id: tiID, parentID: uuid.New(), expectedError: utils.ErrParentDepartmentNotFound},
+		{name: "para baixo de si mesmo", id: tiID, parentID: tiID, expectedError: utils.ErrCycleDetected},
+		{name: "para dentro da própria subárvore", id: tiID, parentID: suporteID, expectedError: utils.ErrCycleDetected},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := service.PreviewMove(tc.id, tc.parentID); !errors.Is(err, tc.expectedError) {
+				t.Errorf("Expected error %v, got %v", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestDepartmentService_UpdateDepartment_ConfirmacaoDaMovimentacao(t *testing.T) {
+	empresaID, tiID, suporteID, rhID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	moves := services.MoveConfirmation{Threshold: 4, TTL: time.Minute, Secret: []byte("segredo")}
+
+	setup := func(headcount int64, mc services.MoveConfirmation) (services.DepartmentService, *MockDepartmentRepository) {
+		deptRepo := &MockDepartmentRepository{
+			findByIDResult: &models.Department{ID: tiID, Name: "TI", ParentDepartmentID: &empresaID},
+			findAllResult: []*models.Department{
+				{ID: empresaID, Name: "Empresa"},
+				{ID: tiID, Name: "TI", ParentDepartmentID: &empresaID},
+				{ID: suporteID, Name: "Suporte", ParentDepartmentID: &tiID},
+				{ID: rhID, Name: "RH", ParentDepartmentID: &empresaID},
+			},
+			findAllSubordinateIDsResult: []uuid.UUID{tiID, suporteID},
+		}
+		employeeRepo := &MockEmployeeRepository{countByDepartmentIDs: map[uuid.UUID]int64{tiID: 1, suporteID: headcount - 1}}
+		return services.NewDepartmentService(deptRepo, employeeRepo, &MockAuditRepository{}, &MockHistoryRepository{}, &MockVersionRepository{}, mc), deptRepo
+	}
+	tokenFor := func(parentID uuid.UUID, mc services.MoveConfirmation) string {
+		service, _ := setup(5, mc)
+		preview, err := service.PreviewMove(tiID, parentID)
+		if err != nil {
+			t.Fatalf("PreviewMove failed: %v", err)
+		}
+		return preview.ConfirmationToken
+	}
+
+	testCases := []struct {
+		name          string
+		headcount     int64
+		moves         services.MoveConfirmation
+		parentID      *uuid.UUID
+		token         string
+		expectedError error
+	}{
+		{name: "abaixo do limite dispensa confirmação", headcount: 4, moves: moves, parentID: &rhID},
+		{name: "acima do limite sem token", headcount: 5, moves: moves, parentID: &rhID, expectedError: utils.ErrConfirmationRequired},
+		{name: "acima do limite com o token da prévia", headcount: 5, moves: moves, parentID: &rhID, token: tokenFor(rhID, moves)},
+		{name: "token de outra movimentação", headcount: 5, moves: moves, parentID: &rhID, token: tokenFor(uuid.Nil, moves), expectedError: utils.ErrInvalidConfirmation},
+		{name: "token assinado com outra chave", headcount: 5, moves: moves, parentID: &rhID, token: tokenFor(rhID, services.MoveConfirmation{Secret: []byte("outra")}), expectedError: utils.ErrInvalidConfirmation},
+		{name: "token adulterado", headcount: 5, moves: moves, parentID: &rhID, token: tokenFor(rhID, moves) + "x", expectedError: utils.ErrInvalidConfirmation},
+		{name: "sem mudança de superior", headcount: 5, moves: moves, parentID: &empresaID},
+		{name: "confirmação desligada", headcount: 500, parentID: &rhID},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, deptRepo := setup(tc.headcount, tc.moves)
+			dept, err := service.UpdateDepartment(tiID, nil, nil, tc.parentID, tc.token)
+
+			if tc.expectedError != nil {
+				if !errors.Is(err, tc.expectedError) {
+					t.Fatalf("Expected error %v, got %v", tc.expectedError, err)
+				}
+				if len(deptRepo.updatedDepts) != 0 {
+					t.Error("Expected the department not to be moved")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if *dept.ParentDepartmentID != *tc.parentID {
+				t.Errorf("Expected the department under %s, got %v", tc.parentID, dept.ParentDepartmentID)
+			}
+		})
+	}
+}
+
+func TestDepartmentService_Reorganize_ConfirmacaoDaMovimentacao(t *testing.T) {
+	empresaID, tiID, suporteID, rhID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	moves := services.MoveConfirmation{Threshold: 4, TTL: time.Minute, Secret: []byte("segredo")}
+
+	// Empresa > TI (1) > Suporte (4); Empresa > RH
+	setup := func() (services.DepartmentService, *MockDepartmentRepository) {
+		deptRepo := &MockDepartmentRepository{
+			findAllResult: []*models.Department{
+				{ID: empresaID, Name: "Empresa"},
+				{ID: tiID, Name: "TI", ParentDepartmentID: &empresaID},
+				{ID: suporteID, Name: "Suporte", ParentDepartmentID: &tiID},
+				{ID: rhID, Name: "RH", ParentDepartmentID: &empresaID},
+			},
+			findAllSubordinateIDsResult: []uuid.UUID{tiID, suporteID},
+		}
+		employeeRepo := &MockEmployeeRepository{countByDepartmentIDs: map[uuid.UUID]int64{tiID: 1, suporteID: 4}}
+		return services.NewDepartmentService(deptRepo, employeeRepo, &MockAuditRepository{}, &MockHistoryRepository{}, &MockVersionRepository{}, moves), deptRepo
+	}
+	service, _ := setup()
+	preview, err := service.PreviewMove(tiID, rhID)
+	if err != nil {
+		t.Fatalf("PreviewMove failed: %v", err)
+	}
+	token, raiz := preview.ConfirmationToken, uuid.Nil
+
+	t.Run("reparent", func(t *testing.T) {
+		testCases := []struct {
+			name         string
+			op           models.ReorganizationOperation
+			expectedCode string
+		}{
+			{name: "acima do limite sem token", op: models.ReorganizationOperation{Type: models.ReorganizeReparent, DepartmentID: &tiID, ParentDepartmentID: &rhID}, expectedCode: "CONFIRMATION_REQUIRED"},
+			{name: "acima do limite com o token da prévia", op: models.ReorganizationOperation{Type: models.ReorganizeReparent, DepartmentID: &tiID, ParentDepartmentID: &rhID, ConfirmationToken: token}},
+			{name: "token de outra movimentação", op: models.ReorganizationOperation{Type: models.ReorganizeReparent, DepartmentID: &tiID, ParentDepartmentID: &raiz, ConfirmationToken: token}, expectedCode: "CONFIRMATION_INVALID"},
+			{name: "abaixo do limite dispensa confirmação", op: models.ReorganizationOperation{Type: models.ReorganizeReparent, DepartmentID: &suporteID, ParentDepartmentID: &rhID}},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				service, deptRepo := setup()
+				report, err := service.Reorganize([]models.ReorganizationOperation{tc.op}, false)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if tc.expectedCode == "" {
+					if !report.Applied {
+						t.Errorf("Expected the plan to be applied, got %+v", report.Problems)
+					}
+					return
+				}
+				if report.Applied || len(deptRepo.updatedDepts) != 0 {
+					t.Error("Expected nothing to be written")
+				}
+				if len(report.Problems) != 1 || report.Problems[0].Code != tc.expectedCode || *report.Problems[0].Operation != 0 {
+					t.Errorf("Expected %s on the operation, got %+v", tc.expectedCode, report.Problems)
+				}
+			})
+		}
+	})
+
+	t.Run("mesclagem", func(t *testing.T) {
+		testCases := []struct {
+			name          string
+			token         string
+			expectedError error
+		}{
+			{name: "acima do limite sem token", expectedError: utils.ErrConfirmationRequired},
+			{name: "com o token da prévia para baixo do destino", token: token},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				service, deptRepo := setup()
+				_, err := service.MergeDepartment(tiID, rhID, nil, tc.token)
+				if !errors.Is(err, tc.expectedError) {
+					t.Fatalf("Expected error %v, got %v", tc.expectedError, err)
+				}
+				if merged := len(deptRepo.deleted) == 1; merged != (tc.expectedError == nil) {
+					t.Errorf("Expected merged=%v, got %v", tc.expectedError == nil, deptRepo.deleted)
+				}
+			})
+		}
+	})
+}
@@ -101,6 +101,25 @@ func TestDepartmentService_CreateDepartment(t *testing.T) {
 			},
 			expectedError: nil,
 		},
+		{
+			name:           "success creating department without manager",
+			departmentName: "IT",
+			managerID:      uuid.Nil,
+			parentID:       nil,
+			mockSetup:      func(deptRepo *MockDepartmentRepository, employeeRepo *MockEmployeeRepository) {},
+			expectedError:  nil,
+		},
+		{
+			name:           "error manager already runs another department",
+			departmentName: "IT",
+			managerID:      managerID,
+			parentID:       nil,
+			mockSetup: func(deptRepo *MockDepartmentRepository, employeeRepo *MockEmployeeRepository) {
+				employeeRepo.findByIDResult = &models.Employee{ID: managerID, Name: "João Manager", DepartmentID: uuid.New()}
+				deptRepo.findByManagerIDResult = []*models.Department{{ID: uuid.New(), Name: "Finance"}}
+			},
+			expectedError: utils.ErrManagerOfAnotherDepartment,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -110,7 +129,7 @@ func TestDepartmentService_CreateDepartment(t *testing.T) {
 			employeeRepo := &MockEmployeeRepository{}
 			tc.mockSetup(deptRepo, employeeRepo)
 
-			service := services.NewDepartmentService(deptRepo, employeeRepo)
+			service := services.NewDepartmentService(deptRepo, employeeRepo, &MockAuditRepository{}, &MockHistoryRepository{}, &MockVersionRepository{}, services.MoveConfirmation{})
 
 			// Execute
 			result, err := service.CreateDepartment(tc.departmentName, tc.managerID, tc.parentID)
@@ -182,10 +201,10 @@ func TestDepartmentService_GetDepartmentWithTree(t *testing.T) {
 			employeeRepo := &MockEmployeeRepository{}
 			tc.mockSetup(deptRepo, employeeRepo)
 
-			service := services.NewDepartmentService(deptRepo, employeeRepo)
+			service := services.NewDepartmentService(deptRepo, employeeRepo, &MockAuditRepository{}, &MockHistoryRepository{}, &MockVersionRepository{}, services.MoveConfirmation{})
 
 			// Execute
-			result, err := service.GetDepartmentWithTree(tc.id)
+			result, err := service.GetDepartmentWithTree(tc.id, 0, nil)
 
 			// Validate
 			if tc.expectedError != nil {
@@ -295,7 +314,7 @@ func TestDepartmentService_DeleteDepartment(t *testing.T) {
 			colabRepo := &MockEmployeeRepository{}
 			tc.mockSetup(deptoRepo, colabRepo)
 
-			service := services.NewDepartmentService(deptoRepo, colabRepo)
+			service := services.NewDepartmentService(deptoRepo, colabRepo, &MockAuditRepository{}, &MockHistoryRepository{}, &MockVersionRepository{}, services.MoveConfirmation{})
 
 			// Executar
 			err := service.DeleteDepartment(tc.id)
@@ -377,10 +396,10 @@ func TestDepartmentService_GetSubordinateEmployeesRecursively(t *testing.T) {
 			colabRepo := &MockEmployeeRepository{}
 			tc.mockSetup(deptoRepo, colabRepo)
 
-			service := services.NewDepartmentService(deptoRepo, colabRepo)
+			service := services.NewDepartmentService(deptoRepo, colabRepo, &MockAuditRepository{}, &MockHistoryRepository{}, &MockVersionRepository{}, services.MoveConfirmation{})
 
 			// Executar
-			result, err := service.GetSubordinateEmployeesRecursively(tc.gerenteID)
+			result, err := service.GetSubordinateEmployeesRecursively(tc.gerenteID, models.SubordinatesFilter{IncludeManagers: true, Page: 1, PageSize: 10})
 
 			// Validar
 			if tc.expectedError != nil {
@@ -402,6 +421,176 @@ func TestDepartmentService_GetSubordinateEmployeesRecursively(t *testing.T) {
 	}
 }
 
+func TestDepartmentService_GetDepartmentWithTree_BuildsHierarchy(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	// Empresa -> TI -> Desenvolvimento, Empresa -> RH
+	empresa := &models.Department{ID: uuid.New(), Name: "Empresa"}
+	ti := &models.Department{ID: uuid.New(), Name: "TI", ParentDepartmentID: &empresa.ID}
+	rh := &models.Department{ID: uuid.New(), Name: "RH", ParentDepartmentID: &empresa.ID}
+	dev := &models.Department{ID: uuid.New(), Name: "Desenvolvimento", ParentDepartmentID: &ti.ID}
+
+	deptoRepo := &MockDepartmentRepository{
+		findByIDWithManagerResult: empresa,
+		findDescendantsResult:     []*models.Department{ti, rh, dev},
+	}
+	service := services.NewDepartmentService(deptoRepo, &MockEmployeeRepository{}, &MockAuditRepository{}, &MockHistoryRepository{}, &MockVersionRepository{}, services.MoveConfirmation{})
+
+	result, err := service.GetDepartmentWithTree(empresa.ID, 0, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(result.SubDepartments) != 2 {
+		t.Fatalf("Expected 2 direct sub-departments, got %d", len(result.SubDepartments))
+	}
+	if len(ti.SubDepartments) != 1 || ti.SubDepartments[0].ID != dev.ID {
+		t.Errorf("Expected Desenvolvimento under TI, got %v", ti.SubDepartments)
+	}
+	if len(rh.SubDepartments) != 0 {
+		t.Errorf("Expected RH to have no sub-departments, got %d", len(rh.SubDepartments))
+	}
+}
+
+func TestDepartmentService_GetSubordinateEmployeesRecursively_ReportingLine(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	gerenteID := uuid.New()
+	subGerenteID := uuid.New()
+
+	// TI (gerente) -> Desenvolvimento (subgerente) -> Backend
+	ti := &models.Department{ID: uuid.New(), Name: "TI", ManagerID: &gerenteID}
+	dev := &models.Department{ID: uuid.New(), Name: "Desenvolvimento", ManagerID: &subGerenteID, ParentDepartmentID: &ti.ID}
+	backend := &models.Department{ID: uuid.New(), Name: "Backend", ParentDepartmentID: &dev.ID}
+
+	dev1 := &models.Employee{ID: uuid.New(), Name: "Ana", DepartmentID: backend.ID}
+	dev2 := &models.Employee{ID: uuid.New(), Name: "Bruno", DepartmentID: dev.ID}
+
+	testCases := []struct {
+		name            string
+		includeManagers bool
+		scope           []uuid.UUID
+		expectDepts     int
+		expectExcluded  []uuid.UUID
+	}{
+		{
+			name:            "inclui gerentes",
+			includeManagers: true,
+			expectDepts:     3,
+			expectExcluded:  nil,
+		},
+		{
+			name:            "exclui gerentes da linha",
+			includeManagers: false,
+			expectDepts:     3,
+			expectExcluded:  []uuid.UUID{gerenteID, subGerenteID},
+		},
+		{
+			name:            "restrito à subárvore de quem consulta",
+			includeManagers: true,
+			scope:           []uuid.UUID{dev.ID, backend.ID},
+			expectDepts:     2,
+			expectExcluded:  nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deptoRepo := &MockDepartmentRepository{
+				findByManagerIDResult: []*models.Department{ti},
+				findDescendantsResult: []*models.Department{dev, backend},
+			}
+			colabRepo := &MockEmployeeRepository{
+				findByIDResult:            &models.Employee{ID: gerenteID, Name: "João Gerente"},
+				findByDepartmentIDsResult: []*models.Employee{dev1, dev2},
+			}
+			service := services.NewDepartmentService(deptoRepo, colabRepo, &MockAuditRepository{}, &MockHistoryRepository{}, &MockVersionRepository{}, services.MoveConfirmation{})
+
+			result, err := service.GetSubordinateEmployeesRecursively(gerenteID, models.SubordinatesFilter{
+				IncludeManagers: tc.includeManagers,
+				Page:            1,
+				PageSize:        10,
+				Scope:           tc.scope,
+			})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if len(colabRepo.listByDepartmentIDsDepts) != tc.expectDepts {
+				t.Errorf("Expected %d departments in the line, got %d", tc.expectDepts, len(colabRepo.listByDepartmentIDsDepts))
+			}
+			if len(colabRepo.listByDepartmentIDsExcl) != len(tc.expectExcluded) {
+				t.Errorf("Expected %d excluded managers, got %d", len(tc.expectExcluded), len(colabRepo.listByDepartmentIDsExcl))
+			}
+
+			if result.Total != 2 || len(result.Items) != 2 {
+				t.Fatalf("Expected 2 employees, got %d", len(result.Items))
+			}
+			for _, item := range result.Items {
+				switch item.ID {
+				case dev1.ID:
+					if item.DepartmentName != "Backend" || item.Depth != 2 {
+						t.Errorf("Expected Ana in Backend at depth 2, got %s at %d", item.DepartmentName, item.Depth)
+					}
+				case dev2.ID:
+					if item.DepartmentName != "Desenvolvimento" || item.Depth != 1 {
+						t.Errorf("Expected Bruno in Desenvolvimento at depth 1, got %s at %d", item.DepartmentName, item.Depth)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDepartmentService_ManagedSubtreeIDs(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	gerenteID := uuid.New()
+	ti := &models.Department{ID: uuid.New(), Name: "TI", ManagerID: &gerenteID}
+	subarvore := []uuid.UUID{ti.ID, uuid.New(), uuid.New()}
+
+	testCases := []struct {
+		name        string
+		managed     []*models.Department
+		expectedIDs []uuid.UUID
+	}{
+		{
+			name:        "departamento gerenciado e descendentes",
+			managed:     []*models.Department{ti},
+			expectedIDs: subarvore,
+		},
+		{
+			name:        "colaborador que não gerencia nada",
+			managed:     nil,
+			expectedIDs: []uuid.UUID{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deptoRepo := &MockDepartmentRepository{
+				findByManagerIDResult:       tc.managed,
+				findAllSubordinateIDsResult: subarvore,
+			}
+			service := services.NewDepartmentService(deptoRepo, &MockEmployeeRepository{}, &MockAuditRepository{}, &MockHistoryRepository{}, &MockVersionRepository{}, services.MoveConfirmation{})
+
+			ids, err := service.ManagedSubtreeIDs(gerenteID)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			// Um escopo vazio precisa continuar restringindo (não nil)
+			if ids == nil || len(ids) != len(tc.expectedIDs) {
+				t.Fatalf("Expected %v, got %v", tc.expectedIDs, ids)
+			}
+			for i := range ids {
+				if ids[i] != tc.expectedIDs[i] {
+					t.Errorf("Position %d: expected %v, got %v", i, tc.expectedIDs[i], ids[i])
+				}
+			}
+		})
+	}
+}
+
 // Benchmark para teste de performance
 func BenchmarkDepartamentoService_CreateDepartment(b *testing.B) {
 	gerenteID := uuid.New()
@@ -417,7 +606,7 @@ func BenchmarkDepartamentoService_CreateDepartment(b *testing.B) {
 		findByIDError: nil,
 	}
 
-	service := services.NewDepartmentService(deptoRepo, colabRepo)
+	service := services.NewDepartmentService(deptoRepo, colabRepo, &MockAuditRepository{}, &MockHistoryRepository{}, &MockVersionRepository{}, services.MoveConfirmation{})
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -501,6 +690,52 @@ func TestDepartmentService_UpdateDepartment(t *testing.T) {
 			},
 			expectedError: utils.ErrCycleDetected,
 		},
+		{
+			name:     "ciclo detectado com subordinado como superior",
+			id:       departamentoID,
+			parentID: &superiorID,
+			mockSetup: func(deptoRepo *MockDepartmentRepository, colabRepo *MockEmployeeRepository) {
+				departamento := &models.Department{
+					ID:   departamentoID,
+					Name: "TI",
+				}
+				deptoRepo.findByIDResult = departamento
+				deptoRepo.findByIDError = nil
+				// O novo superior está abaixo do próprio departamento
+				deptoRepo.isSubordinateResult = true
+			},
+			expectedError: utils.ErrCycleDetected,
+		},
+		{
+			name:      "gerente não pertence ao departamento",
+			id:        departamentoID,
+			managerID: &novoGerenteID,
+			mockSetup: func(deptoRepo *MockDepartmentRepository, colabRepo *MockEmployeeRepository) {
+				deptoRepo.findByIDResult = &models.Department{ID: departamentoID, Name: "TI"}
+				colabRepo.findByIDResult = &models.Employee{ID: novoGerenteID, Name: "Novo Gerente", DepartmentID: uuid.New()}
+			},
+			expectedError: utils.ErrManagerNotBelongToDepartment,
+		},
+		{
+			name:      "gerente não encontrado",
+			id:        departamentoID,
+			managerID: &novoGerenteID,
+			mockSetup: func(deptoRepo *MockDepartmentRepository, colabRepo *MockEmployeeRepository) {
+				deptoRepo.findByIDResult = &models.Department{ID: departamentoID, Name: "TI"}
+				colabRepo.findByIDError = gorm.ErrRecordNotFound
+			},
+			expectedError: utils.ErrManagerNotFound,
+		},
+		{
+			name:     "erro ao verificar ciclo",
+			id:       departamentoID,
+			parentID: &superiorID,
+			mockSetup: func(deptoRepo *MockDepartmentRepository, colabRepo *MockEmployeeRepository) {
+				deptoRepo.findByIDResult = &models.Department{ID: departamentoID, Name: "TI"}
+				deptoRepo.isSubordinateError = gorm.ErrInvalidTransaction
+			},
+			expectedError: gorm.ErrInvalidTransaction,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -510,10 +745,10 @@ func TestDepartmentService_UpdateDepartment(t *testing.T) {
 			colabRepo := &MockEmployeeRepository{}
 			tc.mockSetup(deptoRepo, colabRepo)
 
-			service := services.NewDepartmentService(deptoRepo, colabRepo)
+			service := services.NewDepartmentService(deptoRepo, colabRepo, &MockAuditRepository{}, &MockHistoryRepository{}, &MockVersionRepository{}, services.MoveConfirmation{})
 
 			// Execute
-			result, err := service.UpdateDepartment(tc.id, tc.departmentName, tc.managerID, tc.parentID)
+			result, err := service.UpdateDepartment(tc.id, tc.departmentName, tc.managerID, tc.parentID, "")
 
 			// Validar
 			if tc.expectedError != nil {
@@ -547,6 +782,8 @@ func TestDepartmentService_ListDepartments(t *testing.T) {
 		pageSize       int
 		mockSetup      func(*MockDepartmentRepository, *MockEmployeeRepository)
 		expectedError  error
+		expectedTotal  int64
+		expectedPages  int
 	}{
 		{
 			name:           "sucesso ao listar departamentos",
@@ -560,9 +797,30 @@ func TestDepartmentService_ListDepartments(t *testing.T) {
 					{ID: uuid.New(), Name: "TI Desenvolvimento"},
 				}
 				deptoRepo.listResult = departamentos
+				deptoRepo.listTotal = 21
 				deptoRepo.listError = nil
 			},
 			expectedError: nil,
+			expectedTotal: 21,
+			expectedPages: 3,
+		},
+		{
+			name:     "nenhum departamento encontrado",
+			page:     1,
+			pageSize: 10,
+			mockSetup: func(deptoRepo *MockDepartmentRepository, colabRepo *MockEmployeeRepository) {
+				deptoRepo.listResult = nil
+			},
+			expectedError: nil,
+		},
+		{
+			name:     "erro no repositório",
+			page:     1,
+			pageSize: 10,
+			mockSetup: func(deptoRepo *MockDepartmentRepository, colabRepo *MockEmployeeRepository) {
+				deptoRepo.listError = gorm.ErrInvalidDB
+			},
+			expectedError: gorm.ErrInvalidDB,
 		},
 	}
 
@@ -573,10 +831,10 @@ func TestDepartmentService_ListDepartments(t *testing.T) {
 			colabRepo := &MockEmployeeRepository{}
 			tc.mockSetup(deptoRepo, colabRepo)
 
-			service := services.NewDepartmentService(deptoRepo, colabRepo)
+			service := services.NewDepartmentService(deptoRepo, colabRepo, &MockAuditRepository{}, &MockHistoryRepository{}, &MockVersionRepository{}, services.MoveConfirmation{})
 
 			// Execute
-			result, err := service.ListDepartments(tc.departmentName, tc.managerName, tc.parentID, tc.page, tc.pageSize)
+			result, err := service.ListDepartments(tc.departmentName, tc.managerName, tc.parentID, models.Sort{}, tc.page, tc.pageSize)
 
 			// Validate
 			if tc.expectedError != nil {
@@ -591,7 +849,16 @@ func TestDepartmentService_ListDepartments(t *testing.T) {
 					t.Errorf("Expected no error, got %v", err)
 				}
 				if result == nil {
-					t.Errorf("Expected result, got nil")
+					t.Fatalf("Expected result, got nil")
+				}
+				if result.Items == nil {
+					t.Errorf("Expected empty items, got nil")
+				}
+				if result.Page != tc.page || result.PageSize != tc.pageSize {
+					t.Errorf("Expected page %d/%d, got %d/%d", tc.page, tc.pageSize, result.Page, result.PageSize)
+				}
+				if result.Total != tc.expectedTotal || result.TotalPages != tc.expectedPages {
+					t.Errorf("Expected total %d in %d pages, got %d in %d", tc.expectedTotal, tc.expectedPages, result.Total, result.TotalPages)
 				}
 			}
 		})
@@ -0,0 +1,99 @@
+package services_test
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/services"
+	"ManageEmployeesandDepartments/internal/utils"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestEmployeeService_HistoricoDeDepartamentos(t *testing.T) {
+	employeeID := uuid.New()
+	currentDeptID := uuid.New()
+	newDeptID := uuid.New()
+
+	t.Run("transferência encerra o período atual e abre outro", func(t *testing.T) {
+		employeeRepo := &MockEmployeeRepository{findByIDResult: &models.Employee{ID: employeeID, DepartmentID: currentDeptID}}
+		deptRepo := &MockDepartmentRepository{findByIDResult: &models.Department{ID: newDeptID}}
+		historyRepo := &MockHistoryRepository{}
+
+		service := services.NewEmployeeService(deptRepo, employeeRepo, &MockAuditRepository{}, historyRepo)
+		if _, err := service.UpdateEmployee(employeeID, nil, nil, newDeptID); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(historyRepo.closed) != 1 || historyRepo.closed[0] != employeeID {
+			t.Errorf("Expected the current assignment to be closed, got %v", historyRepo.closed)
+		}
+		if len(historyRepo.created) != 1 || historyRepo.created[0].DepartmentID != newDeptID || historyRepo.created[0].EmployeeID != employeeID {
+			t.Fatalf("Expected a new assignment in %s, got %+v", newDeptID, historyRepo.created)
+		}
+	})
+
+	t.Run("atualização sem troca de departamento não gera histórico", func(t *testing.T) {
+		employeeRepo := &MockEmployeeRepository{findByIDResult: &models.Employee{ID: employeeID, DepartmentID: currentDeptID}}
+		deptRepo := &MockDepartmentRepository{findByIDResult: &models.Department{ID: currentDeptID}}
+		historyRepo := &MockHistoryRepository{}
+
+		service := services.NewEmployeeService(deptRepo, employeeRepo, &MockAuditRepository{}, historyRepo)
+		if _, err := service.UpdateEmployee(employeeID, stringPtr("Novo Nome"), nil, currentDeptID); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(historyRepo.closed)+len(historyRepo.created) != 0 {
+			t.Errorf("Expected no history change, got %v closed and %v created", historyRepo.closed, historyRepo.created)
+		}
+	})
+
+	t.Run("admissão abre o primeiro período", func(t *testing.T) {
+		deptRepo := &MockDepartmentRepository{findByIDResult: &models.Department{ID: currentDeptID}}
+		historyRepo := &MockHistoryRepository{}
+
+		service := services.NewEmployeeService(deptRepo, &MockEmployeeRepository{}, &MockAuditRepository{}, historyRepo)
+		employee, err := service.CreateEmployee("Maria", "12345678909", nil, currentDeptID)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(historyRepo.created) != 1 || historyRepo.created[0].EmployeeID != employee.ID || historyRepo.created[0].DepartmentID != currentDeptID {
+			t.Errorf("Expected the first assignment, got %+v", historyRepo.created)
+		}
+	})
+
+	t.Run("desligamento encerra o período atual", func(t *testing.T) {
+		employeeRepo := &MockEmployeeRepository{findByIDResult: &models.Employee{ID: employeeID, DepartmentID: currentDeptID}}
+		historyRepo := &MockHistoryRepository{}
+
+		service := services.NewEmployeeService(&MockDepartmentRepository{}, employeeRepo, &MockAuditRepository{}, historyRepo)
+		if err := service.DeleteEmployee(employeeID); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(historyRepo.closed) != 1 || len(historyRepo.created) != 0 {
+			t.Errorf("Expected only the current assignment to be closed, got %v closed and %v created", historyRepo.closed, historyRepo.created)
+		}
+	})
+
+	t.Run("colaborador sem histórico não encontrado", func(t *testing.T) {
+		service := services.NewEmployeeService(&MockDepartmentRepository{}, &MockEmployeeRepository{}, &MockAuditRepository{}, &MockHistoryRepository{})
+		if _, err := service.GetDepartmentHistory(employeeID); !errors.Is(err, utils.ErrEmployeeNotFound) {
+			t.Errorf("Expected ErrEmployeeNotFound, got %v", err)
+		}
+	})
+}
+
+func TestDepartmentService_CreateDepartment_TransfereGerenteNoHistorico(t *testing.T) {
+	managerID := uuid.New()
+	employeeRepo := &MockEmployeeRepository{findByIDResult: &models.Employee{ID: managerID, DepartmentID: uuid.New()}}
+	historyRepo := &MockHistoryRepository{}
+
+	service := services.NewDepartmentService(&MockDepartmentRepository{}, employeeRepo, &MockAuditRepository{}, historyRepo, &MockVersionRepository{}, services.MoveConfirmation{})
+	dept, err := service.CreateDepartment("Financeiro", managerID, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(historyRepo.created) != 1 || historyRepo.created[0].EmployeeID != managerID || historyRepo.created[0].DepartmentID != dept.ID {
+		t.Errorf("Expected the manager transfer in the history, got %+v", historyRepo.created)
+	}
+}
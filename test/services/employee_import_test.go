@@ -0,0 +1,145 @@
+package services_test
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/services"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestEmployeeService_ImportEmployees(t *testing.T) {
+	empresa := &models.Department{ID: uuid.New(), Name: "Empresa"}
+	ti := &models.Department{ID: uuid.New(), Name: "TI", ParentDepartmentID: &empresa.ID}
+	suporte := &models.Department{ID: uuid.New(), Name: "Suporte", ParentDepartmentID: &ti.ID}
+	// Dois departamentos com o mesmo caminho tornam o nome ambíguo
+	rh1 := &models.Department{ID: uuid.New(), Name: "RH", ParentDepartmentID: &empresa.ID}
+	rh2 := &models.Department{ID: uuid.New(), Name: "RH", ParentDepartmentID: &empresa.ID}
+	depts := []*models.Department{empresa, ti, suporte, rh1, rh2}
+
+	rows := func() []*models.EmployeeImportRow {
+		return []*models.EmployeeImportRow{
+			{Line: 2, Name: "Maria", CPF: "123.456.789-09", RG: "123456789", Department: "empresa / ti / SUPORTE"},
+			{Line: 3, Name: "João", CPF: "98765432100", Department: ti.ID.String()},
+		}
+	}
+
+	testCases := []struct {
+		name            string
+		rows            func() []*models.EmployeeImportRow
+		opts            models.ImportOptions
+		createError     error
+		cpfDuplicated   bool
+		expectedStatus  []string
+		expectedCodes   []string
+		expectedWritten int
+		committed       bool
+	}{
+		{
+			name:            "importação atômica de linhas válidas",
+			rows:            rows,
+			opts:            models.ImportOptions{Mode: models.ImportModeAtomic},
+			expectedStatus:  []string{models.ImportStatusCreated, models.ImportStatusCreated},
+			expectedWritten: 2,
+			committed:       true,
+		},
+		{
+			name:           "simulação não grava",
+			rows:           rows,
+			opts:           models.ImportOptions{Mode: models.ImportModeAtomic, DryRun: true},
+			expectedStatus: []string{models.ImportStatusValid, models.ImportStatusValid},
+		},
+		{
+			name: "importação atômica com linha inválida não grava nada",
+			rows: func() []*models.EmployeeImportRow {
+				r := rows()
+				r[1].CPF = "11111111111"
+				return r
+			},
+			opts:           models.ImportOptions{Mode: models.ImportModeAtomic},
+			expectedStatus: []string{models.ImportStatusValid, models.ImportStatusInvalid},
+			expectedCodes:  []string{"", "INVALID_CPF"},
+		},
+		{
+			name: "melhor esforço grava as linhas válidas",
+			rows: func() []*models.EmployeeImportRow {
+				r := rows()
+				r[1].Department = "Empresa/Financeiro"
+				return r
+			},
+			opts:            models.ImportOptions{Mode: models.ImportModeBestEffort},
+			expectedStatus:  []string{models.ImportStatusCreated, models.ImportStatusInvalid},
+			expectedCodes:   []string{"", "DEPARTMENT_NOT_FOUND"},
+			expectedWritten: 1,
+			committed:       true,
+		},
+		{
+			name: "documentos repetidos no arquivo e caminho ambíguo",
+			rows: func() []*models.EmployeeImportRow {
+				r := rows()
+				return append(r,
+					&models.EmployeeImportRow{Line: 4, Name: "Ana", CPF: "12345678909", Department: ti.ID.String()},
+					&models.EmployeeImportRow{Line: 5, Name: "Bia", CPF: "52998224725", RG: "123456789", Department: ti.ID.String()},
+					&models.EmployeeImportRow{Line: 6, Name: "Caio", CPF: "39053344705", Department: "Empresa/RH"},
+					&models.EmployeeImportRow{Line: 7, Name: " ", CPF: "11144477735", Department: ti.ID.String()},
+				)
+			},
+			opts:            models.ImportOptions{Mode: models.ImportModeBestEffort},
+			expectedStatus:  []string{models.ImportStatusCreated, models.ImportStatusCreated, models.ImportStatusInvalid, models.ImportStatusInvalid, models.ImportStatusInvalid, models.ImportStatusInvalid},
+			expectedCodes:   []string{"", "", "CPF_DUPLICATED", "RG_DUPLICATED", "DEPARTMENT_AMBIGUOUS", "INVALID_DATA"},
+			expectedWritten: 2,
+			committed:       true,
+		},
+		{
+			name:           "falha na gravação desfaz a importação atômica",
+			rows:           rows,
+			opts:           models.ImportOptions{Mode: models.ImportModeAtomic},
+			createError:    errors.New("duplicate key"),
+			cpfDuplicated:  true,
+			expectedStatus: []string{models.ImportStatusInvalid, models.ImportStatusValid},
+			expectedCodes:  []string{"CPF_DUPLICATED", ""},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deptRepo := &MockDepartmentRepository{findAllResult: depts, findByIDResult: ti}
+			employeeRepo := &MockEmployeeRepository{createError: tc.createError, isCPFDuplicatedResult: tc.cpfDuplicated}
+			auditRepo := &MockAuditRepository{}
+			historyRepo := &MockHistoryRepository{}
+
+			service := services.NewEmployeeService(deptRepo, employeeRepo, auditRepo, historyRepo)
+			report, err := service.WithActor("rh@empresa.com").ImportEmployees(tc.rows(), tc.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(report.Rows) != len(tc.expectedStatus) || report.Total != len(tc.expectedStatus) {
+				t.Fatalf("Expected %d rows, got %+v", len(tc.expectedStatus), report)
+			}
+			for i, row := range report.Rows {
+				if row.Status != tc.expectedStatus[i] {
+					t.Errorf("Line %d: expected status %s, got %s (%+v)", row.Line, tc.expectedStatus[i], row.Status, row.Error)
+				}
+				code := ""
+				if row.Error != nil {
+					code = row.Error.Code
+				}
+				if tc.expectedCodes != nil && code != tc.expectedCodes[i] {
+					t.Errorf("Line %d: expected code %q, got %q", row.Line, tc.expectedCodes[i], code)
+				}
+				if (row.EmployeeID != nil) != (row.Status == models.ImportStatusCreated) {
+					t.Errorf("Line %d: expected an employee ID only for written rows, got %v", row.Line, row.EmployeeID)
+				}
+			}
+
+			if report.Committed != tc.committed || report.Created != tc.expectedWritten {
+				t.Errorf("Expected %d rows written (committed %v), got %+v", tc.expectedWritten, tc.committed, report)
+			}
+			if len(auditRepo.entries) != tc.expectedWritten || len(historyRepo.created) != tc.expectedWritten {
+				t.Errorf("Expected %d audit entries and assignments, got %d and %d", tc.expectedWritten, len(auditRepo.entries), len(historyRepo.created))
+			}
+		})
+	}
+}
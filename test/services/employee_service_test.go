@@ -2,9 +2,11 @@ package services_test
 
 import (
 	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/repository"
 	"ManageEmployeesandDepartments/internal/services"
 	"ManageEmployeesandDepartments/internal/utils"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/goleak"
@@ -24,12 +26,39 @@ type MockEmployeeRepository struct {
 	deleteError               error
 	countByDepartmentIDResult int64
 	countByDepartmentIDError  error
+	countByDepartmentIDs      map[uuid.UUID]int64
 	findByDepartmentIDsResult []*models.Employee
 	findByDepartmentIDsError  error
+	listByDepartmentIDsDepts  []uuid.UUID
+	listByDepartmentIDsExcl   []uuid.UUID
 	listResult                []*models.Employee
+	listTotal                 int64
 	listError                 error
+	listCPFArg                *string
+	listByCursorResult        []*models.Employee
+	listByCursorCursor        *models.Cursor
+	listByCursorBackward      bool
+	listByCursorLimit         int
 	isCPFDuplicatedResult     bool
 	isRGDuplicatedResult      bool
+	withDeletedResult         *models.Employee
+	withDeletedError          error
+	listDeletedResult         []*models.Employee
+	restoreError              error
+	restored                  bool
+	cpfInUse                  bool
+	rgInUse                   bool
+	formerResult              *models.Employee
+	updated                   *models.Employee
+	lockedIDs                 []uuid.UUID
+	rehireError               error
+	rehired                   *models.Employee
+	anonymized                *models.Employee
+	exportResult              []*models.EmployeeExportRow
+}
+
+func (m *MockEmployeeRepository) WithTx(tx *gorm.DB) repository.EmployeeRepository {
+	return m
 }
 
 func (m *MockEmployeeRepository) Create(employee *models.Employee) error {
@@ -49,11 +78,17 @@ func (m *MockEmployeeRepository) FindByID(id uuid.UUID) (*models.Employee, error
 	return m.findByIDResult, m.findByIDError
 }
 
+func (m *MockEmployeeRepository) FindByIDForUpdate(id uuid.UUID) (*models.Employee, error) {
+	m.lockedIDs = append(m.lockedIDs, id)
+	return m.FindByID(id)
+}
+
 func (m *MockEmployeeRepository) FindAll() ([]models.Employee, error) {
 	return m.findAllResult, m.findAllError
 }
 
 func (m *MockEmployeeRepository) Update(employee *models.Employee) error {
+	m.updated = employee
 	return m.updateError
 }
 
@@ -65,12 +100,63 @@ func (m *MockEmployeeRepository) CountByDepartmentID(deptID uuid.UUID) (int64, e
 	return m.countByDepartmentIDResult, m.countByDepartmentIDError
 }
 
+func (m *MockEmployeeRepository) CountByDepartmentIDs(deptIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+	counts := map[uuid.UUID]int64{}
+	for _, id := range deptIDs {
+		if count, ok := m.countByDepartmentIDs[id]; ok {
+			counts[id] = count
+		}
+	}
+	return counts, m.countByDepartmentIDError
+}
+
 func (m *MockEmployeeRepository) FindByDepartmentIDs(deptIDs []uuid.UUID) ([]*models.Employee, error) {
 	return m.findByDepartmentIDsResult, m.findByDepartmentIDsError
 }
 
-func (m *MockEmployeeRepository) List(name, cpf, rg *string, deptID *uuid.UUID, page, pageSize int) ([]*models.Employee, error) {
-	return m.listResult, m.listError
+func (m *MockEmployeeRepository) FindByDepartmentIDsForUpdate(deptIDs []uuid.UUID) ([]*models.Employee, error) {
+	for _, e := range m.findByDepartmentIDsResult {
+		m.lockedIDs = append(m.lockedIDs, e.ID)
+	}
+	return m.FindByDepartmentIDs(deptIDs)
+}
+
+func (m *MockEmployeeRepository) ListByDepartmentIDs(deptIDs, excludeIDs []uuid.UUID, page, pageSize int) ([]*models.Employee, int64, error) {
+	m.listByDepartmentIDsDepts = deptIDs
+	m.listByDepartmentIDsExcl = excludeIDs
+	return m.findByDepartmentIDsResult, int64(len(m.findByDepartmentIDsResult)), m.findByDepartmentIDsError
+}
+
+func (m *MockEmployeeRepository) List(filter models.EmployeeFilter, sort models.Sort, page, pageSize int) ([]*models.Employee, int64, error) {
+	m.listCPFArg = filter.CPF
+	return m.listResult, m.listTotal, m.listError
+}
+
+func (m *MockEmployeeRepository) Export(filter models.EmployeeFilter, sort models.Sort, fn func(*models.EmployeeExportRow) error) error {
+	m.listCPFArg = filter.CPF
+	for _, row := range m.exportResult {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MockEmployeeRepository) ListByCursor(filter models.EmployeeFilter, sort models.Sort, cursor *models.Cursor, backward bool, limit int) ([]*models.Employee, []models.Cursor, error) {
+	m.listCPFArg = filter.CPF
+	m.listByCursorCursor = cursor
+	m.listByCursorBackward = backward
+	m.listByCursorLimit = limit
+
+	result := m.listByCursorResult
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	cursors := make([]models.Cursor, 0, len(result))
+	for _, e := range result {
+		cursors = append(cursors, models.Cursor{Sort: sort.Field, Desc: sort.Desc, Key: e.Name, ID: e.ID})
+	}
+	return result, cursors, m.listError
 }
 
 func (m *MockEmployeeRepository) IsCPFDuplicated(err error) bool {
@@ -81,8 +167,53 @@ func (m *MockEmployeeRepository) IsRGDuplicated(err error) bool {
 	return m.isRGDuplicatedResult
 }
 
+func (m *MockEmployeeRepository) FindByIDWithDeleted(id uuid.UUID) (*models.Employee, error) {
+	return m.withDeletedResult, m.withDeletedError
+}
+
+func (m *MockEmployeeRepository) FindByIDWithDeletedForUpdate(id uuid.UUID) (*models.Employee, error) {
+	return m.withDeletedResult, m.withDeletedError
+}
+
+func (m *MockEmployeeRepository) ListDeleted(page, pageSize int) ([]*models.Employee, int64, error) {
+	return m.listDeletedResult, int64(len(m.listDeletedResult)), m.listError
+}
+
+func (m *MockEmployeeRepository) Restore(id uuid.UUID) error {
+	m.restored = m.restoreError == nil
+	return m.restoreError
+}
+
+func (m *MockEmployeeRepository) Rehire(employee *models.Employee) error {
+	if m.rehireError == nil {
+		m.rehired = employee
+	}
+	return m.rehireError
+}
+
+func (m *MockEmployeeRepository) IsCPFInUse(cpf string) (bool, error) {
+	return m.cpfInUse, nil
+}
+
+func (m *MockEmployeeRepository) IsRGInUse(rg string) (bool, error) {
+	return m.rgInUse, nil
+}
+
+func (m *MockEmployeeRepository) Anonymize(employee *models.Employee) error {
+	m.anonymized = employee
+	return nil
+}
+
+func (m *MockEmployeeRepository) FindDeletedByCPFForUpdate(cpf string) (*models.Employee, error) {
+	if m.formerResult == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return m.formerResult, nil
+}
+
 // MockDepartmentRepository implements repository.DepartmentRepository for tests
 type MockDepartmentRepository struct {
+	lockedAll                   bool
 	findByIDResult              *models.Department
 	findByIDError               error
 	findByIDWithManagerResult   *models.Department
@@ -94,16 +225,36 @@ type MockDepartmentRepository struct {
 	countSubDepartmentsError    error
 	findSubDepartmentsResult    []*models.Department
 	findSubDepartmentsError     error
+	findDescendantsResult       []*models.Department
+	findDescendantsError        error
 	findByManagerIDResult       []*models.Department
 	findByManagerIDError        error
 	findAllSubordinateIDsResult []uuid.UUID
 	findAllSubordinateIDsError  error
 	listResult                  []*models.Department
+	listTotal                   int64
 	listError                   error
 	isSubordinateResult         bool
 	isSubordinateError          error
 	isManagerResult             bool
 	isManagerError              error
+	withDeletedResult           *models.Department
+	withDeletedError            error
+	listDeletedResult           []*models.Department
+	restoreError                error
+	restored                    bool
+	findAllResult               []*models.Department
+	exportResult                []*models.DepartmentExportRow
+	updatedDepts                []*models.Department
+	deleted                     []uuid.UUID
+}
+
+func (m *MockDepartmentRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return fn(nil)
+}
+
+func (m *MockDepartmentRepository) WithTx(tx *gorm.DB) repository.DepartmentRepository {
+	return m
 }
 
 func (m *MockDepartmentRepository) Create(dept *models.Department) error {
@@ -114,6 +265,27 @@ func (m *MockDepartmentRepository) FindByID(id uuid.UUID) (*models.Department, e
 	return m.findByIDResult, m.findByIDError
 }
 
+func (m *MockDepartmentRepository) FindAll() ([]*models.Department, error) {
+	return m.findAllResult, nil
+}
+
+func (m *MockDepartmentRepository) FindAllForUpdate() ([]*models.Department, error) {
+	m.lockedAll = true
+	return m.findAllResult, nil
+}
+
+func (m *MockDepartmentRepository) FindByIDForUpdate(id uuid.UUID) (*models.Department, error) {
+	return m.findByIDResult, m.findByIDError
+}
+
+func (m *MockDepartmentRepository) FindByIDForShare(id uuid.UUID) (*models.Department, error) {
+	return m.FindByID(id)
+}
+
+func (m *MockDepartmentRepository) LockAncestors(id uuid.UUID) error {
+	return nil
+}
+
 func (m *MockDepartmentRepository) FindByIDWithManager(id uuid.UUID) (*models.Department, error) {
 	return m.findByIDWithManagerResult, m.findByIDWithManagerError
 }
@@ -122,11 +294,17 @@ func (m *MockDepartmentRepository) FindSubDepartments(parentID uuid.UUID) ([]*mo
 	return m.findSubDepartmentsResult, m.findSubDepartmentsError
 }
 
+func (m *MockDepartmentRepository) FindDescendants(id uuid.UUID, maxDepth int) ([]*models.Department, error) {
+	return m.findDescendantsResult, m.findDescendantsError
+}
+
 func (m *MockDepartmentRepository) Update(dept *models.Department) error {
+	m.updatedDepts = append(m.updatedDepts, dept)
 	return m.updateError
 }
 
 func (m *MockDepartmentRepository) Delete(id uuid.UUID) error {
+	m.deleted = append(m.deleted, id)
 	return m.deleteError
 }
 
@@ -150,8 +328,135 @@ func (m *MockDepartmentRepository) FindAllSubordinateIDs(id uuid.UUID) ([]uuid.U
 	return m.findAllSubordinateIDsResult, m.findAllSubordinateIDsError
 }
 
-func (m *MockDepartmentRepository) List(name, managerName *string, parentID *uuid.UUID, page, pageSize int) ([]*models.Department, error) {
-	return m.listResult, m.listError
+func (m *MockDepartmentRepository) List(name, managerName *string, parentID *uuid.UUID, sort models.Sort, page, pageSize int) ([]*models.Department, int64, error) {
+	return m.listResult, m.listTotal, m.listError
+}
+
+func (m *MockDepartmentRepository) Export(filter models.DepartmentFilter, sort models.Sort, fn func(*models.DepartmentExportRow) error) error {
+	for _, row := range m.exportResult {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MockDepartmentRepository) FindByIDWithDeletedForUpdate(id uuid.UUID) (*models.Department, error) {
+	return m.withDeletedResult, m.withDeletedError
+}
+
+func (m *MockDepartmentRepository) ListDeleted(page, pageSize int) ([]*models.Department, int64, error) {
+	return m.listDeletedResult, int64(len(m.listDeletedResult)), m.listError
+}
+
+func (m *MockDepartmentRepository) Restore(id uuid.UUID) error {
+	m.restored = m.restoreError == nil
+	return m.restoreError
+}
+
+// MockAuditRepository records the audit entries written by the services
+type MockAuditRepository struct {
+	entries     []*models.AuditEntry
+	updated     []*models.AuditEntry
+	createError error
+}
+
+func (m *MockAuditRepository) WithTx(tx *gorm.DB) repository.AuditRepository {
+	return m
+}
+
+func (m *MockAuditRepository) Create(entry *models.AuditEntry) error {
+	if m.createError != nil {
+		return m.createError
+	}
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *MockAuditRepository) List(filter models.AuditFilter, page, pageSize int) ([]*models.AuditEntry, int64, error) {
+	return m.entries, int64(len(m.entries)), nil
+}
+
+func (m *MockAuditRepository) ListByEntity(entityID uuid.UUID) ([]*models.AuditEntry, error) {
+	var entries []*models.AuditEntry
+	for _, e := range m.entries {
+		if e.EntityID == entityID {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func (m *MockAuditRepository) UpdateChanges(entry *models.AuditEntry) error {
+	m.updated = append(m.updated, entry)
+	return nil
+}
+
+// MockHistoryRepository records the department assignments written by the services
+type MockHistoryRepository struct {
+	created     []*models.DepartmentAssignment
+	closed      []uuid.UUID
+	listResult  []*models.DepartmentAssignment
+	createError error
+}
+
+func (m *MockHistoryRepository) WithTx(tx *gorm.DB) repository.EmployeeHistoryRepository {
+	return m
+}
+
+func (m *MockHistoryRepository) Create(assignment *models.DepartmentAssignment) error {
+	if m.createError != nil {
+		return m.createError
+	}
+	m.created = append(m.created, assignment)
+	return nil
+}
+
+func (m *MockHistoryRepository) CloseCurrent(employeeID uuid.UUID, at time.Time) error {
+	m.closed = append(m.closed, employeeID)
+	return nil
+}
+
+func (m *MockHistoryRepository) ListByEmployee(employeeID uuid.UUID) ([]*models.DepartmentAssignment, error) {
+	return m.listResult, nil
+}
+
+// MockVersionRepository records the department versions written by the services
+type MockVersionRepository struct {
+	created                 []*models.DepartmentVersion
+	closed                  []uuid.UUID
+	findAtResult            *models.Department
+	findAtError             error
+	findDescendantsAtResult []*models.Department
+	listByManagerResult     []*models.DepartmentVersion
+	at                      time.Time
+}
+
+func (m *MockVersionRepository) WithTx(tx *gorm.DB) repository.DepartmentHistoryRepository {
+	return m
+}
+
+func (m *MockVersionRepository) Create(version *models.DepartmentVersion) error {
+	m.created = append(m.created, version)
+	return nil
+}
+
+func (m *MockVersionRepository) CloseCurrent(deptID uuid.UUID, at time.Time) error {
+	m.closed = append(m.closed, deptID)
+	return nil
+}
+
+func (m *MockVersionRepository) FindAt(id uuid.UUID, at time.Time) (*models.Department, error) {
+	m.at = at
+	return m.findAtResult, m.findAtError
+}
+
+func (m *MockVersionRepository) FindDescendantsAt(id uuid.UUID, at time.Time, maxDepth int) ([]*models.Department, error) {
+	return m.findDescendantsAtResult, nil
+}
+
+func (m *MockVersionRepository) ListByManager(managerID uuid.UUID) ([]*models.DepartmentVersion, error) {
+	return m.listByManagerResult, nil
 }
 
 // Helper function to create string pointers
@@ -174,7 +479,7 @@ func TestEmployeeService_CreateEmployee(t *testing.T) {
 		{
 			name:         "success creating employee",
 			employeName:  "João Silva",
-			cpf:          "12345678901",
+			cpf:          "52998224725",
 			rg:           stringPtr("123456789"),
 			departmentID: uuid.New(),
 			mockSetup: func(deptRepo *MockDepartmentRepository, employeeRepo *MockEmployeeRepository) {
@@ -189,7 +494,7 @@ func TestEmployeeService_CreateEmployee(t *testing.T) {
 		{
 			name:         "error department not found",
 			employeName:  "João Silva",
-			cpf:          "12345678901",
+			cpf:          "52998224725",
 			rg:           stringPtr("123456789"),
 			departmentID: uuid.New(),
 			mockSetup: func(deptRepo *MockDepartmentRepository, employeeRepo *MockEmployeeRepository) {
@@ -202,7 +507,7 @@ func TestEmployeeService_CreateEmployee(t *testing.T) {
 		{
 			name:         "error duplicate CPF",
 			employeName:  "João Silva",
-			cpf:          "12345678901",
+			cpf:          "52998224725",
 			rg:           stringPtr("123456789"),
 			departmentID: uuid.New(),
 			mockSetup: func(deptRepo *MockDepartmentRepository, employeeRepo *MockEmployeeRepository) {
@@ -218,7 +523,7 @@ func TestEmployeeService_CreateEmployee(t *testing.T) {
 		{
 			name:         "error duplicate RG",
 			employeName:  "João Silva",
-			cpf:          "12345678901",
+			cpf:          "52998224725",
 			rg:           stringPtr("123456789"),
 			departmentID: uuid.New(),
 			mockSetup: func(deptRepo *MockDepartmentRepository, employeeRepo *MockEmployeeRepository) {
@@ -232,6 +537,39 @@ func TestEmployeeService_CreateEmployee(t *testing.T) {
 			},
 			expectedError: utils.ErrRGDuplicated,
 		},
+		{
+			name:         "success normalizing formatted CPF",
+			employeName:  "João Silva",
+			cpf:          "529.982.247-25",
+			rg:           stringPtr("123456789"),
+			departmentID: uuid.New(),
+			mockSetup: func(deptRepo *MockDepartmentRepository, employeeRepo *MockEmployeeRepository) {
+				deptRepo.findByIDResult = &models.Department{ID: uuid.New(), Name: "IT"}
+			},
+			expectedError: nil,
+		},
+		{
+			name:         "error invalid CPF",
+			employeName:  "João Silva",
+			cpf:          "12345678901",
+			rg:           stringPtr("123456789"),
+			departmentID: uuid.New(),
+			mockSetup: func(deptRepo *MockDepartmentRepository, employeeRepo *MockEmployeeRepository) {
+				deptRepo.findByIDResult = &models.Department{ID: uuid.New(), Name: "IT"}
+			},
+			expectedError: utils.ErrInvalidCPF,
+		},
+		{
+			name:         "error formatted CPF too long for the column",
+			employeName:  "João Silva",
+			cpf:          "529.982.247-251",
+			rg:           stringPtr("123456789"),
+			departmentID: uuid.New(),
+			mockSetup: func(deptRepo *MockDepartmentRepository, employeeRepo *MockEmployeeRepository) {
+				deptRepo.findByIDResult = &models.Department{ID: uuid.New(), Name: "IT"}
+			},
+			expectedError: utils.ErrInvalidCPF,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -241,10 +579,13 @@ func TestEmployeeService_CreateEmployee(t *testing.T) {
 			employeeRepo := &MockEmployeeRepository{}
 			tc.mockSetup(deptRepo, employeeRepo)
 
-			service := services.NewEmployeeService(deptRepo, employeeRepo)
+			service := services.NewEmployeeService(deptRepo, employeeRepo, &MockAuditRepository{}, &MockHistoryRepository{})
 
 			// Execute
 			result, err := service.CreateEmployee(tc.employeName, tc.cpf, tc.rg, tc.departmentID)
+			if result != nil && result.CPF != "52998224725" {
+				t.Errorf("Expected CPF stored as digits only, got %q", result.CPF)
+			}
 
 			// Validate
 			if tc.expectedError != nil {
@@ -278,6 +619,7 @@ func TestEmployeeService_GetEmployeeWithManager(t *testing.T) {
 		id            uuid.UUID
 		mockSetup     func(*MockDepartmentRepository, *MockEmployeeRepository)
 		expectedError error
+		expectedName  string
 	}{
 		{
 			name: "success getting employee with manager",
@@ -318,6 +660,16 @@ func TestEmployeeService_GetEmployeeWithManager(t *testing.T) {
 				}
 			},
 			expectedError: nil,
+			expectedName:  "Manager Silva",
+		},
+		{
+			name: "department without manager",
+			id:   employeeID,
+			mockSetup: func(deptRepo *MockDepartmentRepository, employeeRepo *MockEmployeeRepository) {
+				employeeRepo.findByIDResult = &models.Employee{ID: employeeID, Name: "João Silva", DepartmentID: departmentID}
+				deptRepo.findByIDResult = &models.Department{ID: departmentID, Name: "IT"}
+			},
+			expectedError: nil,
 		},
 		{
 			name: "employee not found",
@@ -337,7 +689,7 @@ func TestEmployeeService_GetEmployeeWithManager(t *testing.T) {
 			employeeRepo := &MockEmployeeRepository{}
 			tc.mockSetup(deptRepo, employeeRepo)
 
-			service := services.NewEmployeeService(deptRepo, employeeRepo)
+			service := services.NewEmployeeService(deptRepo, employeeRepo, &MockAuditRepository{}, &MockHistoryRepository{})
 
 			// Execute
 			result, err := service.GetEmployeeWithManager(tc.id)
@@ -355,7 +707,226 @@ func TestEmployeeService_GetEmployeeWithManager(t *testing.T) {
 					t.Errorf("Expected no error, got %v", err)
 				}
 				if result == nil {
-					t.Errorf("Expected result, got nil")
+					t.Fatalf("Expected result, got nil")
+				}
+				if result.ManagerName != tc.expectedName {
+					t.Errorf("Expected manager name %q, got %q", tc.expectedName, result.ManagerName)
+				}
+			}
+		})
+	}
+}
+
+func TestEmployeeService_UpdateEmployee(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	employeeID := uuid.New()
+	currentDeptID := uuid.New()
+	newDeptID := uuid.New()
+
+	testCases := []struct {
+		name          string
+		departmentID  uuid.UUID
+		mockSetup     func(*MockDepartmentRepository, *MockEmployeeRepository)
+		expectedError error
+	}{
+		{
+			name:         "success moving employee to another department",
+			departmentID: newDeptID,
+			mockSetup: func(deptRepo *MockDepartmentRepository, employeeRepo *MockEmployeeRepository) {
+				employeeRepo.findByIDResult = &models.Employee{ID: employeeID, Name: "João Silva", DepartmentID: currentDeptID}
+				deptRepo.findByIDResult = &models.Department{ID: newDeptID, Name: "RH"}
+				deptRepo.isManagerResult = false
+			},
+			expectedError: nil,
+		},
+		{
+			name:         "success updating manager within their department",
+			departmentID: currentDeptID,
+			mockSetup: func(deptRepo *MockDepartmentRepository, employeeRepo *MockEmployeeRepository) {
+				employeeRepo.findByIDResult = &models.Employee{ID: employeeID, Name: "João Silva", DepartmentID: currentDeptID}
+				deptRepo.findByIDResult = &models.Department{ID: currentDeptID, Name: "TI"}
+				deptRepo.isManagerResult = true
+			},
+			expectedError: nil,
+		},
+		{
+			name:         "manager cannot leave the department they manage",
+			departmentID: newDeptID,
+			mockSetup: func(deptRepo *MockDepartmentRepository, employeeRepo *MockEmployeeRepository) {
+				employeeRepo.findByIDResult = &models.Employee{ID: employeeID, Name: "João Silva", DepartmentID: currentDeptID}
+				deptRepo.findByIDResult = &models.Department{ID: newDeptID, Name: "RH"}
+				deptRepo.isManagerResult = true
+			},
+			expectedError: utils.ErrManagerNotBelongToDepartment,
+		},
+		{
+			name:         "department not found",
+			departmentID: newDeptID,
+			mockSetup: func(deptRepo *MockDepartmentRepository, employeeRepo *MockEmployeeRepository) {
+				employeeRepo.findByIDResult = &models.Employee{ID: employeeID, Name: "João Silva", DepartmentID: currentDeptID}
+				deptRepo.findByIDError = gorm.ErrRecordNotFound
+			},
+			expectedError: utils.ErrDepartmentNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			deptRepo := &MockDepartmentRepository{}
+			employeeRepo := &MockEmployeeRepository{}
+			tc.mockSetup(deptRepo, employeeRepo)
+
+			service := services.NewEmployeeService(deptRepo, employeeRepo, &MockAuditRepository{}, &MockHistoryRepository{})
+
+			// Execute
+			result, err := service.UpdateEmployee(employeeID, stringPtr("João Silva"), nil, tc.departmentID)
+
+			// Validate
+			if err != tc.expectedError {
+				t.Errorf("Expected error %v, got %v", tc.expectedError, err)
+			}
+			if tc.expectedError == nil && result == nil {
+				t.Errorf("Expected result, got nil")
+			}
+		})
+	}
+}
+
+func TestEmployeeService_ListEmployees_NormalizesCPF(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	employeeRepo := &MockEmployeeRepository{listResult: []*models.Employee{}}
+	service := services.NewEmployeeService(&MockDepartmentRepository{}, employeeRepo, &MockAuditRepository{}, &MockHistoryRepository{})
+
+	if _, err := service.ListEmployees(models.EmployeeFilter{CPF: stringPtr("529.982.247-25")}, models.Sort{}, 1, 10); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if employeeRepo.listCPFArg == nil || *employeeRepo.listCPFArg != "52998224725" {
+		t.Errorf("Expected CPF filter normalized to digits, got %v", employeeRepo.listCPFArg)
+	}
+}
+
+func TestEmployeeService_ListEmployeesByCursor(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	employees := []*models.Employee{
+		{ID: uuid.New(), Name: "Ana"},
+		{ID: uuid.New(), Name: "Bruno"},
+		{ID: uuid.New(), Name: "Carla"},
+	}
+	byName := models.Sort{Field: "name"}
+	fromBruno := models.Cursor{Sort: "name", Key: "Bruno", ID: employees[1].ID}.Encode()
+	empty := ""
+
+	testCases := []struct {
+		name             string
+		sort             models.Sort
+		after, before    *string
+		pageSize         int
+		expectedError    error
+		expectedNames    []string
+		expectedNext     bool
+		expectedPrev     bool
+		expectedBackward bool
+		expectedCursorID uuid.UUID
+	}{
+		{
+			name:          "primeira página com próxima",
+			sort:          byName,
+			after:         &empty,
+			pageSize:      2,
+			expectedNames: []string{"Ana", "Bruno"},
+			expectedNext:  true,
+		},
+		{
+			name:          "página única",
+			sort:          byName,
+			after:         &empty,
+			pageSize:      5,
+			expectedNames: []string{"Ana", "Bruno", "Carla"},
+		},
+		{
+			name:             "depois de um cursor",
+			sort:             byName,
+			after:            &fromBruno,
+			pageSize:         5,
+			expectedNames:    []string{"Ana", "Bruno", "Carla"},
+			expectedPrev:     true,
+			expectedCursorID: employees[1].ID,
+		},
+		{
+			name:             "antes de um cursor volta à ordem de exibição",
+			sort:             byName,
+			before:           &fromBruno,
+			pageSize:         2,
+			expectedNames:    []string{"Bruno", "Ana"},
+			expectedNext:     true,
+			expectedPrev:     true,
+			expectedBackward: true,
+			expectedCursorID: employees[1].ID,
+		},
+		{
+			name:          "cursor ilegível",
+			sort:          byName,
+			after:         stringPtr("%%%"),
+			pageSize:      2,
+			expectedError: utils.ErrInvalidCursor,
+		},
+		{
+			name:          "cursor de outra ordenação",
+			sort:          models.Sort{Field: "name", Desc: true},
+			after:         &fromBruno,
+			pageSize:      2,
+			expectedError: utils.ErrInvalidCursor,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			employeeRepo := &MockEmployeeRepository{listByCursorResult: employees}
+			service := services.NewEmployeeService(&MockDepartmentRepository{}, employeeRepo, &MockAuditRepository{}, &MockHistoryRepository{})
+
+			result, err := service.ListEmployeesByCursor(models.EmployeeFilter{}, tc.sort, tc.after, tc.before, tc.pageSize)
+			if err != tc.expectedError {
+				t.Fatalf("Expected error %v, got %v", tc.expectedError, err)
+			}
+			if tc.expectedError != nil {
+				return
+			}
+
+			if employeeRepo.listByCursorLimit != tc.pageSize+1 {
+				t.Errorf("Expected one extra row requested, got limit %d", employeeRepo.listByCursorLimit)
+			}
+			if employeeRepo.listByCursorBackward != tc.expectedBackward {
+				t.Errorf("Expected backward %v, got %v", tc.expectedBackward, employeeRepo.listByCursorBackward)
+			}
+			if tc.expectedCursorID == uuid.Nil && employeeRepo.listByCursorCursor != nil {
+				t.Errorf("Expected to start without a cursor, got %v", employeeRepo.listByCursorCursor)
+			}
+			if tc.expectedCursorID != uuid.Nil && (employeeRepo.listByCursorCursor == nil || employeeRepo.listByCursorCursor.ID != tc.expectedCursorID) {
+				t.Errorf("Expected cursor at %v, got %v", tc.expectedCursorID, employeeRepo.listByCursorCursor)
+			}
+
+			if len(result.Items) != len(tc.expectedNames) {
+				t.Fatalf("Expected %d items, got %d", len(tc.expectedNames), len(result.Items))
+			}
+			for i, e := range result.Items {
+				if e.Name != tc.expectedNames[i] {
+					t.Errorf("Position %d: expected %s, got %s", i, tc.expectedNames[i], e.Name)
+				}
+			}
+			if (result.NextCursor != nil) != tc.expectedNext {
+				t.Errorf("Expected next cursor %v, got %v", tc.expectedNext, result.NextCursor)
+			}
+			if (result.PrevCursor != nil) != tc.expectedPrev {
+				t.Errorf("Expected prev cursor %v, got %v", tc.expectedPrev, result.PrevCursor)
+			}
+			if result.NextCursor != nil {
+				next, err := models.DecodeCursor(*result.NextCursor)
+				if err != nil || next.ID != result.Items[len(result.Items)-1].ID {
+					t.Errorf("Expected next cursor at the last item, got %v (%v)", next, err)
 				}
 			}
 		})
@@ -419,7 +990,7 @@ func TestEmployeeService_DeleteEmployee(t *testing.T) {
 			employeeRepo := &MockEmployeeRepository{}
 			tc.mockSetup(deptRepo, employeeRepo)
 
-			service := services.NewEmployeeService(deptRepo, employeeRepo)
+			service := services.NewEmployeeService(deptRepo, employeeRepo, &MockAuditRepository{}, &MockHistoryRepository{})
 
 			// Execute
 			err := service.DeleteEmployee(tc.id)
@@ -448,12 +1019,12 @@ func BenchmarkEmployeeService_CreateEmployee(b *testing.B) {
 		createError: nil,
 	}
 
-	service := services.NewEmployeeService(deptRepo, employeeRepo)
+	service := services.NewEmployeeService(deptRepo, employeeRepo, &MockAuditRepository{}, &MockHistoryRepository{})
 
 	departmentID := uuid.New()
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		service.CreateEmployee("João Silva", "12345678901", stringPtr("123456789"), departmentID)
+		service.CreateEmployee("João Silva", "52998224725", stringPtr("123456789"), departmentID)
 	}
 }
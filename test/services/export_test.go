@@ -0,0 +1,34 @@
+package services_test
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/services"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestEmployeeService_ExportEmployees(t *testing.T) {
+	employeeRepo := &MockEmployeeRepository{exportResult: []*models.EmployeeExportRow{
+		{ID: uuid.New(), Name: "Maria"},
+		{ID: uuid.New(), Name: "João"},
+	}}
+
+	service := services.NewEmployeeService(&MockDepartmentRepository{}, employeeRepo, &MockAuditRepository{}, &MockHistoryRepository{})
+	cpf := "123.456.789-09"
+	var names []string
+	err := service.ExportEmployees(models.EmployeeFilter{CPF: &cpf}, models.Sort{}, func(row *models.EmployeeExportRow) error {
+		names = append(names, row.Name)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if employeeRepo.listCPFArg == nil || *employeeRepo.listCPFArg != "12345678909" {
+		t.Errorf("Expected the CPF filter to be normalized, got %v", employeeRepo.listCPFArg)
+	}
+	if len(names) != 2 || names[0] != "Maria" || names[1] != "João" {
+		t.Errorf("Expected every row to reach the caller, got %v", names)
+	}
+}
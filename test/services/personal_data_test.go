@@ -0,0 +1,93 @@
+package services_test
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/services"
+	"ManageEmployeesandDepartments/internal/utils"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestPersonalDataService_ExportPersonalData(t *testing.T) {
+	employeeID, managerID := uuid.New(), uuid.New()
+	ti, rh := uuid.New(), uuid.New()
+	t1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	t3 := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	t4 := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("colaborador ativo com gerente e períodos de gestão", func(t *testing.T) {
+		employeeRepo := &MockEmployeeRepository{
+			withDeletedResult: &models.Employee{ID: employeeID, Name: "Maria", DepartmentID: ti},
+			findByIDResult:    &models.Employee{ID: managerID, Name: "Carlos"},
+		}
+		deptRepo := &MockDepartmentRepository{findByIDResult: &models.Department{ID: ti, ManagerID: &managerID}}
+		historyRepo := &MockHistoryRepository{listResult: []*models.DepartmentAssignment{{EmployeeID: employeeID, DepartmentID: ti, EffectiveFrom: t1}}}
+		// RH renomeado em t2 sem troca de gerente; TI gerido de t3 a t4
+		versionRepo := &MockVersionRepository{listByManagerResult: []*models.DepartmentVersion{
+			{DepartmentID: rh, Name: "RH", ManagerID: &employeeID, ValidFrom: t1, ValidTo: &t2},
+			{DepartmentID: rh, Name: "Pessoas", ManagerID: &employeeID, ValidFrom: t2, ValidTo: &t3},
+			{DepartmentID: ti, Name: "TI", ManagerID: &employeeID, ValidFrom: t3, ValidTo: &t4},
+		}}
+		auditRepo := &MockAuditRepository{entries: []*models.AuditEntry{
+			{EntityID: employeeID, Action: models.AuditActionCreate},
+			{EntityID: uuid.New(), Action: models.AuditActionCreate},
+		}}
+
+		service := services.NewPersonalDataService(deptRepo, employeeRepo, historyRepo, versionRepo, auditRepo)
+		export, err := service.ExportPersonalData(employeeID)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if export.Employee.ID != employeeID || export.DeletedAt != nil {
+			t.Errorf("Unexpected employee: %+v", export)
+		}
+		if export.Manager == nil || export.Manager.ID != managerID || export.Manager.Name != "Carlos" {
+			t.Errorf("Expected Carlos as the manager, got %+v", export.Manager)
+		}
+		if len(export.DepartmentHistory) != 1 || len(export.AuditEntries) != 1 {
+			t.Errorf("Expected the employee's history and audit entries, got %d and %d", len(export.DepartmentHistory), len(export.AuditEntries))
+		}
+		if len(export.ManagedDepartments) != 2 {
+			t.Fatalf("Expected 2 management periods, got %+v", export.ManagedDepartments)
+		}
+		rhPeriod, tiPeriod := export.ManagedDepartments[0], export.ManagedDepartments[1]
+		if rhPeriod.DepartmentID != rh || rhPeriod.DepartmentName != "Pessoas" || !rhPeriod.From.Equal(t1) || !rhPeriod.To.Equal(t3) {
+			t.Errorf("Expected RH to be managed from t1 to t3 under its latest name, got %+v", rhPeriod)
+		}
+		if tiPeriod.DepartmentID != ti || !tiPeriod.From.Equal(t3) || !tiPeriod.To.Equal(t4) {
+			t.Errorf("Unexpected TI period: %+v", tiPeriod)
+		}
+	})
+
+	t.Run("colaborador removido", func(t *testing.T) {
+		deletedAt := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+		employeeRepo := &MockEmployeeRepository{withDeletedResult: &models.Employee{
+			ID: employeeID, DepartmentID: ti, DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true},
+		}}
+		deptRepo := &MockDepartmentRepository{findByIDError: gorm.ErrRecordNotFound}
+
+		service := services.NewPersonalDataService(deptRepo, employeeRepo, &MockHistoryRepository{}, &MockVersionRepository{}, &MockAuditRepository{})
+		export, err := service.ExportPersonalData(employeeID)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if export.DeletedAt == nil || !export.DeletedAt.Equal(deletedAt) || export.Manager != nil {
+			t.Errorf("Expected the removal date and no manager, got %+v", export)
+		}
+	})
+
+	t.Run("colaborador não encontrado", func(t *testing.T) {
+		employeeRepo := &MockEmployeeRepository{withDeletedError: gorm.ErrRecordNotFound}
+
+		service := services.NewPersonalDataService(&MockDepartmentRepository{}, employeeRepo, &MockHistoryRepository{}, &MockVersionRepository{}, &MockAuditRepository{})
+		if _, err := service.ExportPersonalData(employeeID); !errors.Is(err, utils.ErrEmployeeNotFound) {
+			t.Errorf("Expected ErrEmployeeNotFound, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,354 @@
+package services_test
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/services"
+	"ManageEmployeesandDepartments/internal/utils"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestDepartmentService_Reorganize(t *testing.T) {
+	empresaID, tiID, suporteID, rhID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	mariaID, joaoID, anaID := uuid.New(), uuid.New(), uuid.New()
+	unknown := uuid.New()
+
+	// Empresa > TI (Maria) > Suporte; Empresa > RH (Ana)
+	tree := func() ([]*models.Department, map[uuid.UUID]*models.Employee) {
+		depts := []*models.Department{
+			{ID: empresaID, Name: "Empresa"},
+			{ID: tiID, Name: "TI", ParentDepartmentID: &empresaID, ManagerID: &mariaID},
+			{ID: suporteID, Name: "Suporte", ParentDepartmentID: &tiID},
+			{ID: rhID, Name: "RH", ParentDepartmentID: &empresaID, ManagerID: &anaID},
+		}
+		employees := map[uuid.UUID]*models.Employee{
+			mariaID: {ID: mariaID, Name: "Maria", CPF: "12345678909", DepartmentID: tiID},
+			joaoID:  {ID: joaoID, Name: "João", CPF: "98765432100", DepartmentID: tiID},
+			anaID:   {ID: anaID, Name: "Ana", CPF: "52998224725", DepartmentID: rhID},
+		}
+		return depts, employees
+	}
+	ref := func(id uuid.UUID) *uuid.UUID { return &id }
+
+	type problem struct {
+		operation int // -1 for checks of the resulting tree
+		entityID  uuid.UUID
+		code      string
+	}
+	testCases := []struct {
+		name             string
+		ops              []models.ReorganizationOperation
+		dryRun           bool
+		mergeStaff       []uuid.UUID // Employees of the department being merged
+		expectedProblems []problem
+		expectedChanges  map[uuid.UUID]string // Entity → action
+		expectedDeleted  []uuid.UUID
+	}{
+		{
+			name: "aplica renomeação, mudança de superior e transferência",
+			ops: []models.ReorganizationOperation{
+				{Type: models.ReorganizeRename, DepartmentID: ref(tiID), Name: stringPtr(" Tecnologia ")},
+				{Type: models.ReorganizeReparent, DepartmentID: ref(suporteID), ParentDepartmentID: ref(rhID)},
+				{Type: models.ReorganizeMoveEmployee, DepartmentID: ref(rhID), EmployeeID: ref(joaoID)},
+			},
+			expectedChanges: map[uuid.UUID]string{joaoID: models.AuditActionUpdate, tiID: models.AuditActionUpdate, suporteID: models.AuditActionUpdate},
+		},
+		{
+			name: "simulação não grava",
+			ops: []models.ReorganizationOperation{
+				{Type: models.ReorganizeRename, DepartmentID: ref(tiID), Name: stringPtr("Tecnologia")},
+				{Type: models.ReorganizeMoveEmployee, DepartmentID: ref(rhID), EmployeeID: ref(joaoID)},
+			},
+			dryRun:          true,
+			expectedChanges: map[uuid.UUID]string{joaoID: models.AuditActionUpdate, tiID: models.AuditActionUpdate},
+		},
+		{
+			name: "troca de gerentes passa por estados intermediários inválidos",
+			ops: []models.ReorganizationOperation{
+				{Type: models.ReorganizeChangeManager, DepartmentID: ref(tiID), ManagerID: ref(anaID)},
+				{Type: models.ReorganizeChangeManager, DepartmentID: ref(rhID), ManagerID: ref(mariaID)},
+				{Type: models.ReorganizeMoveEmployee, DepartmentID: ref(tiID), EmployeeID: ref(anaID)},
+				{Type: models.ReorganizeMoveEmployee, DepartmentID: ref(rhID), EmployeeID: ref(mariaID)},
+			},
+			expectedChanges: map[uuid.UUID]string{anaID: models.AuditActionUpdate, mariaID: models.AuditActionUpdate, tiID: models.AuditActionUpdate, rhID: models.AuditActionUpdate},
+		},
+		{
+			name: "mescla leva colaboradores e subdepartamentos",
+			ops: []models.ReorganizationOperation{
+				{Type: models.ReorganizeMerge, DepartmentID: ref(tiID), TargetDepartmentID: ref(rhID)},
+			},
+			mergeStaff: []uuid.UUID{mariaID, joaoID},
+			expectedChanges: map[uuid.UUID]string{
+				mariaID: models.AuditActionUpdate, joaoID: models.AuditActionUpdate,
+				tiID: models.AuditActionDelete, suporteID: models.AuditActionUpdate,
+			},
+			expectedDeleted: []uuid.UUID{tiID},
+		},
+		{
+			name: "ciclo na árvore resultante",
+			ops: []models.ReorganizationOperation{
+				{Type: models.ReorganizeReparent, DepartmentID: ref(empresaID), ParentDepartmentID: ref(suporteID)},
+			},
+			expectedProblems: []problem{
+				{-1, empresaID, "HIERARCHY_CYCLE"}, {-1, tiID, "HIERARCHY_CYCLE"}, {-1, suporteID, "HIERARCHY_CYCLE"},
+			},
+		},
+		{
+			name: "gerente fora do departamento",
+			ops: []models.ReorganizationOperation{
+				{Type: models.ReorganizeChangeManager, DepartmentID: ref(rhID), ManagerID: ref(joaoID)},
+				{Type: models.ReorganizeMoveEmployee, DepartmentID: ref(rhID), EmployeeID: ref(mariaID)},
+			},
+			expectedProblems: []problem{{-1, tiID, "MANAGER_NOT_IN_DEPARTMENT"}, {-1, rhID, "MANAGER_NOT_IN_DEPARTMENT"}},
+		},
+		{
+			name: "operações inválidas",
+			ops: []models.ReorganizationOperation{
+				{Type: models.ReorganizeRename, DepartmentID: ref(unknown), Name: stringPtr("X")},
+				{Type: models.ReorganizeMoveEmployee, DepartmentID: ref(rhID), EmployeeID: ref(unknown)},
+				{Type: models.ReorganizeMerge, DepartmentID: ref(rhID), TargetDepartmentID: ref(rhID)},
+				{Type: models.ReorganizeChangeManager, DepartmentID: ref(rhID), ManagerID: ref(unknown)},
+				{Type: models.ReorganizeRename, DepartmentID: ref(rhID)},
+			},
+			expectedProblems: []problem{
+				{0, unknown, "DEPARTMENT_NOT_FOUND"}, {1, unknown, "EMPLOYEE_NOT_FOUND"}, {2, rhID, "INVALID_DATA"},
+				{3, unknown, "MANAGER_NOT_FOUND"}, {4, rhID, "INVALID_DATA"},
+			},
+		},
+		{
+			name: "departamento mesclado deixa de existir no plano",
+			ops: []models.ReorganizationOperation{
+				{Type: models.ReorganizeMerge, DepartmentID: ref(suporteID), TargetDepartmentID: ref(tiID)},
+				{Type: models.ReorganizeMoveEmployee, DepartmentID: ref(suporteID), EmployeeID: ref(joaoID)},
+			},
+			expectedProblems: []problem{{1, suporteID, "DEPARTMENT_NOT_FOUND"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			depts, employees := tree()
+			deptRepo := &MockDepartmentRepository{findAllResult: depts}
+			employeeRepo := &MockEmployeeRepository{findByIDResults: employees, findByIDError: gorm.ErrRecordNotFound}
+			for _, id := range tc.mergeStaff {
+				employeeRepo.findByDepartmentIDsResult = append(employeeRepo.findByDepartmentIDsResult, employees[id])
+			}
+			auditRepo := &MockAuditRepository{}
+			historyRepo := &MockHistoryRepository{}
+			versionRepo := &MockVersionRepository{}
+
+			service := services.NewDepartmentService(deptRepo, employeeRepo, auditRepo, historyRepo, versionRepo, services.MoveConfirmation{})
+			report, err := service.WithActor("rh@empresa.com").Reorganize(tc.ops, tc.dryRun)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(report.Problems) != len(tc.expectedProblems) {
+				t.Fatalf("Expected %d problems, got %d: %+v", len(tc.expectedProblems), len(report.Problems), report.Problems)
+			}
+			for i, p := range report.Problems {
+				want := tc.expectedProblems[i]
+				if (p.Operation == nil) != (want.operation < 0) || (p.Operation != nil && *p.Operation != want.operation) {
+					t.Errorf("Problem %d: expected operation %d, got %v", i, want.operation, p.Operation)
+				}
+				if p.EntityID == nil || *p.EntityID != want.entityID || p.Code != want.code {
+					t.Errorf("Problem %d: expected %s on %s, got %+v", i, want.code, want.entityID, p)
+				}
+			}
+
+			// Only a plan that will be applied locks what it reads
+			if locked := deptRepo.lockedAll || len(employeeRepo.lockedIDs) > 0; locked == tc.dryRun {
+				t.Errorf("Expected locking to be %v, got departments %v and employees %v", !tc.dryRun, deptRepo.lockedAll, employeeRepo.lockedIDs)
+			}
+
+			wrote := len(auditRepo.entries) > 0 || len(deptRepo.updatedDepts) > 0 || len(historyRepo.created) > 0 || len(versionRepo.created) > 0
+			if len(tc.expectedProblems) > 0 || tc.dryRun {
+				if report.Applied || wrote {
+					t.Errorf("Expected nothing to be written, got applied=%v", report.Applied)
+				}
+				if report.Valid != (len(tc.expectedProblems) == 0) {
+					t.Errorf("Expected valid=%v", len(tc.expectedProblems) == 0)
+				}
+				if tc.expectedChanges == nil {
+					return
+				}
+			} else if !report.Valid || !report.Applied {
+				t.Fatalf("Expected the plan to be applied, got %+v", report)
+			}
+
+			if len(report.Changes) != len(tc.expectedChanges) {
+				t.Fatalf("Expected %d changes, got %d: %+v", len(tc.expectedChanges), len(report.Changes), report.Changes)
+			}
+			for _, change := range report.Changes {
+				if action, ok := tc.expectedChanges[change.EntityID]; !ok || action != change.Action {
+					t.Errorf("Unexpected change %s %s: %+v", change.Action, change.Name, change.Changes)
+				}
+			}
+			if tc.dryRun {
+				return
+			}
+
+			if len(auditRepo.entries) != len(tc.expectedChanges) {
+				t.Errorf("Expected an audit entry per change, got %d", len(auditRepo.entries))
+			}
+			for _, entry := range auditRepo.entries {
+				if entry.Actor != "rh@empresa.com" || entry.Action != tc.expectedChanges[entry.EntityID] {
+					t.Errorf("Unexpected audit entry: %+v", entry)
+				}
+			}
+			if len(deptRepo.deleted) != len(tc.expectedDeleted) || (len(tc.expectedDeleted) > 0 && deptRepo.deleted[0] != tc.expectedDeleted[0]) {
+				t.Errorf("Expected %v to be removed, got %v", tc.expectedDeleted, deptRepo.deleted)
+			}
+		})
+	}
+}
+
+func TestDepartmentService_Reorganize_Mescla(t *testing.T) {
+	empresaID, tiID, suporteID, rhID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	mariaID, joaoID := uuid.New(), uuid.New()
+	ti := &models.Department{ID: tiID, Name: "TI", ParentDepartmentID: &empresaID, ManagerID: &mariaID}
+	suporte := &models.Department{ID: suporteID, Name: "Suporte", ParentDepartmentID: &tiID}
+	maria := &models.Employee{ID: mariaID, Name: "Maria", DepartmentID: tiID}
+	joao := &models.Employee{ID: joaoID, Name: "João", DepartmentID: tiID}
+
+	deptRepo := &MockDepartmentRepository{findAllResult: []*models.Department{
+		{ID: empresaID, Name: "Empresa"}, ti, suporte, {ID: rhID, Name: "RH", ParentDepartmentID: &empresaID},
+	}}
+	employeeRepo := &MockEmployeeRepository{findByDepartmentIDsResult: []*models.Employee{maria, joao}}
+	historyRepo := &MockHistoryRepository{}
+	versionRepo := &MockVersionRepository{}
+
+	service := services.NewDepartmentService(deptRepo, employeeRepo, &MockAuditRepository{}, historyRepo, versionRepo, services.MoveConfirmation{})
+	target := rhID
+	if _, err := service.Reorganize([]models.ReorganizationOperation{
+		{Type: models.ReorganizeMerge, DepartmentID: &tiID, TargetDepartmentID: &target},
+	}, false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if maria.DepartmentID != rhID || joao.DepartmentID != rhID {
+		t.Errorf("Expected the employees of TI to move to RH, got %s and %s", maria.DepartmentID, joao.DepartmentID)
+	}
+	if len(historyRepo.created) != 2 {
+		t.Errorf("Expected a new assignment per employee, got %d", len(historyRepo.created))
+	}
+	if suporte.ParentDepartmentID == nil || *suporte.ParentDepartmentID != rhID {
+		t.Errorf("Expected Suporte to move under RH, got %v", suporte.ParentDepartmentID)
+	}
+	if ti.ManagerID != nil {
+		t.Errorf("Expected the merged department to lose its manager, got %v", ti.ManagerID)
+	}
+	if len(versionRepo.closed) != 2 || versionRepo.closed[1] != tiID {
+		t.Errorf("Expected Suporte's version to be replaced and TI's to be closed, got %v", versionRepo.closed)
+	}
+	if len(employeeRepo.lockedIDs) != 2 {
+		t.Errorf("Expected the staff of TI to be locked, got %v", employeeRepo.lockedIDs)
+	}
+
+	// Someone who joined TI after the plan was read is not left behind
+	maria.DepartmentID, joao.DepartmentID = tiID, tiID
+	employeeRepo.countByDepartmentIDResult = 1
+	deptRepo.deleted = nil
+	if _, err := service.Reorganize([]models.ReorganizationOperation{
+		{Type: models.ReorganizeMerge, DepartmentID: &tiID, TargetDepartmentID: &target},
+	}, false); !errors.Is(err, utils.ErrDepartmentHasEmployees) {
+		t.Errorf("Expected ErrDepartmentHasEmployees, got %v", err)
+	}
+	if len(deptRepo.deleted) != 0 {
+		t.Errorf("Expected TI not to be removed, got %v", deptRepo.deleted)
+	}
+}
+
+func TestDepartmentService_MergeDepartment(t *testing.T) {
+	empresaID, tiID, suporteID, rhID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	mariaID, joaoID, anaID := uuid.New(), uuid.New(), uuid.New()
+
+	testCases := []struct {
+		name            string
+		sourceID        uuid.UUID
+		targetID        uuid.UUID
+		managerID       *uuid.UUID
+		targetManager   bool // Whether RH has Ana as manager
+		expectedError   error
+		expectedManager *uuid.UUID
+		expectedParent  *uuid.UUID // Of the target after the merge
+	}{
+		{name: "destino mantém seu gerente", sourceID: tiID, targetID: rhID, targetManager: true, expectedManager: &anaID, expectedParent: &empresaID},
+		{name: "destino sem gerente herda o da origem", sourceID: tiID, targetID: rhID, expectedManager: &mariaID, expectedParent: &empresaID},
+		{name: "gerente escolhido entre os mesclados", sourceID: tiID, targetID: rhID, targetManager: true, managerID: &joaoID, expectedManager: &joaoID, expectedParent: &empresaID},
+		{name: "destino sem gerente", sourceID: tiID, targetID: rhID, targetManager: true, managerID: &uuid.UUID{}, expectedParent: &empresaID},
+		{name: "destino abaixo da origem ocupa o lugar dela", sourceID: tiID, targetID: suporteID, expectedManager: &mariaID, expectedParent: &empresaID},
+		{name: "gerente de outro departamento", sourceID: tiID, targetID: suporteID, managerID: &anaID, expectedError: utils.ErrManagerNotBelongToDepartment},
+		{name: "origem não encontrada", sourceID: uuid.New(), targetID: rhID, expectedError: gorm.ErrRecordNotFound},
+		{name: "destino não encontrado", sourceID: tiID, targetID: uuid.New(), expectedError: utils.ErrDepartmentNotFound},
+		{name: "mesclagem consigo mesmo", sourceID: tiID, targetID: tiID, expectedError: utils.ErrInvalid},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Empresa > TI (Maria, João) > Suporte; Empresa > RH (Ana)
+			rh := &models.Department{ID: rhID, Name: "RH", ParentDepartmentID: &empresaID}
+			if tc.targetManager {
+				rh.ManagerID = &anaID
+			}
+			depts := []*models.Department{
+				{ID: empresaID, Name: "Empresa"},
+				{ID: tiID, Name: "TI", ParentDepartmentID: &empresaID, ManagerID: &mariaID},
+				{ID: suporteID, Name: "Suporte", ParentDepartmentID: &tiID},
+				rh,
+			}
+			maria := &models.Employee{ID: mariaID, Name: "Maria", DepartmentID: tiID}
+			joao := &models.Employee{ID: joaoID, Name: "João", DepartmentID: tiID}
+			ana := &models.Employee{ID: anaID, Name: "Ana", DepartmentID: rhID}
+
+			deptRepo := &MockDepartmentRepository{findAllResult: depts}
+			employeeRepo := &MockEmployeeRepository{
+				findByIDResults:           map[uuid.UUID]*models.Employee{mariaID: maria, joaoID: joao, anaID: ana},
+				findByIDError:             gorm.ErrRecordNotFound,
+				findByDepartmentIDsResult: []*models.Employee{maria, joao},
+			}
+			auditRepo := &MockAuditRepository{}
+			historyRepo := &MockHistoryRepository{}
+
+			service := services.NewDepartmentService(deptRepo, employeeRepo, auditRepo, historyRepo, &MockVersionRepository{}, services.MoveConfirmation{})
+			target, err := service.WithActor("rh@empresa.com").MergeDepartment(tc.sourceID, tc.targetID, tc.managerID, "")
+
+			if tc.expectedError != nil {
+				if !errors.Is(err, tc.expectedError) {
+					t.Fatalf("Expected error %v, got %v", tc.expectedError, err)
+				}
+				if len(deptRepo.updatedDepts) != 0 || len(deptRepo.deleted) != 0 || len(auditRepo.entries) != 0 || len(historyRepo.created) != 0 {
+					t.Errorf("Expected nothing to be written on failure")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if target.ID != tc.targetID {
+				t.Errorf("Expected the target to be returned, got %+v", target)
+			}
+			if (target.ManagerID == nil) != (tc.expectedManager == nil) || (target.ManagerID != nil && *target.ManagerID != *tc.expectedManager) {
+				t.Errorf("Expected manager %v, got %v", tc.expectedManager, target.ManagerID)
+			}
+			if target.ParentDepartmentID == nil || *target.ParentDepartmentID != *tc.expectedParent {
+				t.Errorf("Expected parent %v, got %v", tc.expectedParent, target.ParentDepartmentID)
+			}
+			if maria.DepartmentID != tc.targetID || joao.DepartmentID != tc.targetID || len(historyRepo.created) != 2 {
+				t.Errorf("Expected the staff of TI to move to the target, got %s and %s", maria.DepartmentID, joao.DepartmentID)
+			}
+			if len(deptRepo.deleted) != 1 || deptRepo.deleted[0] != tiID {
+				t.Errorf("Expected TI to be removed, got %v", deptRepo.deleted)
+			}
+			for _, entry := range auditRepo.entries {
+				if entry.Actor != "rh@empresa.com" {
+					t.Errorf("Expected the merge to be attributed to the caller, got %+v", entry)
+				}
+			}
+		})
+	}
+}
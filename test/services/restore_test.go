@@ -0,0 +1,258 @@
+package services_test
+
+import (
+	"ManageEmployeesandDepartments/internal/models"
+	"ManageEmployeesandDepartments/internal/services"
+	"ManageEmployeesandDepartments/internal/utils"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestEmployeeService_RestoreEmployee(t *testing.T) {
+	employeeID := uuid.New()
+	deptID := uuid.New()
+	removed := func() *models.Employee {
+		return &models.Employee{
+			ID:           employeeID,
+			Name:         "Maria",
+			CPF:          "12345678909",
+			RG:           stringPtr("123456789"),
+			DepartmentID: deptID,
+			DeletedAt:    gorm.DeletedAt{Time: time.Now(), Valid: true},
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		employee      func() *models.Employee
+		findError     error
+		deptError     error
+		cpfInUse      bool
+		rgInUse       bool
+		restoreError  error
+		cpfDuplicated bool
+		rgDuplicated  bool
+		expectedError error
+	}{
+		{name: "restaura colaborador removido", employee: removed},
+		{name: "colaborador não encontrado", findError: utils.ErrEmployeeNotFound, expectedError: utils.ErrEmployeeNotFound},
+		{name: "colaborador ativo", employee: func() *models.Employee { return &models.Employee{ID: employeeID} }, expectedError: utils.ErrNotDeleted},
+		{name: "departamento removido", employee: removed, deptError: utils.ErrDepartmentNotFound, expectedError: utils.ErrDepartmentNotFound},
+		{name: "CPF em uso por outro colaborador", employee: removed, cpfInUse: true, expectedError: utils.ErrCPFDuplicated},
+		{name: "RG em uso por outro colaborador", employee: removed, rgInUse: true, expectedError: utils.ErrRGDuplicated},
+		{name: "CPF tomado após a verificação", employee: removed, restoreError: errors.New("duplicate key"), cpfDuplicated: true, expectedError: utils.ErrCPFDuplicated},
+		{name: "RG tomado após a verificação", employee: removed, restoreError: errors.New("duplicate key"), rgDuplicated: true, expectedError: utils.ErrRGDuplicated},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			employeeRepo := &MockEmployeeRepository{
+				withDeletedError: tc.findError,
+				findByIDResult:   &models.Employee{ID: employeeID, DepartmentID: deptID},
+				cpfInUse:         tc.cpfInUse,
+				rgInUse:          tc.rgInUse,
+				restoreError:     tc.restoreError,
+
+				isCPFDuplicatedResult: tc.cpfDuplicated,
+				isRGDuplicatedResult:  tc.rgDuplicated,
+			}
+			if tc.employee != nil {
+				employeeRepo.withDeletedResult = tc.employee()
+			}
+			deptRepo := &MockDepartmentRepository{findByIDResult: &models.Department{ID: deptID}, findByIDError: tc.deptError}
+			auditRepo := &MockAuditRepository{}
+			historyRepo := &MockHistoryRepository{}
+
+			service := services.NewEmployeeService(deptRepo, employeeRepo, auditRepo, historyRepo)
+			employee, err := service.WithActor("rh@empresa.com").RestoreEmployee(employeeID)
+
+			if tc.expectedError != nil {
+				if !errors.Is(err, tc.expectedError) {
+					t.Fatalf("Expected error %v, got %v", tc.expectedError, err)
+				}
+				if employeeRepo.restored || len(auditRepo.entries) != 0 || len(historyRepo.created) != 0 {
+					t.Errorf("Expected nothing to be written on failure")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !employeeRepo.restored || employee.ID != employeeID {
+				t.Errorf("Expected the employee to be restored, got %+v", employee)
+			}
+			if len(historyRepo.created) != 1 || historyRepo.created[0].DepartmentID != deptID {
+				t.Errorf("Expected a new assignment in the former department, got %+v", historyRepo.created)
+			}
+			if len(auditRepo.entries) != 1 || auditRepo.entries[0].Action != models.AuditActionRestore || auditRepo.entries[0].Actor != "rh@empresa.com" {
+				t.Errorf("Expected a restore audit entry, got %+v", auditRepo.entries)
+			}
+		})
+	}
+}
+
+func TestDepartmentService_RestoreDepartment(t *testing.T) {
+	deptID := uuid.New()
+	parentID := uuid.New()
+	removed := func() *models.Department {
+		return &models.Department{
+			ID:                 deptID,
+			Name:               "TI",
+			ParentDepartmentID: &parentID,
+			DeletedAt:          gorm.DeletedAt{Time: time.Now(), Valid: true},
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		dept          func() *models.Department
+		findError     error
+		parentError   error
+		expectedError error
+	}{
+		{name: "restaura departamento removido", dept: removed},
+		{name: "departamento não encontrado", findError: utils.ErrDepartmentNotFound, expectedError: utils.ErrDepartmentNotFound},
+		{name: "departamento ativo", dept: func() *models.Department { return &models.Department{ID: deptID} }, expectedError: utils.ErrNotDeleted},
+		{name: "departamento superior removido", dept: removed, parentError: utils.ErrDepartmentNotFound, expectedError: utils.ErrParentDepartmentNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deptRepo := &MockDepartmentRepository{
+				withDeletedError: tc.findError,
+				findByIDResult:   &models.Department{ID: deptID, Name: "TI", ParentDepartmentID: &parentID},
+				findByIDError:    tc.parentError,
+			}
+			if tc.dept != nil {
+				deptRepo.withDeletedResult = tc.dept()
+			}
+			auditRepo := &MockAuditRepository{}
+			versionRepo := &MockVersionRepository{}
+
+			service := services.NewDepartmentService(deptRepo, &MockEmployeeRepository{}, auditRepo, &MockHistoryRepository{}, versionRepo, services.MoveConfirmation{})
+			dept, err := service.RestoreDepartment(deptID)
+
+			if tc.expectedError != nil {
+				if !errors.Is(err, tc.expectedError) {
+					t.Fatalf("Expected error %v, got %v", tc.expectedError, err)
+				}
+				if deptRepo.restored || len(auditRepo.entries) != 0 || len(versionRepo.created) != 0 {
+					t.Errorf("Expected nothing to be written on failure")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !deptRepo.restored || dept.ID != deptID {
+				t.Errorf("Expected the department to be restored, got %+v", dept)
+			}
+			if len(versionRepo.created) != 1 || versionRepo.created[0].DepartmentID != deptID {
+				t.Errorf("Expected a new version, got %+v", versionRepo.created)
+			}
+			if len(auditRepo.entries) != 1 || auditRepo.entries[0].Action != models.AuditActionRestore || auditRepo.entries[0].Actor != services.SystemActor {
+				t.Errorf("Expected a restore audit entry by the system, got %+v", auditRepo.entries)
+			}
+		})
+	}
+}
+
+func TestEmployeeService_ListDeletedEmployees(t *testing.T) {
+	deletedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	employeeRepo := &MockEmployeeRepository{listDeletedResult: []*models.Employee{
+		{ID: uuid.New(), Name: "Maria", DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true}},
+	}}
+
+	service := services.NewEmployeeService(&MockDepartmentRepository{}, employeeRepo, &MockAuditRepository{}, &MockHistoryRepository{})
+	page, err := service.ListDeletedEmployees(1, 10)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if page.Total != 1 || len(page.Items) != 1 || !page.Items[0].DeletedAt.Equal(deletedAt) {
+		t.Errorf("Unexpected page: %+v", page)
+	}
+}
+
+func TestEmployeeService_CreateEmployee_Recontratacao(t *testing.T) {
+	employeeID := uuid.New()
+	oldDeptID, newDeptID := uuid.New(), uuid.New()
+	hired := time.Date(2023, 11, 1, 9, 0, 0, 0, time.UTC)
+	former := func() *models.Employee {
+		return &models.Employee{
+			ID:           employeeID,
+			Name:         "Maria Temporária",
+			CPF:          "12345678909",
+			DepartmentID: oldDeptID,
+			CreatedAt:    hired,
+			DeletedAt:    gorm.DeletedAt{Time: time.Date(2024, 2, 1, 18, 0, 0, 0, time.UTC), Valid: true},
+		}
+	}
+
+	t.Run("CPF de colaborador removido reativa o mesmo registro", func(t *testing.T) {
+		employeeRepo := &MockEmployeeRepository{formerResult: former()}
+		deptRepo := &MockDepartmentRepository{findByIDResult: &models.Department{ID: newDeptID}}
+		auditRepo := &MockAuditRepository{}
+		historyRepo := &MockHistoryRepository{}
+
+		service := services.NewEmployeeService(deptRepo, employeeRepo, auditRepo, historyRepo)
+		employee, err := service.CreateEmployee("Maria Sazonal", "123.456.789-09", stringPtr("123456789"), newDeptID)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if employee.ID != employeeID || !employee.CreatedAt.Equal(hired) {
+			t.Errorf("Expected the former record to come back, got %+v", employee)
+		}
+		if employeeRepo.rehired == nil || employeeRepo.restored || employeeRepo.updated != nil {
+			t.Fatal("Expected the record to be restored and updated in a single statement")
+		}
+		if employee.Name != "Maria Sazonal" || employee.DepartmentID != newDeptID || employee.RG == nil || *employee.RG != "123456789" {
+			t.Errorf("Expected the rehire data to be applied, got %+v", employee)
+		}
+		if len(historyRepo.created) != 1 || historyRepo.created[0].EmployeeID != employeeID || historyRepo.created[0].DepartmentID != newDeptID {
+			t.Errorf("Expected a new assignment for the rehired employee, got %+v", historyRepo.created)
+		}
+		if len(auditRepo.entries) != 1 || auditRepo.entries[0].Action != models.AuditActionRehire {
+			t.Fatalf("Expected a rehire audit entry, got %+v", auditRepo.entries)
+		}
+		if change, ok := auditRepo.entries[0].Changes["department_id"]; !ok || change.Before != oldDeptID.String() {
+			t.Errorf("Expected the department change to be audited, got %+v", auditRepo.entries[0].Changes)
+		}
+	})
+
+	t.Run("RG em uso por colaborador ativo", func(t *testing.T) {
+		employeeRepo := &MockEmployeeRepository{formerResult: former(), rehireError: errors.New("duplicate key"), isRGDuplicatedResult: true}
+		deptRepo := &MockDepartmentRepository{findByIDResult: &models.Department{ID: newDeptID}}
+		auditRepo := &MockAuditRepository{}
+
+		service := services.NewEmployeeService(deptRepo, employeeRepo, auditRepo, &MockHistoryRepository{})
+		if _, err := service.CreateEmployee("Maria", "12345678909", stringPtr("123456789"), newDeptID); !errors.Is(err, utils.ErrRGDuplicated) {
+			t.Fatalf("Expected ErrRGDuplicated, got %v", err)
+		}
+		if len(auditRepo.entries) != 0 {
+			t.Errorf("Expected no audit entry, got %+v", auditRepo.entries)
+		}
+	})
+
+	t.Run("sem registro removido cria um novo colaborador", func(t *testing.T) {
+		employeeRepo := &MockEmployeeRepository{}
+		deptRepo := &MockDepartmentRepository{findByIDResult: &models.Department{ID: newDeptID}}
+		auditRepo := &MockAuditRepository{}
+
+		service := services.NewEmployeeService(deptRepo, employeeRepo, auditRepo, &MockHistoryRepository{})
+		employee, err := service.CreateEmployee("João", "12345678909", nil, newDeptID)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if employee.ID == employeeID || employeeRepo.rehired != nil {
+			t.Error("Expected a new employee")
+		}
+		if len(auditRepo.entries) != 1 || auditRepo.entries[0].Action != models.AuditActionCreate {
+			t.Errorf("Expected a create audit entry, got %+v", auditRepo.entries)
+		}
+	})
+}
@@ -3,8 +3,11 @@ package utils_test
 import (
 	"ManageEmployeesandDepartments/internal/utils"
 	"errors"
+	"fmt"
 	"net/http"
 	"testing"
+
+	"gorm.io/gorm"
 )
 
 func TestCustomError_Error(t *testing.T) {
@@ -103,58 +106,158 @@ func TestNewCustomError(t *testing.T) {
 
 func TestMapErrorToCustom(t *testing.T) {
 	tests := []struct {
-		name         string
-		inputError   error
-		expectedCode int
-		expectedMsg  string
+		name              string
+		inputError        error
+		expectedCode      int
+		expectedMsg       string
+		expectedErrorCode string
 	}{
 		{
-			name:         "ErrParentDepartmentNotFound",
-			inputError:   utils.ErrParentDepartmentNotFound,
-			expectedCode: http.StatusNotFound,
-			expectedMsg:  "Resource not found.",
+			name:              "ErrParentDepartmentNotFound",
+			inputError:        utils.ErrParentDepartmentNotFound,
+			expectedCode:      http.StatusUnprocessableEntity,
+			expectedMsg:       "Business rule failure or invalid data.",
+			expectedErrorCode: "PARENT_DEPARTMENT_NOT_FOUND",
+		},
+		{
+			name:              "ErrNotFound",
+			inputError:        utils.ErrNotFound,
+			expectedCode:      http.StatusNotFound,
+			expectedMsg:       "Resource not found.",
+			expectedErrorCode: "NOT_FOUND",
+		},
+		{
+			name:              "gorm.ErrRecordNotFound",
+			inputError:        gorm.ErrRecordNotFound,
+			expectedCode:      http.StatusNotFound,
+			expectedMsg:       "Resource not found.",
+			expectedErrorCode: "NOT_FOUND",
+		},
+		{
+			name:              "ErrEmployeeNotFound",
+			inputError:        utils.ErrEmployeeNotFound,
+			expectedCode:      http.StatusNotFound,
+			expectedMsg:       "Resource not found.",
+			expectedErrorCode: "EMPLOYEE_NOT_FOUND",
+		},
+		{
+			name:              "ErrDepartmentAmbiguous",
+			inputError:        utils.ErrDepartmentAmbiguous,
+			expectedCode:      http.StatusUnprocessableEntity,
+			expectedMsg:       "Business rule failure or invalid data.",
+			expectedErrorCode: "DEPARTMENT_AMBIGUOUS",
+		},
+		{
+			name:              "ErrConfirmationRequired",
+			inputError:        utils.ErrConfirmationRequired,
+			expectedCode:      http.StatusPreconditionRequired,
+			expectedMsg:       "Precondition Required",
+			expectedErrorCode: "CONFIRMATION_REQUIRED",
+		},
+		{
+			name:              "ErrCycleDetected",
+			inputError:        utils.ErrCycleDetected,
+			expectedCode:      http.StatusUnprocessableEntity,
+			expectedMsg:       "Business rule failure or invalid data.",
+			expectedErrorCode: "HIERARCHY_CYCLE",
+		},
+		{
+			name:              "ErrDepartmentHasEmployees",
+			inputError:        utils.ErrDepartmentHasEmployees,
+			expectedCode:      http.StatusUnprocessableEntity,
+			expectedMsg:       "Business rule failure or invalid data.",
+			expectedErrorCode: "DEPARTMENT_HAS_EMPLOYEES",
+		},
+		{
+			name:              "ErrDepartmentHasSubDepartments",
+			inputError:        utils.ErrDepartmentHasSubDepartments,
+			expectedCode:      http.StatusUnprocessableEntity,
+			expectedMsg:       "Business rule failure or invalid data.",
+			expectedErrorCode: "DEPARTMENT_HAS_SUB_DEPARTMENTS",
+		},
+		{
+			name:              "ErrManagerNotBelongToDepartment",
+			inputError:        utils.ErrManagerNotBelongToDepartment,
+			expectedCode:      http.StatusUnprocessableEntity,
+			expectedMsg:       "Business rule failure or invalid data.",
+			expectedErrorCode: "MANAGER_NOT_IN_DEPARTMENT",
 		},
 		{
-			name:         "ErrNotFound",
-			inputError:   utils.ErrNotFound,
-			expectedCode: http.StatusNotFound,
-			expectedMsg:  "Resource not found.",
+			name:              "ErrCPFDuplicated",
+			inputError:        utils.ErrCPFDuplicated,
+			expectedCode:      http.StatusConflict,
+			expectedMsg:       "Resource already exists.",
+			expectedErrorCode: "CPF_DUPLICATED",
 		},
 		{
-			name:         "ErrCycleDetected",
-			inputError:   utils.ErrCycleDetected,
-			expectedCode: http.StatusBadRequest,
-			expectedMsg:  "Business rule failure or invalid data.",
+			name:              "ErrInvalidCPF",
+			inputError:        utils.ErrInvalidCPF,
+			expectedCode:      http.StatusBadRequest,
+			expectedMsg:       "Invalid request.",
+			expectedErrorCode: "INVALID_CPF",
 		},
 		{
-			name:         "ErrDepartmentHasEmployees",
-			inputError:   utils.ErrDepartmentHasEmployees,
-			expectedCode: http.StatusBadRequest,
-			expectedMsg:  "Business rule failure or invalid data.",
+			name:              "ErrNotDeleted",
+			inputError:        utils.ErrNotDeleted,
+			expectedCode:      http.StatusConflict,
+			expectedMsg:       "Resource already exists.",
+			expectedErrorCode: "NOT_DELETED",
 		},
 		{
-			name:         "ErrDepartmentHasSubDepartments",
-			inputError:   utils.ErrDepartmentHasSubDepartments,
-			expectedCode: http.StatusBadRequest,
-			expectedMsg:  "Business rule failure or invalid data.",
+			name:              "ErrEmployeeAnonymized",
+			inputError:        utils.ErrEmployeeAnonymized,
+			expectedCode:      http.StatusConflict,
+			expectedMsg:       "Resource already exists.",
+			expectedErrorCode: "EMPLOYEE_ANONYMIZED",
 		},
 		{
-			name:         "ErrManagerNotBelongToDepartment",
-			inputError:   utils.ErrManagerNotBelongToDepartment,
-			expectedCode: http.StatusBadRequest,
-			expectedMsg:  "Business rule failure or invalid data.",
+			name:              "ErrInvalidSort",
+			inputError:        utils.ErrInvalidSort,
+			expectedCode:      http.StatusBadRequest,
+			expectedMsg:       "Invalid request.",
+			expectedErrorCode: "INVALID_SORT",
 		},
 		{
-			name:         "ErrInvalid",
-			inputError:   utils.ErrInvalid,
-			expectedCode: http.StatusBadRequest,
-			expectedMsg:  "Business rule failure or invalid data.",
+			name:              "ErrInvalidScope",
+			inputError:        utils.ErrInvalidScope,
+			expectedCode:      http.StatusBadRequest,
+			expectedMsg:       "Invalid request.",
+			expectedErrorCode: "INVALID_SCOPE",
 		},
 		{
-			name:         "Unknown error",
-			inputError:   errors.New("unknown error"),
-			expectedCode: http.StatusInternalServerError,
-			expectedMsg:  "Internal server error.",
+			name:              "ErrInvalid",
+			inputError:        utils.ErrInvalid,
+			expectedCode:      http.StatusBadRequest,
+			expectedMsg:       "Invalid request.",
+			expectedErrorCode: "INVALID_DATA",
+		},
+		{
+			name:              "ErrUnauthorized",
+			inputError:        utils.ErrUnauthorized,
+			expectedCode:      http.StatusUnauthorized,
+			expectedMsg:       "Authentication required.",
+			expectedErrorCode: "UNAUTHORIZED",
+		},
+		{
+			name:              "ErrForbidden",
+			inputError:        utils.ErrForbidden,
+			expectedCode:      http.StatusForbidden,
+			expectedMsg:       "Access denied.",
+			expectedErrorCode: "FORBIDDEN",
+		},
+		{
+			name:              "Wrapped domain error",
+			inputError:        fmt.Errorf("updating department: %w", utils.ErrCycleDetected),
+			expectedCode:      http.StatusUnprocessableEntity,
+			expectedMsg:       "Business rule failure or invalid data.",
+			expectedErrorCode: "HIERARCHY_CYCLE",
+		},
+		{
+			name:              "Unknown error",
+			inputError:        errors.New("unknown error"),
+			expectedCode:      http.StatusInternalServerError,
+			expectedMsg:       "Internal server error.",
+			expectedErrorCode: "INTERNAL_ERROR",
 		},
 	}
 
@@ -168,6 +271,9 @@ func TestMapErrorToCustom(t *testing.T) {
 			if result.Message != tt.expectedMsg {
 				t.Errorf("MapErrorToCustom().Message = %q, expected %q", result.Message, tt.expectedMsg)
 			}
+			if result.ErrorCode != tt.expectedErrorCode {
+				t.Errorf("MapErrorToCustom().ErrorCode = %q, expected %q", result.ErrorCode, tt.expectedErrorCode)
+			}
 			if result.Details != tt.inputError.Error() {
 				t.Errorf("MapErrorToCustom().Details = %q, expected %q", result.Details, tt.inputError.Error())
 			}
@@ -175,6 +281,25 @@ func TestMapErrorToCustom(t *testing.T) {
 	}
 }
 
+func TestMapErrorToCustom_PassesCustomErrorThrough(t *testing.T) {
+	original := utils.NewCustomError(http.StatusTeapot, "Teapot", "short and stout")
+
+	if result := utils.MapErrorToCustom(original); result != original {
+		t.Errorf("MapErrorToCustom() = %v, expected the original CustomError", result)
+	}
+}
+
+func TestWithStatus(t *testing.T) {
+	result := utils.WithStatus(utils.ErrManagerNotFound, http.StatusNotFound)
+
+	if result.Code != http.StatusNotFound {
+		t.Errorf("WithStatus().Code = %d, expected %d", result.Code, http.StatusNotFound)
+	}
+	if result.ErrorCode != "MANAGER_NOT_FOUND" {
+		t.Errorf("WithStatus().ErrorCode = %q, expected %q", result.ErrorCode, "MANAGER_NOT_FOUND")
+	}
+}
+
 func TestPredefinedErrors(t *testing.T) {
 	tests := []struct {
 		name string
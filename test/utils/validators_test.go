@@ -157,6 +157,27 @@ func BenchmarkIsCPFValidoInvalid(b *testing.B) {
 }
 
 // Test para verificar se a função lida com entrada nil/panic
+func TestNormalizeCPF(t *testing.T) {
+	tests := []struct {
+		name     string
+		cpf      string
+		expected string
+	}{
+		{name: "CPF formatado", cpf: "111.444.777-35", expected: "11144477735"},
+		{name: "CPF só com dígitos", cpf: "11144477735", expected: "11144477735"},
+		{name: "CPF com espaços", cpf: " 111 444 777 35 ", expected: "11144477735"},
+		{name: "CPF vazio", cpf: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := utils.NormalizeCPF(tt.cpf); result != tt.expected {
+				t.Errorf("NormalizeCPF(%q) = %q, expected %q", tt.cpf, result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestIsCPFValidoPanicRecovery(t *testing.T) {
 	defer func() {
 		if r := recover(); r != nil {